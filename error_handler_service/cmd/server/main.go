@@ -15,6 +15,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -26,6 +27,9 @@ import (
 	"project_chimera/error_handle_service/config/rabbitmq"
 	"project_chimera/error_handle_service/internal/actuators"
 	"project_chimera/error_handle_service/internal/dump"
+	"project_chimera/error_handle_service/internal/errordump"
+	"project_chimera/error_handle_service/internal/flora"
+	"project_chimera/error_handle_service/internal/problem"
 	customlogger "project_chimera/error_handle_service/pkg/logger"
 	"syscall"
 	"time"
@@ -37,6 +41,7 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/swagger"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // @title Error Handle Service API
@@ -61,7 +66,12 @@ func main() {
 		}
 	}()
 
-	app := fiber.New()
+	app := fiber.New(fiber.Config{
+		ErrorHandler: problem.ErrorHandler,
+	})
+
+	// Assign every request a trace ID that error responses refer to
+	app.Use(problem.RequestID())
 
 	// Logger setup
 	app.Use(logger.New(customlogger.InitLogger()))
@@ -82,14 +92,25 @@ func main() {
 	retryDelay := 2 * time.Second
 
 	// Initialize RabbitMQ Consumer with retries
-	consumer, err := rabbitmq.NewConsumer(queueName, maxAttempts, retryDelay)
+	consumer, err := rabbitmq.NewConsumer(queueName, maxAttempts, retryDelay, rabbitmq.RetryConfig{
+		MaxAttempts: config.Env.DeliveryMaxAttempts,
+		BaseDelay:   config.Env.DeliveryRetryDelay,
+		MaxDelay:    config.Env.DeliveryRetryMaxDelay,
+	})
 	if err != nil {
 		customlogger.LogFatal(fmt.Sprintf("Failed to initialize RabbitMQ consumer:\n%s", err.Error()))
 	}
 
+	// Load the auto-fix rules applied to failed flora submissions
+	fixer, err := flora.LoadAutoFixer(config.Env.AutoFixRulesFile)
+	if err != nil {
+		customlogger.LogFatal(fmt.Sprintf("Failed to load auto-fix rules:\n%s", err.Error()))
+	}
+	attempts := flora.NewAttemptCounter(context.Background(), db.GetCollection("chimera_flora", "autofix_attempts"), config.Env.AutoFixAttemptTTL)
+
 	// Start consuming messages
 	log.Println("Starting RabbitMQ consumer...")
-	if err := dump.InitFloraDumpService(consumer, collection); err != nil {
+	if err := dump.InitFloraDumpService(consumer, collection, fixer, attempts); err != nil {
 		customlogger.LogFatal(fmt.Sprintf("Failed to start flora dump service:\n%s", err.Error()))
 	}
 
@@ -125,9 +146,10 @@ func main() {
 	// set up cross-origin resource sharing (CORS) middleware
 	app.Use(cors.New(
 		cors.Config{
-			AllowOrigins: "*",
-			AllowMethods: "GET,POST,PUT,DELETE,OPTIONS",
-			AllowHeaders: "Content-Type, Authorization",
+			AllowOrigins:  "*",
+			AllowMethods:  "GET,POST,PUT,DELETE,OPTIONS",
+			AllowHeaders:  "Content-Type, Authorization, X-Request-ID",
+			ExposeHeaders: "X-Request-ID",
 		},
 	))
 
@@ -195,9 +217,14 @@ func main() {
 
 	// Set up route groups
 	actuatorGroup := app.Group("/actuator")
+	errorsGroup := app.Group("/errors")
 
 	// Register routes
 	actuators.ActuatorRouter(actuatorGroup)
+	errordump.ErrorDumpRouter(errorsGroup, map[string]*mongo.Collection{
+		errordump.SourceFlora: collection,
+		errordump.SourceUser:  db.GetCollection("chimera_user", "error_dump"),
+	}, consumer)
 
 	err = app.Listen(":" + config.Env.AppPort)
 	if err != nil {
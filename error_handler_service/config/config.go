@@ -18,6 +18,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -34,6 +35,11 @@ type Config struct {
 	DeregisterCriticalServiceAfter string
 	AppPort                        string
 	MongoDBURI                     string
+	DeliveryMaxAttempts            int           // Deliveries of an error event before it is parked
+	DeliveryRetryDelay             time.Duration // Backoff before the first retry, doubled for every further one
+	DeliveryRetryMaxDelay          time.Duration // Upper bound of the retry backoff
+	AutoFixRulesFile               string        // YAML or JSON file with the auto-fix rules
+	AutoFixAttemptTTL              time.Duration // How long the auto-fix attempts of a submission are remembered
 }
 
 var Env Config
@@ -64,7 +70,38 @@ func LoadConfig() {
 		DeregisterCriticalServiceAfter: os.Getenv("DEREGISTER_CRITICAL_SERVICE_AFTER"),
 		AppPort:                        os.Getenv("APP_PORT"),
 		MongoDBURI:                     os.Getenv("MONGODB_URI"),
+		DeliveryMaxAttempts:            getIntEnv("DELIVERY_MAX_ATTEMPTS", 5),
+		DeliveryRetryDelay:             getDurationEnv("DELIVERY_RETRY_DELAY", 5*time.Second),
+		DeliveryRetryMaxDelay:          getDurationEnv("DELIVERY_RETRY_MAX_DELAY", 5*time.Minute),
+		AutoFixRulesFile:               getEnv("AUTOFIX_RULES_FILE", "./config/autofix_rules.yaml"),
+		AutoFixAttemptTTL:              getDurationEnv("AUTOFIX_ATTEMPT_TTL", 7*24*time.Hour),
 	}
 
 	log.Println("Configuration loaded successfully!")
 }
+
+// getEnv reads an environment variable, falling back to def when it is unset
+func getEnv(key string, def string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return def
+}
+
+// getIntEnv reads an integer environment variable, falling back to def when it is unset or invalid
+func getIntEnv(key string, def int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// getDurationEnv reads a duration such as "30s", falling back to def when it is unset or invalid
+func getDurationEnv(key string, def time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return value
+}
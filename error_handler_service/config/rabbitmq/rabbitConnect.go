@@ -31,15 +31,17 @@ type Consumer struct {
 	conn    *amqp.Connection
 	channel *amqp.Channel
 	queue   string
+	retry   RetryConfig
 }
 
 // Singleton consumer instance
 var instance *Consumer
 var once sync.Once
 
-// NewConsumer initializes the singleton RabbitMQ consumer
-func NewConsumer(queueName string, maxAttempts int, retryDelay time.Duration) (*Consumer, error) {
+// NewConsumer initializes the singleton RabbitMQ consumer, failed deliveries are retried as set by retry
+func NewConsumer(queueName string, maxAttempts int, retryDelay time.Duration, retry RetryConfig) (*Consumer, error) {
 	var err error
+	retry = retry.withDefaults()
 
 	once.Do(func() {
 		rabbitMQURL := os.Getenv("RABBITMQ_URL")
@@ -79,9 +81,29 @@ func NewConsumer(queueName string, maxAttempts int, retryDelay time.Duration) (*
 				continue
 			}
 
+			// Declare where failed deliveries wait for their next attempt
+			if topologyErr := declareRetryTopology(ch, queueName, retry); topologyErr != nil {
+				err = topologyErr
+				logger.LogError(fmt.Sprintf("Failed to declare retry queues (attempt %d/%d): %v", i, maxAttempts, err))
+				ch.Close()
+				conn.Close()
+				time.Sleep(retryDelay)
+				continue
+			}
+
+			// Confirm publishes, so a failed delivery is only acknowledged once its copy is safely queued
+			if confirmErr := ch.Confirm(false); confirmErr != nil {
+				err = confirmErr
+				logger.LogError(fmt.Sprintf("Failed to enable publisher confirms (attempt %d/%d): %v", i, maxAttempts, err))
+				ch.Close()
+				conn.Close()
+				time.Sleep(retryDelay)
+				continue
+			}
+
 			// Successfully connected
 			logger.LogInfo(fmt.Sprintf("Connected to RabbitMQ on attempt %d/%d", i, maxAttempts))
-			instance = &Consumer{conn: conn, channel: ch, queue: queueName}
+			instance = &Consumer{conn: conn, channel: ch, queue: queueName, retry: retry}
 			err = nil
 			return
 		}
@@ -91,7 +113,7 @@ func NewConsumer(queueName string, maxAttempts int, retryDelay time.Duration) (*
 }
 
 // Consume starts consuming messages, but processing is handled by the service
-func (c *Consumer) Consume(handler func(body []byte, deliveryTag uint64)) error {
+func (c *Consumer) Consume(handler func(body []byte, deliveryTag uint64, headers amqp.Table)) error {
 	msgs, err := c.channel.Consume(
 		c.queue,
 		"",    // consumer tag
@@ -109,8 +131,8 @@ func (c *Consumer) Consume(handler func(body []byte, deliveryTag uint64)) error
 
 	go func() {
 		for msg := range msgs {
-			// Pass the body and delivery tag to the handler
-			handler(msg.Body, msg.DeliveryTag)
+			// Pass the body, delivery tag and headers to the handler
+			handler(msg.Body, msg.DeliveryTag, msg.Headers)
 		}
 	}()
 
@@ -139,6 +161,11 @@ func (c *Consumer) Close() {
 
 // SendMessage sends a message to a RabbitMQ queue, allowing dynamic queue names
 func (c *Consumer) SendMessage(queueName string, message map[string]interface{}) error {
+	return c.SendMessageWithHeaders(queueName, message, nil)
+}
+
+// SendMessageWithHeaders sends a message with the given headers to a RabbitMQ queue
+func (c *Consumer) SendMessageWithHeaders(queueName string, message map[string]interface{}, headers amqp.Table) error {
 	// If channel is not available, return an error
 	if c.channel == nil {
 		return fmt.Errorf("channel is not open")
@@ -168,6 +195,7 @@ func (c *Consumer) SendMessage(queueName string, message map[string]interface{})
 		false,     // immediate
 		amqp.Publishing{
 			ContentType: "application/json",
+			Headers:     headers,
 			Body:        body,
 		},
 	)
@@ -0,0 +1,239 @@
+// Copyright 2025 Naveen R
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	logger "project_chimera/error_handle_service/pkg/logger"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Headers carried by retried and parked deliveries
+const (
+	AttemptHeader   = "x-attempt"    // Delivery attempt the message is on, the first delivery has none
+	LastErrorHeader = "x-last-error" // Why the previous attempt failed
+	ParkedAtHeader  = "x-parked-at"  // When the message was moved to the parking lot
+)
+
+const (
+	defaultDeliveryAttempts = 5
+	defaultRetryDelay       = 5 * time.Second
+	defaultRetryMaxDelay    = 5 * time.Minute
+
+	parkingLotSuffix = ".parking-lot"
+
+	publishConfirmTimeout = 5 * time.Second
+)
+
+// RetryConfig bounds how often a failed delivery is retried
+type RetryConfig struct {
+	MaxAttempts int           // Deliveries before a message is parked, including the first one
+	BaseDelay   time.Duration // Delay before the first retry, doubled for every further one
+	MaxDelay    time.Duration // Upper bound of the delay
+}
+
+func (r RetryConfig) withDefaults() RetryConfig {
+	if r.MaxAttempts <= 0 {
+		r.MaxAttempts = defaultDeliveryAttempts
+	}
+	if r.BaseDelay <= 0 {
+		r.BaseDelay = defaultRetryDelay
+	}
+	if r.MaxDelay <= 0 {
+		r.MaxDelay = defaultRetryMaxDelay
+	}
+	return r
+}
+
+// delay returns how long a message waits after its attempt failed
+func (r RetryConfig) delay(attempt int) time.Duration {
+	delay := r.BaseDelay
+	for i := 1; i < attempt && delay < r.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > r.MaxDelay {
+		delay = r.MaxDelay
+	}
+	return delay
+}
+
+// DeadLetterExchange is the exchange failed deliveries of a queue are published to
+func DeadLetterExchange(queueName string) string {
+	return queueName + ".dlx"
+}
+
+// RetryQueue is the queue a message waits in after its attempt failed
+func RetryQueue(queueName string, attempt int) string {
+	return queueName + ".retry." + strconv.Itoa(attempt)
+}
+
+// ParkingLotQueue holds the messages that failed every attempt, for inspection and manual replay
+func ParkingLotQueue(queueName string) string {
+	return queueName + parkingLotSuffix
+}
+
+// declareRetryTopology declares the dead letter exchange, one retry queue per attempt and the parking lot.
+// Other services declare the main queue without arguments, so it cannot dead-letter by itself: failed
+// deliveries are published to the exchange explicitly and each retry queue dead-letters back to the main
+// queue once its TTL expires. A queue per attempt keeps short delays from waiting behind longer ones.
+// RabbitMQ refuses to redeclare a queue with other arguments, so changed delays need the retry queues deleted.
+func declareRetryTopology(ch *amqp.Channel, queueName string, retry RetryConfig) error {
+	exchange := DeadLetterExchange(queueName)
+	if err := ch.ExchangeDeclare(exchange, amqp.ExchangeDirect, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare exchange %s: %v", exchange, err)
+	}
+
+	for attempt := 1; attempt < retry.MaxAttempts; attempt++ {
+		name := RetryQueue(queueName, attempt)
+		args := amqp.Table{
+			"x-message-ttl":             retry.delay(attempt).Milliseconds(),
+			"x-dead-letter-exchange":    "",
+			"x-dead-letter-routing-key": queueName,
+		}
+		if _, err := ch.QueueDeclare(name, true, false, false, false, args); err != nil {
+			return fmt.Errorf("failed to declare queue %s: %v", name, err)
+		}
+		if err := ch.QueueBind(name, name, exchange, false, nil); err != nil {
+			return fmt.Errorf("failed to bind queue %s: %v", name, err)
+		}
+	}
+
+	parking := ParkingLotQueue(queueName)
+	if _, err := ch.QueueDeclare(parking, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare queue %s: %v", parking, err)
+	}
+	if err := ch.QueueBind(parking, parking, exchange, false, nil); err != nil {
+		return fmt.Errorf("failed to bind queue %s: %v", parking, err)
+	}
+
+	return nil
+}
+
+// Attempt returns the delivery attempt recorded in the headers, starting at 1
+func Attempt(headers amqp.Table) int {
+	switch attempt := headers[AttemptHeader].(type) {
+	case int32:
+		return max(int(attempt), 1)
+	case int64:
+		return max(int(attempt), 1)
+	case int:
+		return max(attempt, 1)
+	}
+	return 1
+}
+
+// Retry schedules a failed delivery for another attempt after the backoff delay, or parks it once
+// MaxAttempts deliveries failed, and acknowledges the original. The delivery is requeued if it
+// cannot be republished, so that it is not lost.
+func (c *Consumer) Retry(deliveryTag uint64, body []byte, headers amqp.Table, cause error) {
+	attempt := Attempt(headers)
+	if attempt >= c.retry.MaxAttempts {
+		logger.LogError(fmt.Sprintf("Message failed %d attempts, moving it to %s: %v", attempt, ParkingLotQueue(c.queue), cause))
+		c.Park(deliveryTag, body, headers, cause)
+		return
+	}
+
+	retryHeaders := copyHeaders(headers)
+	retryHeaders[AttemptHeader] = int32(attempt + 1)
+	retryHeaders[LastErrorHeader] = cause.Error()
+
+	routingKey := RetryQueue(c.queue, attempt)
+	if err := c.publishFailed(routingKey, body, retryHeaders); err != nil {
+		logger.LogError(fmt.Sprintf("Failed to schedule retry of message: %v", err))
+		c.requeue(deliveryTag)
+		return
+	}
+
+	logger.LogInfo(fmt.Sprintf("Attempt %d of message failed, retrying in %s: %v", attempt, c.retry.delay(attempt), cause))
+	c.ack(deliveryTag)
+}
+
+// Park moves a delivery to the parking lot right away, for messages that cannot succeed on a retry
+func (c *Consumer) Park(deliveryTag uint64, body []byte, headers amqp.Table, cause error) {
+	parkedHeaders := copyHeaders(headers)
+	parkedHeaders[AttemptHeader] = int32(Attempt(headers))
+	parkedHeaders[LastErrorHeader] = cause.Error()
+	parkedHeaders[ParkedAtHeader] = time.Now().UTC().Format(time.RFC3339)
+
+	if err := c.publishFailed(ParkingLotQueue(c.queue), body, parkedHeaders); err != nil {
+		logger.LogError(fmt.Sprintf("Failed to park message: %v", err))
+		c.requeue(deliveryTag)
+		return
+	}
+
+	logger.LogInfo("Message parked in " + ParkingLotQueue(c.queue))
+	c.ack(deliveryTag)
+}
+
+// publishFailed publishes a failed delivery to the dead letter exchange and waits for the broker to
+// confirm it, so the original is never acknowledged while its copy could still be lost
+func (c *Consumer) publishFailed(routingKey string, body []byte, headers amqp.Table) error {
+	ctx, cancel := context.WithTimeout(context.Background(), publishConfirmTimeout)
+	defer cancel()
+
+	confirmation, err := c.channel.PublishWithDeferredConfirmWithContext(
+		ctx,
+		DeadLetterExchange(c.queue), // exchange
+		routingKey,                  // routing key
+		false,                       // mandatory
+		false,                       // immediate
+		amqp.Publishing{
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			Headers:      headers,
+			Body:         body,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if confirmation == nil {
+		return fmt.Errorf("channel is not in confirm mode")
+	}
+
+	acked, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		return fmt.Errorf("no confirm for message to %s: %v", routingKey, err)
+	}
+	if !acked {
+		return fmt.Errorf("broker rejected message to %s", routingKey)
+	}
+	return nil
+}
+
+func (c *Consumer) ack(deliveryTag uint64) {
+	if err := c.channel.Ack(deliveryTag, false); err != nil {
+		logger.LogError("Failed to acknowledge message: " + err.Error())
+	}
+}
+
+func (c *Consumer) requeue(deliveryTag uint64) {
+	if err := c.channel.Nack(deliveryTag, false, true); err != nil {
+		logger.LogError("Failed to requeue message: " + err.Error())
+	}
+}
+
+func copyHeaders(headers amqp.Table) amqp.Table {
+	copied := amqp.Table{}
+	for key, value := range headers {
+		copied[key] = value
+	}
+	return copied
+}
@@ -0,0 +1,81 @@
+// Copyright 2025 Naveen R
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package rabbitmq
+
+import (
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestRetryConfigDelay(t *testing.T) {
+	retry := RetryConfig{MaxAttempts: 10, BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 0, want: time.Second},
+		{attempt: 1, want: time.Second},
+		{attempt: 2, want: 2 * time.Second},
+		{attempt: 3, want: 4 * time.Second},
+		{attempt: 4, want: 8 * time.Second},
+		{attempt: 5, want: 10 * time.Second},
+		{attempt: 50, want: 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := retry.delay(tt.attempt); got != tt.want {
+			t.Errorf("delay(%d) = %s, want %s", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestRetryConfigWithDefaults(t *testing.T) {
+	retry := RetryConfig{}.withDefaults()
+
+	if retry.MaxAttempts != defaultDeliveryAttempts || retry.BaseDelay != defaultRetryDelay || retry.MaxDelay != defaultRetryMaxDelay {
+		t.Errorf("withDefaults() = %+v", retry)
+	}
+	if got := retry.delay(retry.MaxAttempts); got > retry.MaxDelay {
+		t.Errorf("delay(%d) = %s, exceeds MaxDelay %s", retry.MaxAttempts, got, retry.MaxDelay)
+	}
+}
+
+func TestAttempt(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers amqp.Table
+		want    int
+	}{
+		{name: "no headers", headers: nil, want: 1},
+		{name: "missing header", headers: amqp.Table{"other": int32(3)}, want: 1},
+		{name: "int32", headers: amqp.Table{AttemptHeader: int32(3)}, want: 3},
+		{name: "int64", headers: amqp.Table{AttemptHeader: int64(4)}, want: 4},
+		{name: "int", headers: amqp.Table{AttemptHeader: 5}, want: 5},
+		{name: "zero", headers: amqp.Table{AttemptHeader: int32(0)}, want: 1},
+		{name: "negative", headers: amqp.Table{AttemptHeader: int64(-2)}, want: 1},
+		{name: "unsupported type", headers: amqp.Table{AttemptHeader: "3"}, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Attempt(tt.headers); got != tt.want {
+				t.Errorf("Attempt() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
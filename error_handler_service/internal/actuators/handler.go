@@ -39,7 +39,7 @@ func NewActuatorHandler(service ActuatorService) ActuatorHandler {
 // @Tags Actuator
 // @Produce json
 // @Success 200 {object} common.SuccessResponse
-// @Failure 500 {object} common.ErrorResponse
+// @Failure 500 {object} common.Problem
 // @Router /actuator/health [get]
 func (h *actuatorHandler) Health(c *fiber.Ctx) error {
 	healthMessage := h.service.GetHealthMessage()
@@ -16,14 +16,16 @@ package dump
 
 import (
 	"project_chimera/error_handle_service/config/rabbitmq"
+	"project_chimera/error_handle_service/internal/flora"
 	logger "project_chimera/error_handle_service/pkg/logger"
 
+	"github.com/rabbitmq/amqp091-go"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // OrderHandler defines the interface for handling order-related requests
 type FloraDumpHandler interface {
-	ConsumeMessage(body []byte, deliveryTag uint64)
+	ConsumeMessage(body []byte, deliveryTag uint64, headers amqp091.Table)
 }
 
 // orderHandler is the concrete implementation of OrderHandler
@@ -37,13 +39,13 @@ func NewFloraDumpHandler(service FloraDumpService) FloraDumpHandler {
 }
 
 // ConsumeMessage processes incoming RabbitMQ messages for orders
-func (h *floraDumpHandler) ConsumeMessage(body []byte, deliveryTag uint64) {
-	h.service.ProcessFloraDumpEvent(body, deliveryTag)
+func (h *floraDumpHandler) ConsumeMessage(body []byte, deliveryTag uint64, headers amqp091.Table) {
+	h.service.ProcessFloraDumpEvent(body, deliveryTag, headers)
 }
 
 // InitOrderService initializes the order service consumer
-func InitFloraDumpService(consumer *rabbitmq.Consumer, collection *mongo.Collection) error {
-	service := NewFloraDumpService(consumer.GetChannel(), collection)
+func InitFloraDumpService(consumer *rabbitmq.Consumer, collection *mongo.Collection, fixer flora.AutoFixer, attempts flora.AttemptCounter) error {
+	service := NewFloraDumpService(consumer.GetChannel(), collection, consumer, fixer, attempts)
 	handler := NewFloraDumpHandler(service)
 
 	// Pass handler's method to the RabbitMQ consumer
@@ -18,6 +18,7 @@ import (
 	"context"
 	"encoding/json"
 	"project_chimera/error_handle_service/config/db"
+	"project_chimera/error_handle_service/internal/errordump"
 	"project_chimera/error_handle_service/internal/flora"
 	"project_chimera/error_handle_service/pkg/common"
 	logger "project_chimera/error_handle_service/pkg/logger"
@@ -30,92 +31,211 @@ import (
 
 // FloraDumpService defines the interface for order processing
 type FloraDumpService interface {
-	ProcessFloraDumpEvent(body []byte, deliveryTag uint64)
+	ProcessFloraDumpEvent(body []byte, deliveryTag uint64, headers amqp091.Table)
+}
+
+const (
+	// submissionIDHeader correlates flora events with the gene bank submission that caused them
+	submissionIDHeader = "x-submission-id"
+	// submissionQueue receives the submission status events read by the gene bank service
+	submissionQueue = "flora_submission_queue"
+)
+
+// DeliveryRetrier settles deliveries that could not be processed, it is implemented by rabbitmq.Consumer
+type DeliveryRetrier interface {
+	// Retry schedules the delivery for another attempt, parking it once the attempts are used up
+	Retry(deliveryTag uint64, body []byte, headers amqp091.Table, cause error)
+	// Park moves the delivery to the parking lot right away
+	Park(deliveryTag uint64, body []byte, headers amqp091.Table, cause error)
+}
+
+// delivery is a message taken from the error queue
+type delivery struct {
+	body    []byte
+	tag     uint64
+	headers amqp091.Table
 }
 
 // floraDumpService is the concrete implementation of FloraDumpService
 type floraDumpService struct {
 	channel    *amqp091.Channel
 	collection *mongo.Collection
+	retrier    DeliveryRetrier
+	fixer      flora.AutoFixer
+	attempts   flora.AttemptCounter
 }
 
 // NewFloraDumpService creates a new FloraDumpService instance with MongoDB integration
-func NewFloraDumpService(channel *amqp091.Channel, collection *mongo.Collection) FloraDumpService {
+func NewFloraDumpService(channel *amqp091.Channel, collection *mongo.Collection, retrier DeliveryRetrier, fixer flora.AutoFixer, attempts flora.AttemptCounter) FloraDumpService {
 	return &floraDumpService{
 		channel:    channel,
 		collection: collection,
+		retrier:    retrier,
+		fixer:      fixer,
+		attempts:   attempts,
 	}
 }
 
 // ProcessOrderEvent handles RabbitMQ messages for orders
-func (s *floraDumpService) ProcessFloraDumpEvent(body []byte, deliveryTag uint64) {
+func (s *floraDumpService) ProcessFloraDumpEvent(body []byte, deliveryTag uint64, headers amqp091.Table) {
 	var floraResp models.FloraResponse
 	var errResp models.ErrorDataDTO
+	msg := delivery{body: body, tag: deliveryTag, headers: headers}
+
+	// Gene bank error events carry arbitrary data, so they are routed before the flora parsing
+	var envelope struct {
+		Pattern string `json:"pattern"`
+	}
+	if err := json.Unmarshal(body, &envelope); err == nil && models.IsErrorEvent(envelope.Pattern) {
+		s.handleErrorEvent(msg)
+		return
+	}
 
+	// A message that cannot be parsed fails the same way on every attempt, so it is parked right away
 	err := json.Unmarshal(body, &floraResp)
 	if err != nil {
 		logger.LogError("Failed to parse message body (FloraResponse): " + err.Error())
+		s.parkMessage(msg, err)
 		return
 	}
 
 	err = json.Unmarshal(body, &errResp)
 	if err != nil {
 		logger.LogError("Failed to parse message body (ErrorDataDTO): " + err.Error())
+		s.parkMessage(msg, err)
 		return
 	}
 
 	switch {
 	case strings.HasPrefix(floraResp.Pattern, "flora."):
-		s.handleFloraEvents(floraResp, deliveryTag)
+		submissionID, _ := headers[submissionIDHeader].(string)
+		s.handleFloraEvents(floraResp, msg, submissionID)
 	case strings.HasPrefix(floraResp.Pattern, "user."):
-		s.handleUserEvents(errResp, deliveryTag)
+		s.handleUserEvents(errResp, msg)
 	default:
 		logger.LogError("Unknown event type: " + floraResp.Pattern)
-		s.acknowledgeMessage(deliveryTag)
+		s.acknowledgeMessage(msg.tag)
 	}
 }
 
 // Method to handle flora events
-func (s *floraDumpService) handleFloraEvents(floraResp models.FloraResponse, deliveryTag uint64) {
+func (s *floraDumpService) handleFloraEvents(floraResp models.FloraResponse, msg delivery, submissionID string) {
 	switch floraResp.Pattern {
-	case "flora.getall", "getbyid":
-		logger.LogInfo("Processing flora.getall/getbyid event")
-		s.saveFloraToDB(floraResp)
-		s.acknowledgeMessage(deliveryTag)
-		return
-	case "flora.created":
+	case models.EventFloraCreated:
 		logger.LogInfo("Processing flora.created event")
 
-		res, err := flora.AutoFixFlora(floraResp)
+		// Events without a submission ID get a correlation ID, which comes back if the fix fails again
+		if submissionID == "" {
+			submissionID = flora.NewCorrelationID()
+		}
+		key := flora.AttemptKey(submissionID)
+
+		// Fixed floras come back here if they fail again, counting the republished fixes stops them from looping
+		attempts, err := s.attempts.Count(context.Background(), key)
+		if err != nil {
+			logger.LogError("Failed to count auto-fix attempts: " + err.Error())
+			s.retryMessage(msg, err)
+			return
+		}
+
+		res, err := s.fixer.AutoFixFlora(floraResp, attempts+1)
 		if err != nil {
 			logger.LogError("Failed to fix flora data: " + err.Error() + " sending to error dump in db")
-			s.saveFloraToDB(floraResp)
-			s.acknowledgeMessage(deliveryTag)
+			if err := s.saveFailure(floraResp, submissionID); err != nil {
+				s.retryMessage(msg, err)
+				return
+			}
+			s.sendSubmissionStatus(submissionID, "failed", floraResp.Data.Data.Error)
+			s.acknowledgeMessage(msg.tag)
 			return
 		} else {
 			logger.LogInfo("Flora data fixed successfully and sending to upstream queue")
-			s.sendMessageToQueueIfExists("flora_upstream_queue", res.Data.Data.Values, "add_flora")
-			s.acknowledgeMessage(deliveryTag)
+			if err := s.sendMessageToQueueIfExists("flora_upstream_queue", res.Data.Data.Values, "add_flora", submissionID); err != nil {
+				s.retryMessage(msg, err)
+				return
+			}
+			if err := s.attempts.Add(context.Background(), key); err != nil {
+				logger.LogError("Failed to record auto-fix attempt: " + err.Error())
+			}
+			s.sendSubmissionStatus(submissionID, "auto-fixed", floraResp.Data.Data.Error)
+			s.acknowledgeMessage(msg.tag)
 			return
 		}
-	case "flora.updated":
+	case models.EventFloraUpdated:
 		logger.LogInfo("Processing flora.updated event")
-		s.saveFloraToDB(floraResp)
-		s.acknowledgeMessage(deliveryTag)
+		if err := s.saveFailure(floraResp, submissionID); err != nil {
+			s.retryMessage(msg, err)
+			return
+		}
+		s.sendSubmissionStatus(submissionID, "failed", floraResp.Data.Data.Error)
+		s.acknowledgeMessage(msg.tag)
 		return
-	case "flora.deleted":
+	case models.EventFloraDeleted:
 		logger.LogInfo("Processing flora.deleted event")
-		s.saveFloraToDB(floraResp)
-		s.acknowledgeMessage(deliveryTag)
+		if err := s.saveFloraToDB(floraResp); err != nil {
+			s.retryMessage(msg, err)
+			return
+		}
+		s.acknowledgeMessage(msg.tag)
+		return
+	case models.EventFloraSubmission:
+		logger.LogInfo("Processing flora.submission event")
+		s.handleReplaySucceeded(msg, submissionID)
 		return
 	default:
 		logger.LogError("Unhandled flora event type: " + floraResp.Pattern)
-		s.acknowledgeMessage(deliveryTag)
+		s.acknowledgeMessage(msg.tag)
+	}
+}
+
+// Method to record that flora_upstream_service applied a replayed command
+func (s *floraDumpService) handleReplaySucceeded(msg delivery, submissionID string) {
+	var event struct {
+		Data struct {
+			SubmissionID string `json:"submission_id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(msg.body, &event); err != nil {
+		logger.LogError("Failed to parse message body (flora.submission): " + err.Error())
+		s.parkMessage(msg, err)
+		return
+	}
+	if submissionID == "" {
+		submissionID = event.Data.SubmissionID
+	}
+
+	if !errordump.IsReplaySubmission(submissionID) {
+		logger.LogError("Ignoring flora.submission event of submission " + submissionID + ", it is not a replay")
+		s.acknowledgeMessage(msg.tag)
+		return
+	}
+	if err := errordump.RecordReplayOutcome(context.Background(), s.collection, submissionID, errordump.ReplaySucceeded, ""); err != nil {
+		logger.LogError("Failed to record replay outcome: " + err.Error())
+		s.retryMessage(msg, err)
+		return
+	}
+	s.acknowledgeMessage(msg.tag)
+}
+
+// Method to handle the error events reported by the gene bank service
+func (s *floraDumpService) handleErrorEvent(msg delivery) {
+	var event models.ErrorEventMessage
+	if err := json.Unmarshal(msg.body, &event); err != nil {
+		logger.LogError("Failed to parse message body (ErrorEventMessage): " + err.Error())
+		s.parkMessage(msg, err)
+		return
+	}
+
+	logger.LogInfo("Processing " + event.Pattern + " event")
+	if err := s.saveErrorEventToDB(event); err != nil {
+		s.retryMessage(msg, err)
+		return
 	}
+	s.acknowledgeMessage(msg.tag)
 }
 
 // Method to handle user signup event
-func (s *floraDumpService) handleUserEvents(resp models.ErrorDataDTO, deliveryTag uint64) {
+func (s *floraDumpService) handleUserEvents(resp models.ErrorDataDTO, msg delivery) {
 	switch resp.Pattern {
 	case "user.create", "user.signup", "user.login",
 		"user.delete", "user.softdelete",
@@ -133,20 +253,35 @@ func (s *floraDumpService) handleUserEvents(resp models.ErrorDataDTO, deliveryTa
 
 		// Save only actual "error" events to the collection
 		if resp.Data.Status != "Success" {
-			s.saveToCustomCollection(resp, "chimera_user", "error_dump")
+			if err := s.saveToCustomCollection(resp, "chimera_user", "error_dump"); err != nil {
+				s.retryMessage(msg, err)
+				return
+			}
 		}
 
-		s.acknowledgeMessage(deliveryTag)
+		s.acknowledgeMessage(msg.tag)
 		return
 
 	default:
 		logger.LogError("Unhandled user event type: " + resp.Pattern)
-		s.acknowledgeMessage(deliveryTag)
+		s.acknowledgeMessage(msg.tag)
+	}
+}
+
+// Method to store a failed flora command, a replayed command failing again is recorded on the replayed document
+func (s *floraDumpService) saveFailure(body models.FloraResponse, submissionID string) error {
+	if errordump.IsReplaySubmission(submissionID) {
+		err := errordump.RecordReplayOutcome(context.Background(), s.collection, submissionID, errordump.ReplayRejected, body.Data.Data.Error)
+		if err != nil {
+			logger.LogError("Failed to record replay outcome: " + err.Error())
+		}
+		return err
 	}
+	return s.saveFloraToDB(body)
 }
 
 // Method to insert flora data into MongoDB
-func (s *floraDumpService) saveFloraToDB(body models.FloraResponse) {
+func (s *floraDumpService) saveFloraToDB(body models.FloraResponse) error {
 	// You can modify the data structure as per your MongoDB schema
 	document := common.FloraResponseToBson(body)
 
@@ -154,21 +289,36 @@ func (s *floraDumpService) saveFloraToDB(body models.FloraResponse) {
 	_, err := s.collection.InsertOne(context.Background(), document)
 	if err != nil {
 		logger.LogError("Failed to insert flora data into MongoDB: " + err.Error())
-	} else {
-		logger.LogInfo("Flora data inserted into MongoDB successfully")
+		return err
+	}
+	logger.LogInfo("Flora data inserted into MongoDB successfully")
+	return nil
+}
+
+// Method to insert a gene bank error event into MongoDB
+func (s *floraDumpService) saveErrorEventToDB(event models.ErrorEventMessage) error {
+	document := common.ErrorEventToBson(event)
+
+	_, err := s.collection.InsertOne(context.Background(), document)
+	if err != nil {
+		logger.LogError("Failed to insert error event into MongoDB: " + err.Error())
+		return err
 	}
+	logger.LogInfo("Error event inserted into MongoDB successfully")
+	return nil
 }
 
 // method to insert in custom mongoDB collection
-func (s *floraDumpService) saveToCustomCollection(body models.ErrorDataDTO, dbName string, collectionName string) {
+func (s *floraDumpService) saveToCustomCollection(body models.ErrorDataDTO, dbName string, collectionName string) error {
 	collection := db.GetCollection(dbName, collectionName)
 	document := common.ErrorDataToBson(body)
 	_, err := collection.InsertOne(context.Background(), document)
 	if err != nil {
 		logger.LogError("Failed to insert data into" + dbName + "." + collectionName + ": " + err.Error())
-	} else {
-		logger.LogInfo("Data inserted into " + dbName + "." + collectionName + " successfully")
+		return err
 	}
+	logger.LogInfo("Data inserted into " + dbName + "." + collectionName + " successfully")
+	return nil
 }
 
 // Method to acknowledge the RabbitMQ message
@@ -181,18 +331,58 @@ func (s *floraDumpService) acknowledgeMessage(deliveryTag uint64) {
 	}
 }
 
-// Method to reject the RabbitMQ message
-func (s *floraDumpService) rejectMessage(deliveryTag uint64) {
-	err := s.channel.Reject(deliveryTag, true)
+// Method to retry a message that failed, requeueing it directly would redeliver it in a loop
+func (s *floraDumpService) retryMessage(msg delivery, cause error) {
+	s.retrier.Retry(msg.tag, msg.body, msg.headers, cause)
+}
+
+// Method to park a message that cannot succeed on a retry
+func (s *floraDumpService) parkMessage(msg delivery, cause error) {
+	s.retrier.Park(msg.tag, msg.body, msg.headers, cause)
+}
+
+// Method to report the outcome of a gene bank submission, events without a submission ID,
+// replays and correlation IDs, which the gene bank does not know, are skipped
+func (s *floraDumpService) sendSubmissionStatus(submissionID string, status string, detail string) {
+	if submissionID == "" || errordump.IsReplaySubmission(submissionID) || flora.IsCorrelationID(submissionID) {
+		return
+	}
+
+	message := map[string]interface{}{
+		"pattern": "flora.submission",
+		"data": map[string]interface{}{
+			"submission_id": submissionID,
+			"status":        status,
+			"error":         detail,
+		},
+	}
+	messageBody, err := json.Marshal(message)
 	if err != nil {
-		logger.LogError("Failed to reject message: " + err.Error())
+		logger.LogError("Failed to marshal submission status to JSON: " + err.Error())
+		return
+	}
+
+	err = s.channel.Publish(
+		"",              // exchange
+		submissionQueue, // routing key (queue name)
+		false,           // mandatory
+		false,           // immediate
+		amqp091.Publishing{
+			ContentType:   "application/json",
+			CorrelationId: submissionID,
+			Headers:       amqp091.Table{submissionIDHeader: submissionID},
+			Body:          messageBody,
+		},
+	)
+	if err != nil {
+		logger.LogError("Failed to publish submission status: " + err.Error())
 	} else {
-		logger.LogInfo("Message rejected successfully")
+		logger.LogInfo("Submission " + submissionID + " reported as " + status)
 	}
 }
 
 // Method to publish a message to a queue if it exists
-func (s *floraDumpService) sendMessageToQueueIfExists(queueName string, data models.FloraData, pattern string) {
+func (s *floraDumpService) sendMessageToQueueIfExists(queueName string, data models.FloraData, pattern string, submissionID string) error {
 	message := map[string]interface{}{
 		"pattern": map[string]string{
 			"cmd": pattern,
@@ -203,7 +393,7 @@ func (s *floraDumpService) sendMessageToQueueIfExists(queueName string, data mod
 	messageBody, err := json.Marshal(message)
 	if err != nil {
 		logger.LogError("Failed to marshal FloraData to JSON: " + err.Error())
-		return
+		return err
 	}
 
 	// Try declaring the queue passively (it must already exist)
@@ -218,7 +408,7 @@ func (s *floraDumpService) sendMessageToQueueIfExists(queueName string, data mod
 
 	if err != nil {
 		logger.LogError("Queue does not exist or could not be declared passively: " + err.Error())
-		return
+		return err
 	}
 
 	// Publish the message to the queue
@@ -228,14 +418,26 @@ func (s *floraDumpService) sendMessageToQueueIfExists(queueName string, data mod
 		false,     // mandatory
 		false,     // immediate
 		amqp091.Publishing{
-			ContentType: "application/json",
-			Body:        messageBody,
+			ContentType:   "application/json",
+			CorrelationId: submissionID,
+			Headers:       submissionHeaders(submissionID),
+			Body:          messageBody,
 		},
 	)
 
 	if err != nil {
 		logger.LogError("Failed to publish message to queue: " + err.Error())
-	} else {
-		logger.LogInfo("Message published to queue: " + queueName)
+		return err
+	}
+	logger.LogInfo("Message published to queue: " + queueName)
+	return nil
+}
+
+// submissionHeaders forwards the submission ID so the resubmitted command stays correlated
+func submissionHeaders(submissionID string) amqp091.Table {
+	headers := amqp091.Table{}
+	if submissionID != "" {
+		headers[submissionIDHeader] = submissionID
 	}
+	return headers
 }
@@ -0,0 +1,244 @@
+// Copyright 2025 Naveen R
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package errordump
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrorDumpHandler defines the interface for the error dump handlers
+type ErrorDumpHandler interface {
+	ListErrors(c *fiber.Ctx) error
+	GetError(c *fiber.Ctx) error
+	HourlyCounts(c *fiber.Ctx) error
+	ReplayError(c *fiber.Ctx) error
+	ReplayErrors(c *fiber.Ctx) error
+}
+
+// errorDumpHandler is the concrete implementation of ErrorDumpHandler
+type errorDumpHandler struct {
+	service ErrorDumpService
+	replay  ReplayService
+}
+
+// NewErrorDumpHandler creates a new ErrorDumpHandler with the query and replay service dependencies
+func NewErrorDumpHandler(service ErrorDumpService, replay ReplayService) ErrorDumpHandler {
+	return &errorDumpHandler{service: service, replay: replay}
+}
+
+// ListErrors handler for the error dump
+// @Summary List errors
+// @Description List the errors of a source newest first. Pass next_cursor as cursor to get the next page.
+// @Tags Errors
+// @Produce json
+// @Param source query string false "Error dump to read, flora (default) or user"
+// @Param pattern query string false "Event pattern, e.g. flora.created"
+// @Param code query int false "Status code"
+// @Param response_type query string false "Response type"
+// @Param user_id query string false "User the error belongs to"
+// @Param kind query string false "Kind of the parsed error, e.g. invalid_value or unique"
+// @Param field query string false "Field named by the parsed error"
+// @Param from query string false "Start of the time range (RFC 3339), inclusive"
+// @Param to query string false "End of the time range (RFC 3339), exclusive"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param limit query int false "Page size, 50 by default and at most 200"
+// @Success 200 {object} ErrorPage
+// @Failure 400 {object} common.Problem
+// @Failure 500 {object} common.Problem
+// @Router /errors [get]
+func (h *errorDumpHandler) ListErrors(c *fiber.Ctx) error {
+	filter, err := parseFilter(c)
+	if err != nil {
+		return err
+	}
+
+	limit := c.QueryInt("limit", defaultLimit)
+	if limit <= 0 {
+		return &fiber.Error{Code: fiber.StatusBadRequest, Message: "limit must be a positive number"}
+	}
+
+	page, err := h.service.ListErrors(c.UserContext(), filter, c.Query("cursor"), limit)
+	if err != nil {
+		return err
+	}
+	return c.JSON(page)
+}
+
+// GetError handler for a single error
+// @Summary Get an error
+// @Description Get an error of any source by its ID
+// @Tags Errors
+// @Produce json
+// @Param id path string true "Error ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} common.Problem
+// @Failure 404 {object} common.Problem
+// @Failure 500 {object} common.Problem
+// @Router /errors/{id} [get]
+func (h *errorDumpHandler) GetError(c *fiber.Ctx) error {
+	document, err := h.service.GetError(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(document)
+}
+
+// HourlyCounts handler for the error dashboards
+// @Summary Count errors per pattern and hour
+// @Description Count the errors of a source matching the filters per pattern and hour, the last 24 hours unless from is set
+// @Tags Errors
+// @Produce json
+// @Param source query string false "Error dump to read, flora (default) or user"
+// @Param pattern query string false "Event pattern, e.g. flora.created"
+// @Param code query int false "Status code"
+// @Param response_type query string false "Response type"
+// @Param user_id query string false "User the error belongs to"
+// @Param kind query string false "Kind of the parsed error, e.g. invalid_value or unique"
+// @Param field query string false "Field named by the parsed error"
+// @Param from query string false "Start of the time range (RFC 3339), inclusive"
+// @Param to query string false "End of the time range (RFC 3339), exclusive"
+// @Success 200 {array} HourlyCount
+// @Failure 400 {object} common.Problem
+// @Failure 500 {object} common.Problem
+// @Router /errors/stats/hourly [get]
+func (h *errorDumpHandler) HourlyCounts(c *fiber.Ctx) error {
+	filter, err := parseFilter(c)
+	if err != nil {
+		return err
+	}
+
+	counts, err := h.service.HourlyCounts(c.UserContext(), filter)
+	if err != nil {
+		return err
+	}
+	return c.JSON(counts)
+}
+
+// ReplayError handler for a single dumped flora failure
+// @Summary Replay a flora failure
+// @Description Rebuild the add_flora or update_flora command of a dumped flora.created or flora.updated failure,
+// @Description publish it to flora_upstream_queue and record the attempt in the replays of the error.
+// @Description The attempt stays published until flora_upstream_service reports it succeeded or was rejected.
+// @Tags Errors
+// @Produce json
+// @Param id path string true "Error ID"
+// @Success 200 {object} ReplayResult
+// @Failure 400 {object} common.Problem
+// @Failure 404 {object} common.Problem
+// @Failure 422 {object} common.Problem
+// @Failure 500 {object} common.Problem
+// @Router /errors/{id}/replay [post]
+func (h *errorDumpHandler) ReplayError(c *fiber.Ctx) error {
+	result, err := h.replay.ReplayError(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(result)
+}
+
+// ReplayErrors handler for replaying dumped flora failures in bulk
+// @Summary Replay flora failures
+// @Description Replay the dumped flora failures matching the filter oldest first, skipping those whose last replay
+// @Description is pending or succeeded unless include_replayed is set. With dry_run the commands are only listed.
+// @Tags Errors
+// @Accept json
+// @Produce json
+// @Param request body ReplayRequest true "Failures to replay"
+// @Success 200 {object} ReplaySummary
+// @Failure 400 {object} common.Problem
+// @Failure 500 {object} common.Problem
+// @Router /errors/replay [post]
+func (h *errorDumpHandler) ReplayErrors(c *fiber.Ctx) error {
+	var request ReplayRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			return &fiber.Error{Code: fiber.StatusBadRequest, Message: "Invalid replay request: " + err.Error()}
+		}
+	}
+
+	summary, err := h.replay.ReplayErrors(c.UserContext(), request)
+	if err != nil {
+		return err
+	}
+	return c.JSON(summary)
+}
+
+// parseFilter reads the filters shared by the list and the hourly counts from the query string
+func parseFilter(c *fiber.Ctx) (ErrorFilter, error) {
+	filter := ErrorFilter{
+		Source:       c.Query("source"),
+		Pattern:      c.Query("pattern"),
+		ResponseType: c.Query("response_type"),
+		UserID:       c.Query("user_id"),
+		Kind:         c.Query("kind"),
+		Field:        c.Query("field"),
+	}
+
+	if code := c.Query("code"); code != "" {
+		value, err := strconv.Atoi(code)
+		if err != nil {
+			return ErrorFilter{}, &fiber.Error{Code: fiber.StatusBadRequest, Message: "code must be a number"}
+		}
+		filter.Code = value
+	}
+
+	var err error
+	if filter.From, err = parseTime(c, "from"); err != nil {
+		return ErrorFilter{}, err
+	}
+	if filter.To, err = parseTime(c, "to"); err != nil {
+		return ErrorFilter{}, err
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return ErrorFilter{}, &fiber.Error{Code: fiber.StatusBadRequest, Message: "from must be before to"}
+	}
+
+	return filter, nil
+}
+
+func parseTime(c *fiber.Ctx, key string) (time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, &fiber.Error{Code: fiber.StatusBadRequest, Message: key + " must be an RFC 3339 time, e.g. 2025-01-31T12:00:00Z"}
+	}
+	return parsed.UTC(), nil
+}
+
+// ErrorDumpRouter registers the error dump routes, sources maps a source name to its error_dump collection.
+// Replays resubmit flora failures, so they read the flora source and publish through publisher.
+func ErrorDumpRouter(router fiber.Router, sources map[string]*mongo.Collection, publisher Publisher) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	EnsureIndexes(ctx, sources)
+
+	service := NewErrorDumpService(sources)
+	replay := NewReplayService(sources[SourceFlora], publisher)
+	handler := NewErrorDumpHandler(service, replay)
+
+	router.Get("/", handler.ListErrors)
+	router.Get("/stats/hourly", handler.HourlyCounts)
+	router.Get("/:id", handler.GetError)
+	router.Post("/replay", handler.ReplayErrors)
+	router.Post("/:id/replay", handler.ReplayError)
+}
@@ -0,0 +1,332 @@
+// Copyright 2025 Naveen R
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package errordump
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	logger "project_chimera/error_handle_service/pkg/logger"
+	"project_chimera/error_handle_service/pkg/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rabbitmq/amqp091-go"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// upstreamQueue receives the flora commands of flora_upstream_service
+const upstreamQueue = "flora_upstream_queue"
+
+const (
+	// submissionIDHeader carries the replay submission ID to flora_upstream_service and back
+	submissionIDHeader = "x-submission-id"
+	// replaySubmissionPrefix marks the submission IDs of replays, flora_upstream_service reports
+	// their outcome to the error queue instead of the gene bank service
+	replaySubmissionPrefix = "replay:"
+)
+
+// Outcomes of a replay. A published replay waits for flora_upstream_service to report
+// whether it succeeded or was rejected again.
+const (
+	ReplayPublished = "published" // The command was published, its outcome is not known yet
+	ReplaySucceeded = "succeeded" // flora_upstream_service applied the command
+	ReplayRejected  = "rejected"  // flora_upstream_service failed the command again
+	ReplayFailed    = "failed"    // The command could not be published
+	ReplayDryRun    = "dry-run"   // The command would have been published
+)
+
+const (
+	defaultReplayLimit = 100
+	maxReplayLimit     = 1000
+)
+
+// Publisher sends a message to a queue, it is implemented by rabbitmq.Consumer
+type Publisher interface {
+	SendMessageWithHeaders(queueName string, message map[string]interface{}, headers amqp091.Table) error
+}
+
+// ReplayRequest selects the dumped flora failures a bulk replay resubmits
+type ReplayRequest struct {
+	Pattern         string    `json:"pattern"`          // flora.created or flora.updated, both by default
+	Code            int       `json:"code"`             // Status code
+	ResponseType    string    `json:"response_type"`    // Response type
+	UserID          string    `json:"user_id"`          // User the flora belongs to
+	Kind            string    `json:"kind"`             // Kind of the parsed error
+	Field           string    `json:"field"`            // Field named by the parsed error
+	From            time.Time `json:"from"`             // Start of the time range, inclusive
+	To              time.Time `json:"to"`               // End of the time range, exclusive
+	IncludeReplayed bool      `json:"include_replayed"` // Also resubmit failures whose last replay is pending or succeeded
+	DryRun          bool      `json:"dry_run"`          // Only list the commands that would be published
+	Limit           int       `json:"limit"`            // Failures to replay, 100 by default and at most 1000
+}
+
+// ReplayResult is the outcome of replaying one dumped failure
+type ReplayResult struct {
+	ID      string `json:"id"`
+	Command string `json:"command"`
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+}
+
+// ReplaySummary is the outcome of a bulk replay
+type ReplaySummary struct {
+	DryRun    bool           `json:"dry_run"`
+	Matched   int            `json:"matched"`
+	Published int            `json:"published"`
+	Failed    int            `json:"failed"`
+	Results   []ReplayResult `json:"results"`
+}
+
+// ReplayAttempt is recorded in the replays array of a dumped failure, the latest one also in last_replay.
+// Its status and error are updated once flora_upstream_service reports the outcome.
+type ReplayAttempt struct {
+	At           time.Time `bson:"at" json:"at"`
+	Command      string    `bson:"command" json:"command"`
+	SubmissionID string    `bson:"submission_id,omitempty" json:"submission_id,omitempty"`
+	Status       string    `bson:"status" json:"status"`
+	Error        string    `bson:"error,omitempty" json:"error,omitempty"`
+}
+
+// dumpedFlora is the part of a dumped flora failure needed to rebuild its command
+type dumpedFlora struct {
+	ObjectID       primitive.ObjectID     `bson:"_id"`
+	Pattern        string                 `bson:"pattern"`
+	FloraID        string                 `bson:"id"`
+	CommonName     string                 `bson:"common_name"`
+	ScientificName string                 `bson:"scientific_name"`
+	UserID         string                 `bson:"user_id"`
+	Type           string                 `bson:"type"`
+	Image          string                 `bson:"image"`
+	Description    string                 `bson:"description"`
+	Origin         string                 `bson:"origin"`
+	OtherDetails   map[string]interface{} `bson:"other_details"`
+}
+
+// ReplayService resubmits dumped flora failures to flora_upstream_service
+type ReplayService interface {
+	ReplayError(ctx context.Context, id string) (ReplayResult, error)
+	ReplayErrors(ctx context.Context, request ReplayRequest) (ReplaySummary, error)
+}
+
+// replayService is the concrete implementation of ReplayService
+type replayService struct {
+	collection *mongo.Collection
+	publisher  Publisher
+}
+
+// NewReplayService creates a new ReplayService over the flora error dump
+func NewReplayService(collection *mongo.Collection, publisher Publisher) ReplayService {
+	return &replayService{collection: collection, publisher: publisher}
+}
+
+// ReplayError resubmits a single dumped failure, whatever its earlier replays
+func (s *replayService) ReplayError(ctx context.Context, id string) (ReplayResult, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ReplayResult{}, &fiber.Error{Code: fiber.StatusBadRequest, Message: "Invalid error ID " + id}
+	}
+
+	var flora dumpedFlora
+	err = s.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&flora)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ReplayResult{}, &fiber.Error{Code: fiber.StatusNotFound, Message: "Error " + id + " not found"}
+	}
+	if err != nil {
+		return ReplayResult{}, err
+	}
+
+	if _, err := replayCommand(flora); err != nil {
+		return ReplayResult{}, &fiber.Error{Code: fiber.StatusUnprocessableEntity, Message: err.Error()}
+	}
+
+	return s.replay(ctx, flora)
+}
+
+// ReplayErrors resubmits the dumped failures matching the request, oldest first
+func (s *replayService) ReplayErrors(ctx context.Context, request ReplayRequest) (ReplaySummary, error) {
+	if request.Pattern != "" && request.Pattern != models.EventFloraCreated && request.Pattern != models.EventFloraUpdated {
+		return ReplaySummary{}, &fiber.Error{Code: fiber.StatusBadRequest, Message: "Only " + models.EventFloraCreated + " and " + models.EventFloraUpdated + " failures can be replayed"}
+	}
+	if !request.From.IsZero() && !request.To.IsZero() && !request.From.Before(request.To) {
+		return ReplaySummary{}, &fiber.Error{Code: fiber.StatusBadRequest, Message: "from must be before to"}
+	}
+
+	limit := request.Limit
+	if limit <= 0 {
+		limit = defaultReplayLimit
+	}
+	if limit > maxReplayLimit {
+		limit = maxReplayLimit
+	}
+
+	query := buildQuery(ErrorFilter{
+		Pattern:      request.Pattern,
+		Code:         request.Code,
+		ResponseType: request.ResponseType,
+		UserID:       request.UserID,
+		Kind:         request.Kind,
+		Field:        request.Field,
+		From:         request.From.UTC(),
+		To:           request.To.UTC(),
+	})
+	if request.Pattern == "" {
+		query = append(query, bson.E{Key: "pattern", Value: bson.M{"$in": bson.A{models.EventFloraCreated, models.EventFloraUpdated}}})
+	}
+	if !request.IncludeReplayed {
+		query = append(query, bson.E{Key: "last_replay.status", Value: bson.M{"$nin": bson.A{ReplayPublished, ReplaySucceeded}}})
+	}
+
+	// Oldest first, so floras are resubmitted in the order they originally failed
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(limit))
+	results, err := s.collection.Find(ctx, query, opts)
+	if err != nil {
+		return ReplaySummary{}, err
+	}
+
+	var floras []dumpedFlora
+	if err := results.All(ctx, &floras); err != nil {
+		return ReplaySummary{}, err
+	}
+
+	summary := ReplaySummary{DryRun: request.DryRun, Matched: len(floras), Results: []ReplayResult{}}
+	for _, flora := range floras {
+		var result ReplayResult
+		if request.DryRun {
+			result = ReplayResult{ID: flora.ObjectID.Hex(), Status: ReplayDryRun}
+			if command, err := replayCommand(flora); err != nil {
+				result.Status = ReplayFailed
+				result.Error = err.Error()
+			} else {
+				result.Command = command
+			}
+		} else if result, err = s.replay(ctx, flora); err != nil {
+			return summary, err
+		}
+
+		switch result.Status {
+		case ReplayPublished:
+			summary.Published++
+		case ReplayFailed:
+			summary.Failed++
+		}
+		summary.Results = append(summary.Results, result)
+	}
+
+	return summary, nil
+}
+
+// replay publishes the command of a dumped failure and records the attempt on its document.
+// The command carries a submission ID naming the attempt, so its outcome can be recorded later.
+func (s *replayService) replay(ctx context.Context, flora dumpedFlora) (ReplayResult, error) {
+	attempt := ReplayAttempt{At: time.Now().UTC(), Status: ReplayPublished}
+	attempt.SubmissionID = replaySubmissionID(flora.ObjectID, attempt.At)
+
+	command, err := replayCommand(flora)
+	if err == nil {
+		attempt.Command = command
+		err = s.publisher.SendMessageWithHeaders(upstreamQueue, map[string]interface{}{
+			"pattern": map[string]string{"cmd": command},
+			"data":    replayPayload(flora),
+		}, amqp091.Table{submissionIDHeader: attempt.SubmissionID})
+	}
+	if err != nil {
+		attempt.Status = ReplayFailed
+		attempt.Error = err.Error()
+		logger.LogError("Failed to replay error " + flora.ObjectID.Hex() + ": " + err.Error())
+	}
+
+	_, updateErr := s.collection.UpdateByID(ctx, flora.ObjectID, bson.M{
+		"$push": bson.M{"replays": attempt},
+		"$set":  bson.M{"last_replay": attempt},
+	})
+	if updateErr != nil {
+		// The command may already be on its way, so the failure to record it is reported but not undone
+		logger.LogError("Failed to record replay of error " + flora.ObjectID.Hex() + ": " + updateErr.Error())
+	}
+
+	return ReplayResult{ID: flora.ObjectID.Hex(), Command: attempt.Command, Status: attempt.Status, Error: attempt.Error}, nil
+}
+
+// replaySubmissionID names one replay attempt of a dumped failure
+func replaySubmissionID(id primitive.ObjectID, at time.Time) string {
+	return replaySubmissionPrefix + id.Hex() + ":" + strconv.FormatInt(at.UnixNano(), 10)
+}
+
+// IsReplaySubmission reports whether the submission ID was given to a replayed command
+func IsReplaySubmission(submissionID string) bool {
+	return strings.HasPrefix(submissionID, replaySubmissionPrefix)
+}
+
+// RecordReplayOutcome sets the status reported by flora_upstream_service on the replay attempt
+// the submission ID names, and on last_replay while it is still the latest attempt
+func RecordReplayOutcome(ctx context.Context, collection *mongo.Collection, submissionID string, status string, detail string) error {
+	hex, _, _ := strings.Cut(strings.TrimPrefix(submissionID, replaySubmissionPrefix), ":")
+	objectID, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		return errors.New("Invalid replay submission ID " + submissionID)
+	}
+
+	_, err = collection.UpdateOne(ctx,
+		bson.M{"_id": objectID, "replays.submission_id": submissionID},
+		bson.M{"$set": bson.M{"replays.$.status": status, "replays.$.error": detail}},
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = collection.UpdateOne(ctx,
+		bson.M{"_id": objectID, "last_replay.submission_id": submissionID},
+		bson.M{"$set": bson.M{"last_replay.status": status, "last_replay.error": detail}},
+	)
+	return err
+}
+
+// replayCommand returns the flora_upstream_service command that failed with the dumped event
+func replayCommand(flora dumpedFlora) (string, error) {
+	switch flora.Pattern {
+	case models.EventFloraCreated:
+		return "add_flora", nil
+	case models.EventFloraUpdated:
+		if flora.FloraID == "" {
+			return "", errors.New("The " + models.EventFloraUpdated + " failure does not record which flora was updated")
+		}
+		return "update_flora", nil
+	}
+	return "", errors.New("Only " + models.EventFloraCreated + " and " + models.EventFloraUpdated + " failures can be replayed, not " + flora.Pattern)
+}
+
+// replayPayload rebuilds the RabbitMqPayload flora_upstream_service expects from the dumped document
+func replayPayload(flora dumpedFlora) map[string]interface{} {
+	payload := map[string]interface{}{
+		"CommonName":     flora.CommonName,
+		"ScientificName": flora.ScientificName,
+		"UserId":         flora.UserID,
+		"Type":           flora.Type,
+		"Image":          flora.Image,
+		"Description":    flora.Description,
+		"Origin":         flora.Origin,
+		"OtherDetails":   flora.OtherDetails,
+	}
+	if flora.Pattern == models.EventFloraUpdated {
+		payload["ID"] = flora.FloraID
+	}
+	return payload
+}
@@ -0,0 +1,306 @@
+// Copyright 2025 Naveen R
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package errordump
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"project_chimera/error_handle_service/pkg/common"
+	logger "project_chimera/error_handle_service/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Sources of error documents, each one is an error_dump collection
+const (
+	SourceFlora = "flora" // chimera_flora.error_dump, flora events and gene bank error events
+	SourceUser  = "user"  // chimera_user.error_dump, user service events
+)
+
+const (
+	defaultLimit = 50
+	maxLimit     = 200
+
+	// defaultStatsWindow is the time range of the hourly counts when no start is given
+	defaultStatsWindow = 24 * time.Hour
+)
+
+// ErrorFilter narrows down the error documents, zero values match everything
+type ErrorFilter struct {
+	Source       string
+	Pattern      string
+	Code         int
+	ResponseType string
+	UserID       string
+	Kind         string // Kind of the parsed error
+	Field        string // Field named by the parsed error
+	From         time.Time
+	To           time.Time
+}
+
+// ErrorPage is a page of error documents, NextCursor is empty on the last page
+type ErrorPage struct {
+	Items      []bson.M `json:"items"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+}
+
+// HourlyCount is the number of errors of a pattern within an hour
+type HourlyCount struct {
+	Pattern string    `bson:"pattern" json:"pattern"`
+	Hour    time.Time `bson:"hour" json:"hour"`
+	Count   int       `bson:"count" json:"count"`
+}
+
+// ErrorDumpService defines the queries over the error dump collections
+type ErrorDumpService interface {
+	ListErrors(ctx context.Context, filter ErrorFilter, cursor string, limit int) (ErrorPage, error)
+	GetError(ctx context.Context, id string) (bson.M, error)
+	HourlyCounts(ctx context.Context, filter ErrorFilter) ([]HourlyCount, error)
+}
+
+// errorDumpService is the concrete implementation of ErrorDumpService
+type errorDumpService struct {
+	sources map[string]*mongo.Collection
+}
+
+// NewErrorDumpService creates a new ErrorDumpService over the error dump collection of every source
+func NewErrorDumpService(sources map[string]*mongo.Collection) ErrorDumpService {
+	return &errorDumpService{sources: sources}
+}
+
+// ListErrors returns the newest errors matching the filter, continuing after cursor if set
+func (s *errorDumpService) ListErrors(ctx context.Context, filter ErrorFilter, cursor string, limit int) (ErrorPage, error) {
+	collection, err := s.collection(filter.Source)
+	if err != nil {
+		return ErrorPage{}, err
+	}
+
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+
+	query := buildQuery(filter)
+	if cursor != "" {
+		after, err := primitive.ObjectIDFromHex(cursor)
+		if err != nil {
+			return ErrorPage{}, &fiber.Error{Code: fiber.StatusBadRequest, Message: "Invalid cursor"}
+		}
+		query = append(query, bson.E{Key: "_id", Value: bson.M{"$lt": after}})
+	}
+
+	// ObjectIDs grow with the insertion time, so sorting by _id lists the newest first and keeps the cursor stable
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(int64(limit) + 1)
+	results, err := collection.Find(ctx, query, opts)
+	if err != nil {
+		return ErrorPage{}, err
+	}
+
+	page := ErrorPage{Items: []bson.M{}}
+	if err := results.All(ctx, &page.Items); err != nil {
+		return ErrorPage{}, err
+	}
+
+	// One more document than the limit was read to tell whether another page follows
+	if len(page.Items) > limit {
+		page.Items = page.Items[:limit]
+		if id, ok := page.Items[limit-1]["_id"].(primitive.ObjectID); ok {
+			page.NextCursor = id.Hex()
+		}
+	}
+	for _, item := range page.Items {
+		item["source"] = sourceName(filter.Source)
+		withParsedError(item)
+	}
+
+	return page, nil
+}
+
+// GetError looks up an error document by its ID in every source
+func (s *errorDumpService) GetError(ctx context.Context, id string) (bson.M, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, &fiber.Error{Code: fiber.StatusBadRequest, Message: "Invalid error ID " + id}
+	}
+
+	for _, source := range []string{SourceFlora, SourceUser} {
+		collection, ok := s.sources[source]
+		if !ok {
+			continue
+		}
+
+		var document bson.M
+		err := collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&document)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		document["source"] = source
+		withParsedError(document)
+		return document, nil
+	}
+
+	return nil, &fiber.Error{Code: fiber.StatusNotFound, Message: "Error " + id + " not found"}
+}
+
+// HourlyCounts counts the errors matching the filter per pattern and hour, the last day by default
+func (s *errorDumpService) HourlyCounts(ctx context.Context, filter ErrorFilter) ([]HourlyCount, error) {
+	collection, err := s.collection(filter.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.From.IsZero() {
+		filter.From = time.Now().UTC().Add(-defaultStatsWindow)
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: buildQuery(filter)}},
+		{{Key: "$addFields", Value: bson.M{"at": bson.M{"$ifNull": bson.A{"$timestamp", "$created_at"}}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"pattern": "$pattern",
+				// $dateTrunc would need MongoDB 5, the date parts work on every supported version
+				"hour": bson.M{"$dateFromParts": bson.M{
+					"year":  bson.M{"$year": "$at"},
+					"month": bson.M{"$month": "$at"},
+					"day":   bson.M{"$dayOfMonth": "$at"},
+					"hour":  bson.M{"$hour": "$at"},
+				}},
+			},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$project", Value: bson.M{"_id": 0, "pattern": "$_id.pattern", "hour": "$_id.hour", "count": 1}}},
+		{{Key: "$sort", Value: bson.D{{Key: "hour", Value: 1}, {Key: "pattern", Value: 1}}}},
+	}
+
+	results, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := []HourlyCount{}
+	if err := results.All(ctx, &counts); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// EnsureIndexes creates the indexes the filters and the hourly counts rely on, failures are only logged
+func EnsureIndexes(ctx context.Context, sources map[string]*mongo.Collection) {
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "pattern", Value: 1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "timestamp", Value: -1}}},
+		{Keys: bson.D{{Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "parsed_error.kind", Value: 1}, {Key: "_id", Value: -1}}},
+	}
+
+	for source, collection := range sources {
+		if _, err := collection.Indexes().CreateMany(ctx, indexes); err != nil {
+			logger.LogError("Failed to create indexes on the " + source + " error dump: " + err.Error())
+		}
+	}
+}
+
+func (s *errorDumpService) collection(source string) (*mongo.Collection, error) {
+	collection, ok := s.sources[sourceName(source)]
+	if !ok {
+		return nil, &fiber.Error{Code: fiber.StatusBadRequest, Message: "Unknown source " + source + ", expected " + SourceFlora + " or " + SourceUser}
+	}
+	return collection, nil
+}
+
+func sourceName(source string) string {
+	if source == "" {
+		return SourceFlora
+	}
+	return source
+}
+
+// withParsedError adds the parsed error to documents stored before it was saved along with them.
+// Those documents are not found by the kind and field filters, which query the stored one.
+func withParsedError(document bson.M) {
+	if _, ok := document["parsed_error"]; ok {
+		return
+	}
+	if message, ok := document["error"].(string); ok {
+		document["parsed_error"] = common.ParseError(message)
+		return
+	}
+	document["parsed_error"] = common.ParseErrorData(document["data"])
+}
+
+// buildQuery translates the filter into a MongoDB query. The sources do not share a schema: flora
+// events store response_type, user_id and created_at, while gene bank error events and user events
+// store the time in timestamp and gene bank error events keep the user in request.user_id.
+func buildQuery(filter ErrorFilter) bson.D {
+	query := bson.D{}
+
+	if filter.Pattern != "" {
+		query = append(query, bson.E{Key: "pattern", Value: filter.Pattern})
+	}
+	if filter.Code != 0 {
+		query = append(query, bson.E{Key: "code", Value: filter.Code})
+	}
+	if filter.Kind != "" {
+		query = append(query, bson.E{Key: "parsed_error.kind", Value: filter.Kind})
+	}
+	if filter.Field != "" {
+		query = append(query, bson.E{Key: "parsed_error.fields", Value: filter.Field})
+	}
+
+	var alternatives bson.A
+	if filter.ResponseType != "" {
+		alternatives = append(alternatives, bson.M{"$or": bson.A{
+			bson.M{"response_type": filter.ResponseType},
+			bson.M{"type": filter.ResponseType},
+		}})
+	}
+	if filter.UserID != "" {
+		alternatives = append(alternatives, bson.M{"$or": bson.A{
+			bson.M{"user_id": filter.UserID},
+			bson.M{"request.user_id": filter.UserID},
+		}})
+	}
+	if !filter.From.IsZero() || !filter.To.IsZero() {
+		timeRange := bson.M{}
+		if !filter.From.IsZero() {
+			timeRange["$gte"] = filter.From
+		}
+		if !filter.To.IsZero() {
+			timeRange["$lt"] = filter.To
+		}
+		alternatives = append(alternatives, bson.M{"$or": bson.A{
+			bson.M{"timestamp": timeRange},
+			bson.M{"created_at": timeRange},
+		}})
+	}
+	if len(alternatives) > 0 {
+		query = append(query, bson.E{Key: "$and", Value: alternatives})
+	}
+
+	return query
+}
@@ -0,0 +1,99 @@
+// Copyright 2025 Naveen R
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package flora
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	logger "project_chimera/error_handle_service/pkg/logger"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// correlationPrefix marks the IDs given to failed flora events sent without a submission ID
+const correlationPrefix = "autofix:"
+
+// AttemptCounter counts the auto-fixes of a submission republished to flora_upstream_service
+type AttemptCounter interface {
+	// Count returns how many fixes were republished for the key
+	Count(ctx context.Context, key string) (int, error)
+	// Add records a fix republished for the key
+	Add(ctx context.Context, key string) error
+}
+
+// mongoAttemptCounter keeps the counts in MongoDB, so they survive restarts
+type mongoAttemptCounter struct {
+	collection *mongo.Collection
+}
+
+// NewAttemptCounter creates an AttemptCounter on collection, counts expire once idle for ttl
+func NewAttemptCounter(ctx context.Context, collection *mongo.Collection, ttl time.Duration) AttemptCounter {
+	index := mongo.IndexModel{
+		Keys:    bson.D{{Key: "updated_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32(ttl.Seconds())),
+	}
+	if _, err := collection.Indexes().CreateOne(ctx, index); err != nil {
+		logger.LogError("Failed to create the expiry index of the auto-fix attempts: " + err.Error())
+	}
+
+	return &mongoAttemptCounter{collection: collection}
+}
+
+func (c *mongoAttemptCounter) Count(ctx context.Context, key string) (int, error) {
+	var counter struct {
+		Attempts int `bson:"attempts"`
+	}
+
+	err := c.collection.FindOne(ctx, bson.M{"_id": key}).Decode(&counter)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return counter.Attempts, nil
+}
+
+func (c *mongoAttemptCounter) Add(ctx context.Context, key string) error {
+	_, err := c.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": key},
+		bson.M{"$inc": bson.M{"attempts": 1}, "$set": bson.M{"updated_at": time.Now().UTC()}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// AttemptKey identifies the submission of a failed flora by its submission or correlation ID
+func AttemptKey(submissionID string) string {
+	return "submission:" + submissionID
+}
+
+// NewCorrelationID names a failed flora event sent without a submission ID. It is republished
+// with the fix as submission ID, so the event comes back with it if the fix fails again.
+func NewCorrelationID() string {
+	return correlationPrefix + primitive.NewObjectID().Hex()
+}
+
+// IsCorrelationID reports whether the submission ID was created by NewCorrelationID
+func IsCorrelationID(submissionID string) bool {
+	return strings.HasPrefix(submissionID, correlationPrefix)
+}
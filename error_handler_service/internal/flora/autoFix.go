@@ -1,27 +1,89 @@
+// Copyright 2025 Naveen R
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
 package flora
 
 import (
 	"errors"
-	"log"
+	"fmt"
+	"os"
 	"project_chimera/error_handle_service/pkg/common"
 	logger "project_chimera/error_handle_service/pkg/logger"
 	"project_chimera/error_handle_service/pkg/models"
+	"strings"
+)
+
+var (
+	// ErrNoRule is returned when no rule can fix the failure
+	ErrNoRule = errors.New("no auto-fix rule applies")
+	// ErrMaxAttempts is returned once a submission was auto-fixed MaxAttempts times and still failed
+	ErrMaxAttempts = errors.New("auto-fix attempts exhausted")
 )
 
-func AutoFixFlora(floraResp models.FloraResponse) (models.FloraResponse, error) {
-	fixedFloraData := floraResp
-
-	eventType := floraResp.Pattern
-	log.Print(eventType)
-	switch eventType {
-	case "flora.created":
-		field := common.ExtractFieldNameFromError(floraResp.Data.Data.Error)
-		log.Print(field)
-		if field == "type" {
-			logger.LogInfo("Fixing type field in flora.created event")
-			fixedFloraData.Data.Data.Values.Type = "offline"
-			return fixedFloraData, nil
+// AutoFixer fixes the values of failed flora events so they can be resubmitted
+type AutoFixer interface {
+	// AutoFixFlora fixes a failure, attempt counts the auto-fixes of the same submission starting at 1
+	AutoFixFlora(floraResp models.FloraResponse, attempt int) (models.FloraResponse, error)
+}
+
+// ruleEngine is the AutoFixer applying a RuleSet
+type ruleEngine struct {
+	ruleSet RuleSet
+}
+
+// NewAutoFixer creates an AutoFixer from a rule set, rejecting invalid rules
+func NewAutoFixer(ruleSet RuleSet) (AutoFixer, error) {
+	if err := ruleSet.compile(); err != nil {
+		return nil, err
+	}
+	return &ruleEngine{ruleSet: ruleSet}, nil
+}
+
+// LoadAutoFixer creates an AutoFixer from a rules file, using DefaultRuleSet when the file does not exist
+func LoadAutoFixer(path string) (AutoFixer, error) {
+	ruleSet, err := LoadRuleSet(path)
+	if errors.Is(err, os.ErrNotExist) {
+		logger.LogInfo("No auto-fix rules at " + path + ", using the default rules")
+		ruleSet = DefaultRuleSet()
+	} else if err != nil {
+		return nil, err
+	}
+	return NewAutoFixer(ruleSet)
+}
+
+// AutoFixFlora applies every rule matching the failure in order. It fails if none changed a value,
+// since resubmitting the same values would only fail again.
+func (e *ruleEngine) AutoFixFlora(floraResp models.FloraResponse, attempt int) (models.FloraResponse, error) {
+	if attempt > e.ruleSet.MaxAttempts {
+		return floraResp, fmt.Errorf("%w after %d attempts", ErrMaxAttempts, e.ruleSet.MaxAttempts)
+	}
+
+	message := floraResp.Data.Data.Error
+	parsed := common.ParseError(message)
+
+	fixed := floraResp
+	var applied []string
+	for _, rule := range e.ruleSet.Rules {
+		if rule.matches(floraResp.Pattern, parsed, message) && rule.apply(&fixed.Data.Data.Values) {
+			applied = append(applied, rule.Name)
 		}
 	}
-	return fixedFloraData, errors.New("error")
+
+	if len(applied) == 0 {
+		return floraResp, fmt.Errorf("%w to %s error on %v", ErrNoRule, parsed.Kind, parsed.Fields)
+	}
+
+	logger.LogInfo(fmt.Sprintf("Fixed %s event with rules %s (attempt %d/%d)", floraResp.Pattern, strings.Join(applied, ", "), attempt, e.ruleSet.MaxAttempts))
+	return fixed, nil
 }
@@ -0,0 +1,157 @@
+// Copyright 2025 Naveen R
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package flora
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"project_chimera/error_handle_service/pkg/models"
+	"strings"
+	"testing"
+)
+
+// fixture is a failed flora event together with the values the rules are expected to produce
+type fixture struct {
+	Name    string            `json:"name"`
+	Pattern string            `json:"pattern"`
+	Error   string            `json:"error"`
+	Attempt int               `json:"attempt"` // 1 when not set
+	Values  map[string]string `json:"values"`  // Values of the failed event by field
+	Want    map[string]string `json:"want"`    // Expected values after the fix, empty when no fix is expected
+}
+
+func loadFixtures(t *testing.T, path string) []fixture {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fixtures []fixture
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		t.Fatalf("failed to parse %s: %v", path, err)
+	}
+	return fixtures
+}
+
+// failedFlora builds the failed event of a fixture
+func failedFlora(t *testing.T, f fixture) models.FloraResponse {
+	t.Helper()
+
+	var floraResp models.FloraResponse
+	floraResp.Pattern = f.Pattern
+	floraResp.Data.Data.Error = f.Error
+	for field, value := range f.Values {
+		accessor, ok := floraFields[field]
+		if !ok {
+			t.Fatalf("unknown field %s", field)
+		}
+		accessor.set(&floraResp.Data.Data.Values, value)
+	}
+	return floraResp
+}
+
+// TestAutoFixFixtures runs the shipped rules against the fixtures
+func TestAutoFixFixtures(t *testing.T) {
+	ruleSet, err := LoadRuleSet("../../config/autofix_rules.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixer, err := NewAutoFixer(ruleSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range loadFixtures(t, "testdata/autofix_fixtures.json") {
+		t.Run(f.Name, func(t *testing.T) {
+			attempt := f.Attempt
+			if attempt <= 0 {
+				attempt = 1
+			}
+
+			fixed, err := fixer.AutoFixFlora(failedFlora(t, f), attempt)
+			if len(f.Want) == 0 {
+				if err == nil {
+					t.Fatalf("expected no fix, got %+v", fixed.Data.Data.Values)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected a fix, got %v", err)
+			}
+
+			for field, want := range f.Want {
+				accessor, ok := floraFields[field]
+				if !ok {
+					t.Fatalf("unknown field %s", field)
+				}
+				if got := accessor.get(&fixed.Data.Data.Values); got != want {
+					t.Errorf("%s is %q, want %q", field, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewAutoFixerRejectsInvalidRules(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+		want string
+	}{
+		{"no field", Rule{Transform: Transform{Kind: TransformTrim}}, "transform.field is required"},
+		{"unknown field", Rule{Match: Match{Field: "color"}, Transform: Transform{Kind: TransformTrim}}, "unknown field color"},
+		{"unknown transform", Rule{Match: Match{Field: "type"}, Transform: Transform{Kind: "upper"}}, "unknown transform"},
+		{"default without value", Rule{Match: Match{Field: "type"}, Transform: Transform{Kind: TransformDefault}}, "needs a default value"},
+		{"enum without values", Rule{Match: Match{Field: "type"}, Transform: Transform{Kind: TransformEnum}}, "enum needs values"},
+		{"enum default not allowed", Rule{Match: Match{Field: "type"}, Transform: Transform{Kind: TransformEnum, Values: []string{"public"}, Default: "draft"}}, "is not one of the values"},
+		{"lookup without table", Rule{Match: Match{Field: "type"}, Transform: Transform{Kind: TransformLookup}}, "lookup needs a table"},
+		{"invalid error expression", Rule{Match: Match{Field: "type", Error: "("}, Transform: Transform{Kind: TransformTrim}}, "invalid error expression"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAutoFixer(RuleSet{Rules: []Rule{tt.rule}})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("NewAutoFixer() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAutoFixFloraStopsAfterMaxAttempts(t *testing.T) {
+	ruleSet := DefaultRuleSet()
+	ruleSet.MaxAttempts = 2
+	fixer, err := NewAutoFixer(ruleSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failed := failedFlora(t, fixture{
+		Pattern: models.EventFloraCreated,
+		Error:   "Invalid value for argument `type`. Expected PostType.",
+		Values:  map[string]string{"type": "offline"},
+	})
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		if _, err := fixer.AutoFixFlora(failed, attempt); err != nil {
+			t.Fatalf("attempt %d: %v", attempt, err)
+		}
+	}
+	if _, err := fixer.AutoFixFlora(failed, 3); !errors.Is(err, ErrMaxAttempts) {
+		t.Errorf("attempt 3: error = %v, want ErrMaxAttempts", err)
+	}
+}
@@ -0,0 +1,263 @@
+// Copyright 2025 Naveen R
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package flora
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"project_chimera/error_handle_service/pkg/common"
+	"project_chimera/error_handle_service/pkg/models"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Transform kinds a rule can apply to the failing field
+const (
+	TransformDefault = "default" // Replace the value with Default
+	TransformTrim    = "trim"    // Strip surrounding whitespace
+	TransformEnum    = "enum"    // Coerce the value to one of Values, through Table and then Default
+	TransformLookup  = "lookup"  // Replace the value through Table, falling back to Default
+)
+
+const defaultMaxAttempts = 3
+
+// RuleSet is the content of an auto-fix rules file
+type RuleSet struct {
+	MaxAttempts int    `json:"max_attempts" yaml:"max_attempts"` // Auto-fixes of the same submission before it is dumped as failed
+	Rules       []Rule `json:"rules" yaml:"rules"`
+}
+
+// Rule fixes a field of a failed flora event when its match applies
+type Rule struct {
+	Name      string    `json:"name" yaml:"name"`
+	Match     Match     `json:"match" yaml:"match"`
+	Transform Transform `json:"transform" yaml:"transform"`
+
+	errorPattern *regexp.Regexp
+}
+
+// Match selects the failures a rule applies to, empty conditions match everything
+type Match struct {
+	Pattern string `json:"pattern" yaml:"pattern"` // Event pattern, e.g. flora.created
+	Kind    string `json:"kind" yaml:"kind"`       // Kind of the parsed error, e.g. invalid_value
+	Field   string `json:"field" yaml:"field"`     // Field named by the parsed error
+	Error   string `json:"error" yaml:"error"`     // Regular expression the error message has to match
+}
+
+// Transform changes the value of a field
+type Transform struct {
+	Kind    string            `json:"kind" yaml:"kind"`
+	Field   string            `json:"field" yaml:"field"`     // Field to change, the matched field by default
+	Default string            `json:"default" yaml:"default"` // Value used by default, and by enum and lookup when nothing else fits
+	Values  []string          `json:"values" yaml:"values"`   // Allowed values of enum
+	Table   map[string]string `json:"table" yaml:"table"`     // Replacements by value for enum and lookup, keys are case-insensitive
+}
+
+// floraFields gives access to the fields of FloraData rules may change, by their JSON name
+var floraFields = map[string]struct {
+	get func(*models.FloraData) string
+	set func(*models.FloraData, string)
+}{
+	"common_name":     {func(f *models.FloraData) string { return f.CommonName }, func(f *models.FloraData, v string) { f.CommonName = v }},
+	"scientific_name": {func(f *models.FloraData) string { return f.ScientificName }, func(f *models.FloraData, v string) { f.ScientificName = v }},
+	"user_id":         {func(f *models.FloraData) string { return f.UserID }, func(f *models.FloraData, v string) { f.UserID = v }},
+	"type":            {func(f *models.FloraData) string { return f.Type }, func(f *models.FloraData, v string) { f.Type = v }},
+	"image":           {func(f *models.FloraData) string { return f.Image }, func(f *models.FloraData, v string) { f.Image = v }},
+	"description":     {func(f *models.FloraData) string { return f.Description }, func(f *models.FloraData, v string) { f.Description = v }},
+	"origin":          {func(f *models.FloraData) string { return f.Origin }, func(f *models.FloraData, v string) { f.Origin = v }},
+}
+
+// DefaultRuleSet is used when no rules file exists. PostType of flora_upstream_service only knows
+// public and private, unknown types become private so that nothing is published by accident.
+func DefaultRuleSet() RuleSet {
+	return RuleSet{
+		MaxAttempts: defaultMaxAttempts,
+		Rules: []Rule{
+			{
+				Name:  "coerce-post-type",
+				Match: Match{Kind: common.KindInvalidValue, Field: "type"},
+				Transform: Transform{
+					Kind:    TransformEnum,
+					Values:  []string{"public", "private"},
+					Table:   map[string]string{"offline": "private", "online": "public"},
+					Default: "private",
+				},
+			},
+			{Name: "trim-common-name", Match: Match{Field: "common_name"}, Transform: Transform{Kind: TransformTrim}},
+			{Name: "trim-scientific-name", Match: Match{Field: "scientific_name"}, Transform: Transform{Kind: TransformTrim}},
+		},
+	}
+}
+
+// LoadRuleSet reads a rules file, YAML unless its extension is .json
+func LoadRuleSet(path string) (RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return RuleSet{}, err
+	}
+
+	var ruleSet RuleSet
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, &ruleSet)
+	} else {
+		err = yaml.Unmarshal(data, &ruleSet)
+	}
+	if err != nil {
+		return RuleSet{}, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+
+	return ruleSet, nil
+}
+
+// compile validates the rules and compiles their error expressions
+func (r *RuleSet) compile() error {
+	if r.MaxAttempts <= 0 {
+		r.MaxAttempts = defaultMaxAttempts
+	}
+
+	for i := range r.Rules {
+		rule := &r.Rules[i]
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule-%d", i+1)
+		}
+
+		field := rule.field()
+		if field == "" {
+			return fmt.Errorf("rule %s: transform.field is required when match.field is empty", rule.Name)
+		}
+		if _, ok := floraFields[field]; !ok {
+			return fmt.Errorf("rule %s: unknown field %s", rule.Name, field)
+		}
+
+		switch rule.Transform.Kind {
+		case TransformTrim:
+		case TransformDefault:
+			if rule.Transform.Default == "" {
+				return fmt.Errorf("rule %s: default needs a default value", rule.Name)
+			}
+		case TransformEnum:
+			if len(rule.Transform.Values) == 0 {
+				return fmt.Errorf("rule %s: enum needs values", rule.Name)
+			}
+			if rule.Transform.Default != "" && !contains(rule.Transform.Values, rule.Transform.Default) {
+				return fmt.Errorf("rule %s: default %s is not one of the values", rule.Name, rule.Transform.Default)
+			}
+		case TransformLookup:
+			if len(rule.Transform.Table) == 0 {
+				return fmt.Errorf("rule %s: lookup needs a table", rule.Name)
+			}
+		default:
+			return fmt.Errorf("rule %s: unknown transform %q", rule.Name, rule.Transform.Kind)
+		}
+
+		if rule.Match.Error != "" {
+			pattern, err := regexp.Compile(rule.Match.Error)
+			if err != nil {
+				return fmt.Errorf("rule %s: invalid error expression: %v", rule.Name, err)
+			}
+			rule.errorPattern = pattern
+		}
+	}
+
+	return nil
+}
+
+// field returns the field the rule changes
+func (r Rule) field() string {
+	if r.Transform.Field != "" {
+		return r.Transform.Field
+	}
+	return r.Match.Field
+}
+
+// matches reports whether the rule applies to a failure of pattern with the error message
+func (r Rule) matches(pattern string, parsed common.ParsedError, message string) bool {
+	if r.Match.Pattern != "" && r.Match.Pattern != pattern {
+		return false
+	}
+	if r.Match.Kind != "" && r.Match.Kind != parsed.Kind {
+		return false
+	}
+	if r.Match.Field != "" && !contains(parsed.Fields, r.Match.Field) {
+		return false
+	}
+	if r.errorPattern != nil && !r.errorPattern.MatchString(message) {
+		return false
+	}
+	return true
+}
+
+// apply transforms the field of the rule, reporting false when it has no better value
+func (r Rule) apply(data *models.FloraData) bool {
+	accessor := floraFields[r.field()]
+	current := accessor.get(data)
+
+	fixed, ok := r.Transform.value(current)
+	if !ok || fixed == current {
+		return false
+	}
+
+	accessor.set(data, fixed)
+	return true
+}
+
+// value returns the transformed value, or false if the transform has none
+func (t Transform) value(current string) (string, bool) {
+	switch t.Kind {
+	case TransformDefault:
+		return t.Default, true
+	case TransformTrim:
+		return strings.TrimSpace(current), true
+	case TransformEnum:
+		normalized := strings.TrimSpace(current)
+		for _, allowed := range t.Values {
+			if strings.EqualFold(allowed, normalized) {
+				return allowed, true
+			}
+		}
+		if replacement, ok := t.lookup(normalized); ok && contains(t.Values, replacement) {
+			return replacement, true
+		}
+		return t.Default, t.Default != ""
+	case TransformLookup:
+		if replacement, ok := t.lookup(strings.TrimSpace(current)); ok {
+			return replacement, true
+		}
+		return t.Default, t.Default != ""
+	}
+	return "", false
+}
+
+func (t Transform) lookup(value string) (string, bool) {
+	for key, replacement := range t.Table {
+		if strings.EqualFold(key, value) {
+			return replacement, true
+		}
+	}
+	return "", false
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
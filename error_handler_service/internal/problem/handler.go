@@ -0,0 +1,82 @@
+//	Copyright 2025 Naveen R
+//
+//		Licensed under the Apache License, Version 2.0 (the "License");
+//		you may not use this file except in compliance with the License.
+//		You may obtain a copy of the License at
+//
+//		http://www.apache.org/licenses/LICENSE-2.0
+//
+//		Unless required by applicable law or agreed to in writing, software
+//		distributed under the License is distributed on an "AS IS" BASIS,
+//		WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//		See the License for the specific language governing permissions and
+//		limitations under the License.
+
+package problem
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"project_chimera/error_handle_service/pkg/common"
+	customlogger "project_chimera/error_handle_service/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/google/uuid"
+)
+
+// ContentType is the media type of RFC 7807 error bodies
+const ContentType = "application/problem+json"
+
+// TypeDefault is used for problems that are fully described by their HTTP status
+const TypeDefault = "about:blank"
+
+// TraceIDHeader carries the trace ID between the gateway, this service and the client
+const TraceIDHeader = fiber.HeaderXRequestID
+
+// RequestID returns the middleware assigning every request a trace ID, reusing the one sent by the gateway
+func RequestID() fiber.Handler {
+	return requestid.New(requestid.Config{
+		Header:    TraceIDHeader,
+		Generator: func() string { return uuid.New().String() },
+	})
+}
+
+// TraceID returns the trace ID of the request
+func TraceID(c *fiber.Ctx) string {
+	if id, ok := c.Locals(requestid.ConfigDefault.ContextKey).(string); ok && id != "" {
+		return id
+	}
+	if id := c.Get(TraceIDHeader); id != "" {
+		return id
+	}
+	return c.GetRespHeader(TraceIDHeader)
+}
+
+// ErrorHandler renders every error returned by a handler as application/problem+json
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	problem := common.Problem{
+		Type:     TypeDefault,
+		Status:   fiber.StatusInternalServerError,
+		Instance: c.Path(),
+		TraceID:  TraceID(c),
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		problem.Status = fiberErr.Code
+		problem.Detail = fiberErr.Message
+	} else {
+		// Unexpected errors may carry internal details, so they are only logged
+		problem.Detail = "An unexpected error occurred"
+	}
+	problem.Title = http.StatusText(problem.Status)
+
+	if problem.Status >= fiber.StatusInternalServerError {
+		customlogger.LogError(fmt.Sprintf("[%s] %s %s failed with %d:\n%s", problem.TraceID, c.Method(), c.Path(), problem.Status, err.Error()))
+	}
+
+	c.Set(TraceIDHeader, problem.TraceID)
+	return c.Status(problem.Status).JSON(problem, ContentType)
+}
@@ -18,8 +18,15 @@ type SuccessResponse struct {
 	Status string `json:"status"`
 }
 
-type ErrorResponse struct {
-	Error string `json:"error"`
+// Problem is an RFC 7807 application/problem+json error body
+type Problem struct {
+	Type     string `json:"type"`               // URI reference identifying the problem type
+	Title    string `json:"title"`              // Short summary of the problem type
+	Status   int    `json:"status"`             // HTTP status code
+	Detail   string `json:"detail,omitempty"`   // Explanation specific to this occurrence
+	Instance string `json:"instance,omitempty"` // Request path the problem occurred on
+	TraceID  string `json:"trace_id"`           // Request ID, also sent as the X-Request-ID header
+	RPCCode  int    `json:"rpc_code,omitempty"` // Code returned by a downstream RPC call, if any
 }
 
 // Define the top-level structure for the message
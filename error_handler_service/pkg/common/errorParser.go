@@ -0,0 +1,265 @@
+// Copyright 2025 Naveen R
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package common
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Kinds of parsed errors
+const (
+	KindUnknown      = "unknown"       // The error was not recognized
+	KindInvalidValue = "invalid_value" // A field has a value of the wrong type or outside its enum
+	KindMissingField = "missing_field" // A required field is missing or null
+	KindUnknownField = "unknown_field" // A field does not exist on the model
+	KindUnique       = "unique"        // A unique constraint was violated
+	KindForeignKey   = "foreign_key"   // A foreign key constraint was violated
+	KindValidation   = "validation"    // Model validation of user_service failed
+)
+
+// ParsedError is the structured form of an error reported by another service
+type ParsedError struct {
+	Kind       string   `bson:"kind" json:"kind"`
+	Fields     []string `bson:"fields,omitempty" json:"fields,omitempty"`         // Fields the error is about
+	Constraint string   `bson:"constraint,omitempty" json:"constraint,omitempty"` // Violated database constraint
+	Expected   string   `bson:"expected,omitempty" json:"expected,omitempty"`     // Expected type or values
+	Got        string   `bson:"got,omitempty" json:"got,omitempty"`               // Value or type that was provided
+}
+
+// Field returns the first field of the error, or "" if it names none
+func (p ParsedError) Field() string {
+	if len(p.Fields) == 0 {
+		return ""
+	}
+	return p.Fields[0]
+}
+
+var (
+	// Prisma client errors of flora_upstream_service
+	prismaInvalidValue  = regexp.MustCompile("(?i)Invalid value for argument [`\"']?(\\w+)[`\"']?(?:\\. Expected ([\\w\\[\\]]+))?")
+	prismaInvalidTyped  = regexp.MustCompile("Argument [`\"']?(\\w+)[`\"']?: Invalid value provided\\. Expected ([\\w\\[\\], ]+?), provided (\\w+)")
+	prismaGotInvalid    = regexp.MustCompile("Argument (\\w+): Got invalid value '([^']*)' on [\\w.]+\\. Provided (\\w+), expected (\\w+)")
+	prismaMissing       = regexp.MustCompile("Argument [`\"']?(\\w+)[`\"']? is missing")
+	prismaUnknown       = regexp.MustCompile("Unknown arg(?:ument)? [`\"']?(\\w+)[`\"']?")
+	prismaUniqueFields  = regexp.MustCompile("Unique constraint failed on the fields: \\(([^)]*)\\)")
+	prismaUniqueName    = regexp.MustCompile("Unique constraint failed on the constraint: `([^`]+)`")
+	prismaForeignKey    = regexp.MustCompile("Foreign key constraint (?:failed|violated)[^`]*`([^`\\s]+)")
+	prismaMarkedValue   = regexp.MustCompile(`^\s*"?(\w+)"?\s*:\s*(.*?),?\s*$`)
+	backtickIdentifiers = regexp.MustCompile("`([^`]+)`")
+
+	// PostgreSQL errors as wrapped by SQLAlchemy in flora_downstream_service
+	sqlUnique     = regexp.MustCompile(`duplicate key value violates unique constraint "([^"]+)"`)
+	sqlForeignKey = regexp.MustCompile(`violates foreign key constraint "([^"]+)"`)
+	sqlKeyDetail  = regexp.MustCompile(`Key \(([^)]*)\)=\((.*?)\)(?: is not present in table "([^"]+)")?`)
+	sqlNotNull    = regexp.MustCompile(`null value in column "([^"]+)"(?: of relation "[^"]+")? violates not-null constraint`)
+	sqlEnum       = regexp.MustCompile(`invalid input value for enum (\w+): "([^"]*)"`)
+	sqlTooLong    = regexp.MustCompile(`value too long for type ([\w ]+\(\d+\))`)
+)
+
+// ParseError turns the error message of another service into a ParsedError. It understands Prisma
+// validation and known request errors, PostgreSQL errors wrapped by SQLAlchemy and the model state
+// returned by user_service, and falls back to KindUnknown. Samples of every supported error with
+// the expected result are kept in testdata/error_corpus.json.
+func ParseError(message string) ParsedError {
+	message = unquote(strings.TrimSpace(message))
+
+	if parsed, ok := parseModelState(message); ok {
+		return parsed
+	}
+	if parsed, ok := parsePrisma(message); ok {
+		return parsed
+	}
+	if parsed, ok := parseSQL(message); ok {
+		return parsed
+	}
+	return ParsedError{Kind: KindUnknown}
+}
+
+// ParseErrorData parses the error data of an event, which is either a message or a JSON document
+func ParseErrorData(data interface{}) ParsedError {
+	switch value := data.(type) {
+	case nil:
+		return ParsedError{Kind: KindUnknown}
+	case string:
+		return ParseError(value)
+	default:
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return ParsedError{Kind: KindUnknown}
+		}
+		return ParseError(string(encoded))
+	}
+}
+
+// unquote undoes the JSON.stringify flora_upstream_service applies to error messages
+func unquote(message string) string {
+	if len(message) < 2 || message[0] != '"' || message[len(message)-1] != '"' {
+		return message
+	}
+	var unquoted string
+	if err := json.Unmarshal([]byte(message), &unquoted); err != nil {
+		return message
+	}
+	return strings.TrimSpace(unquoted)
+}
+
+func parsePrisma(message string) (ParsedError, bool) {
+	if match := prismaInvalidTyped.FindStringSubmatch(message); match != nil {
+		return ParsedError{Kind: KindInvalidValue, Fields: []string{match[1]}, Expected: match[2], Got: match[3]}, true
+	}
+	if match := prismaGotInvalid.FindStringSubmatch(message); match != nil {
+		return ParsedError{Kind: KindInvalidValue, Fields: []string{match[1]}, Expected: match[4], Got: match[2]}, true
+	}
+	if match := prismaInvalidValue.FindStringSubmatch(message); match != nil {
+		parsed := ParsedError{Kind: KindInvalidValue, Fields: []string{match[1]}, Expected: match[2]}
+		if field, value := markedArgument(message); field == match[1] {
+			parsed.Got = value
+		}
+		return parsed, true
+	}
+	if match := prismaMissing.FindStringSubmatch(message); match != nil {
+		return ParsedError{Kind: KindMissingField, Fields: []string{match[1]}}, true
+	}
+	if match := prismaUnknown.FindStringSubmatch(message); match != nil {
+		return ParsedError{Kind: KindUnknownField, Fields: []string{match[1]}}, true
+	}
+	if match := prismaUniqueFields.FindStringSubmatch(message); match != nil {
+		return ParsedError{Kind: KindUnique, Fields: identifiers(match[1])}, true
+	}
+	if match := prismaUniqueName.FindStringSubmatch(message); match != nil {
+		return ParsedError{Kind: KindUnique, Constraint: match[1]}, true
+	}
+	if match := prismaForeignKey.FindStringSubmatch(message); match != nil {
+		return ParsedError{Kind: KindForeignKey, Constraint: match[1]}, true
+	}
+
+	// Prisma marks the offending argument in the query it prints by underlining it with ~
+	if field, value := markedArgument(message); field != "" {
+		return ParsedError{Kind: KindInvalidValue, Fields: []string{field}, Got: value}, true
+	}
+	return ParsedError{}, false
+}
+
+// markedArgument returns the argument Prisma marked with ~ in the printed query, and its value.
+// The marker either underlines the argument on the next line or follows it on the same line.
+func markedArgument(message string) (string, string) {
+	lines := strings.Split(message, "\n")
+	for i, line := range lines {
+		if !strings.Contains(line, "~") {
+			continue
+		}
+
+		marked := strings.TrimRight(strings.TrimSpace(line), "~ ")
+		if marked == "" {
+			if i == 0 {
+				continue
+			}
+			marked = lines[i-1]
+		}
+		if match := prismaMarkedValue.FindStringSubmatch(marked); match != nil {
+			return match[1], strings.Trim(match[2], `"'`)
+		}
+	}
+	return "", ""
+}
+
+func parseSQL(message string) (ParsedError, bool) {
+	detail := sqlKeyDetail.FindStringSubmatch(message)
+
+	if match := sqlUnique.FindStringSubmatch(message); match != nil {
+		parsed := ParsedError{Kind: KindUnique, Constraint: match[1]}
+		if detail != nil {
+			parsed.Fields = columns(detail[1])
+			parsed.Got = detail[2]
+		}
+		return parsed, true
+	}
+	if match := sqlForeignKey.FindStringSubmatch(message); match != nil {
+		parsed := ParsedError{Kind: KindForeignKey, Constraint: match[1]}
+		if detail != nil {
+			parsed.Fields = columns(detail[1])
+			parsed.Got = detail[2]
+			parsed.Expected = detail[3]
+		}
+		return parsed, true
+	}
+	if match := sqlNotNull.FindStringSubmatch(message); match != nil {
+		return ParsedError{Kind: KindMissingField, Fields: []string{match[1]}, Constraint: "not-null"}, true
+	}
+	if match := sqlEnum.FindStringSubmatch(message); match != nil {
+		return ParsedError{Kind: KindInvalidValue, Expected: match[1], Got: match[2]}, true
+	}
+	if match := sqlTooLong.FindStringSubmatch(message); match != nil {
+		return ParsedError{Kind: KindInvalidValue, Expected: match[1]}, true
+	}
+	return ParsedError{}, false
+}
+
+// parseModelState reads the ModelState user_service returns, either as a ValidationProblemDetails
+// with an errors object or as the plain field to messages object of BadRequest(ModelState)
+func parseModelState(message string) (ParsedError, bool) {
+	if !strings.HasPrefix(message, "{") {
+		return ParsedError{}, false
+	}
+
+	var document map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(message), &document); err != nil {
+		return ParsedError{}, false
+	}
+	if errorsObject, ok := document["errors"]; ok {
+		document = nil
+		if err := json.Unmarshal(errorsObject, &document); err != nil {
+			return ParsedError{}, false
+		}
+	}
+
+	var fields []string
+	for field, raw := range document {
+		var messages []string
+		if err := json.Unmarshal(raw, &messages); err != nil {
+			return ParsedError{}, false
+		}
+		fields = append(fields, field)
+	}
+	if len(fields) == 0 {
+		return ParsedError{}, false
+	}
+
+	sort.Strings(fields)
+	return ParsedError{Kind: KindValidation, Fields: fields}, true
+}
+
+// identifiers splits a Prisma field list such as `a`,`b`
+func identifiers(list string) []string {
+	var fields []string
+	for _, match := range backtickIdentifiers.FindAllStringSubmatch(list, -1) {
+		fields = append(fields, match[1])
+	}
+	return fields
+}
+
+// columns splits a PostgreSQL key column list such as a, b
+func columns(list string) []string {
+	var fields []string
+	for _, column := range strings.Split(list, ",") {
+		if column = strings.Trim(strings.TrimSpace(column), `"`); column != "" {
+			fields = append(fields, column)
+		}
+	}
+	return fields
+}
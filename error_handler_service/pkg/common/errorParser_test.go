@@ -0,0 +1,81 @@
+// Copyright 2025 Naveen R
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package common
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// corpusCase is a sample error of another service together with its expected parse
+type corpusCase struct {
+	Name    string      `json:"name"`
+	Source  string      `json:"source"` // Service the message comes from
+	Message string      `json:"message"`
+	Want    ParsedError `json:"want"`
+}
+
+func TestParseErrorCorpus(t *testing.T) {
+	data, err := os.ReadFile("testdata/error_corpus.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var corpus []corpusCase
+	if err := json.Unmarshal(data, &corpus); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range corpus {
+		t.Run(tt.Name, func(t *testing.T) {
+			if got := ParseError(tt.Message); !reflect.DeepEqual(got, tt.Want) {
+				t.Errorf("ParseError() = %+v, want %+v", got, tt.Want)
+			}
+		})
+	}
+}
+
+func TestParseErrorData(t *testing.T) {
+	tests := []struct {
+		name string
+		data interface{}
+		want ParsedError
+	}{
+		{"nil", nil, ParsedError{Kind: KindUnknown}},
+		{"message", "Argument `user_id` is missing.", ParsedError{Kind: KindMissingField, Fields: []string{"user_id"}}},
+		{
+			"model state object",
+			map[string]interface{}{"Email": []string{"The Email field is required."}},
+			ParsedError{Kind: KindValidation, Fields: []string{"Email"}},
+		},
+		{
+			"problem details object",
+			map[string]interface{}{"title": "One or more validation errors occurred.", "errors": map[string]interface{}{"UserName": []interface{}{"The UserName field is required."}}},
+			ParsedError{Kind: KindValidation, Fields: []string{"UserName"}},
+		},
+		{"object without model state", map[string]interface{}{"msg": "No flora found"}, ParsedError{Kind: KindUnknown}},
+		{"number", 42, ParsedError{Kind: KindUnknown}},
+		{"not encodable", func() {}, ParsedError{Kind: KindUnknown}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseErrorData(tt.data); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseErrorData() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
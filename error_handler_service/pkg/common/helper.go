@@ -2,8 +2,6 @@ package common
 
 import (
 	"project_chimera/error_handle_service/pkg/models"
-	"regexp"
-	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -24,11 +22,36 @@ func FloraResponseToBson(body models.FloraResponse) bson.D {
 		{Key: "created_at", Value: time.Now().UTC()},
 
 		// Include additional metadata from FloraResponse
+		{Key: "code", Value: body.Data.Code},
 		{Key: "status", Value: body.Data.Status},
 		{Key: "error", Value: body.Data.Data.Error},
 		{Key: "id", Value: body.Data.Data.ID},
 		{Key: "response_type", Value: body.Data.Type},
 		{Key: "pattern", Value: body.Pattern},
+		{Key: "parsed_error", Value: ParseError(body.Data.Data.Error)},
+	}
+}
+
+// ErrorEventToBson converts an error event reported by the gene bank service into a BSON document
+func ErrorEventToBson(body models.ErrorEventMessage) bson.D {
+	event := body.Data
+
+	timestamp := event.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now().UTC()
+	}
+
+	return bson.D{
+		{Key: "pattern", Value: body.Pattern},
+		{Key: "code", Value: event.Code},
+		{Key: "status", Value: event.Status},
+		{Key: "type", Value: event.Type},
+		{Key: "data", Value: event.Data},
+		{Key: "service", Value: event.Service},
+		{Key: "stack", Value: event.Stack},
+		{Key: "request", Value: event.Request},
+		{Key: "timestamp", Value: timestamp},
+		{Key: "parsed_error", Value: ParseErrorData(event.Data)},
 	}
 }
 
@@ -44,35 +67,11 @@ func ErrorDataToBson(body models.ErrorDataDTO) bson.D {
 		{Key: "type", Value: responseData.Type},     // Error type
 		{Key: "data", Value: responseData.Data},     // Error data (could be a message or any other relevant info)
 		{Key: "timestamp", Value: time.Now().UTC()}, // Timestamp when the error occurred
+		{Key: "parsed_error", Value: ParseErrorData(responseData.Data)},
 	}
 }
 
-// Function to extract field name from an error string
-// ExtractFieldNameFromError attempts to find the field name causing a Prisma error
+// ExtractFieldNameFromError returns the field an upstream error is about, see ParseError
 func ExtractFieldNameFromError(errorString string) string {
-	// Try to find 'Invalid value for argument ...'
-	reMain := regexp.MustCompile(`(?i)Invalid value for argument [\` + "`" + `"']?(\w+)[\` + "`" + `"']?`)
-	match := reMain.FindStringSubmatch(errorString)
-	if len(match) > 1 {
-		return match[1]
-	}
-
-	// Fallback: try to locate the line where the type/value mismatch occurs
-	lines := strings.Split(errorString, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.HasSuffix(line, "~") || strings.Contains(line, "~") {
-			// Try to extract field name from line
-			colonSplit := strings.Split(line, ":")
-			if len(colonSplit) > 0 {
-				fieldLine := strings.TrimSpace(colonSplit[0])
-				fieldParts := strings.Split(fieldLine, "\"")
-				if len(fieldParts) >= 2 {
-					return fieldParts[1] // e.g., `"type": ...` → "type"
-				}
-			}
-		}
-	}
-
-	return ""
+	return ParseError(errorString).Field()
 }
@@ -0,0 +1,77 @@
+// Copyright 2025 Naveen R
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package models
+
+import "time"
+
+// Error events reported by gene_bank_service through its errorevent package
+const (
+	EventFloraGetAll    = "flora.getall"
+	EventFloraGetByID   = "flora.getbyid"
+	EventFloraGetImage  = "flora.getimage"
+	EventFloraPost      = "flora.post"
+	EventFloraPut       = "flora.put"
+	EventFloraDelete    = "flora.delete"
+	EventFloraRestore   = "flora.restore"
+	EventFloraForbidden = "flora.forbidden"
+)
+
+// Events published by flora_upstream_service
+const (
+	EventFloraCreated = "flora.created"
+	EventFloraUpdated = "flora.updated"
+	EventFloraDeleted = "flora.deleted"
+	// EventFloraSubmission reports a replayed command succeeded, other submissions go to the gene bank
+	EventFloraSubmission = "flora.submission"
+)
+
+// IsErrorEvent reports whether the pattern is one of the gene bank error events
+func IsErrorEvent(pattern string) bool {
+	switch pattern {
+	case EventFloraGetAll, EventFloraGetByID, EventFloraGetImage,
+		EventFloraPost, EventFloraPut, EventFloraDelete, EventFloraRestore, EventFloraForbidden:
+		return true
+	}
+	return false
+}
+
+// ErrorEventMessage is the envelope an ErrorEvent arrives in on the error queue
+type ErrorEventMessage struct {
+	Pattern string     `json:"pattern"`
+	Data    ErrorEvent `json:"data"`
+}
+
+// ErrorEvent mirrors gene_bank_service/internal/errorevent.ErrorEvent,
+// both are tested against contracts/error_event.json
+type ErrorEvent struct {
+	Code      int             `bson:"code" json:"code"`                           // HTTP status returned to the client
+	Status    string          `bson:"status" json:"status"`                       // Text of the HTTP status
+	Type      string          `bson:"type" json:"type"`                           // HTTP method of the failed request
+	Data      interface{}     `bson:"data" json:"data"`                           // Event specific details
+	Service   string          `bson:"service,omitempty" json:"service,omitempty"` // Service that reported the event
+	Stack     []string        `bson:"stack,omitempty" json:"stack,omitempty"`     // Call stack where the event was reported
+	Request   *RequestContext `bson:"request,omitempty" json:"request,omitempty"` // Request that caused the event
+	Timestamp time.Time       `bson:"timestamp" json:"timestamp"`                 // When the event was reported
+}
+
+// RequestContext describes the HTTP request an error event was reported for
+type RequestContext struct {
+	TraceID   string `bson:"trace_id,omitempty" json:"trace_id,omitempty"`
+	Method    string `bson:"method" json:"method"`
+	Path      string `bson:"path" json:"path"`
+	UserID    string `bson:"user_id,omitempty" json:"user_id,omitempty"`
+	IP        string `bson:"ip,omitempty" json:"ip,omitempty"`
+	UserAgent string `bson:"user_agent,omitempty" json:"user_agent,omitempty"`
+}
@@ -0,0 +1,90 @@
+// Copyright 2025 Naveen R
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package models
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// contractFile is shared with gene_bank_service, which checks its errorevent.ErrorEvent against it
+const contractFile = "../../../contracts/error_event.json"
+
+type contract struct {
+	Events  []string        `json:"events"`
+	Message json.RawMessage `json:"message"`
+}
+
+func loadContract(t *testing.T) contract {
+	t.Helper()
+
+	raw, err := os.ReadFile(contractFile)
+	if err != nil {
+		t.Fatalf("reading %s: %v", contractFile, err)
+	}
+	var c contract
+	if err := json.Unmarshal(raw, &c); err != nil {
+		t.Fatalf("decoding %s: %v", contractFile, err)
+	}
+	return c
+}
+
+func TestErrorEventsMatchContract(t *testing.T) {
+	names := []string{
+		EventFloraGetAll, EventFloraGetByID, EventFloraGetImage, EventFloraPost,
+		EventFloraPut, EventFloraDelete, EventFloraRestore, EventFloraForbidden,
+	}
+
+	c := loadContract(t)
+	if !reflect.DeepEqual(names, c.Events) {
+		t.Errorf("event names = %v, contract has %v", names, c.Events)
+	}
+	for _, name := range c.Events {
+		if !IsErrorEvent(name) {
+			t.Errorf("IsErrorEvent(%q) = false", name)
+		}
+	}
+}
+
+func TestErrorEventMessageMatchesContract(t *testing.T) {
+	c := loadContract(t)
+
+	var message ErrorEventMessage
+	if err := json.Unmarshal(c.Message, &message); err != nil {
+		t.Fatal(err)
+	}
+	if message.Data.Request == nil || message.Data.Timestamp.IsZero() {
+		t.Fatalf("request or timestamp not decoded: %+v", message.Data)
+	}
+
+	// Every field of the contract has to survive decoding and encoding again
+	encoded, err := json.Marshal(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got, want interface{}
+	if err := json.Unmarshal(encoded, &got); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(c.Message, &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("decoded message differs from the contract\n got: %s\nwant: %s", encoded, c.Message)
+	}
+}
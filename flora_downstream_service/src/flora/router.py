@@ -39,21 +39,33 @@ async def process_request(
     """
     try:
         if cmd == "get_all_floras":
-            res = await get_floras(db, rpc_consumer)
+            # The param is the FloraQuery of gene_bank_service, older versions send none
+            flora_query = data.get("param") if data else None
+            if not isinstance(flora_query, dict):
+                flora_query = None
+            res = await get_floras(db, rpc_consumer, flora_query)
 
             if res.code == 200:
-                return ApiResponse(status="success", code=res.code, data=res.data)
+                return ApiResponse(
+                    status="success", code=res.code, data=res.data, total=res.total
+                )
             else:
                 return ApiResponse(status="error", code=res.code, data=res.data)
 
         elif cmd == "get_flora_by_id":
-            flora_id = data.get("param")
+            # The param is the flora ID, or an object asking for soft deleted records as well
+            param = data.get("param")
+            include_deleted = False
+            if isinstance(param, dict):
+                include_deleted = bool(param.get("include_deleted"))
+                param = param.get("id")
+            flora_id = param
             if not flora_id:
                 return ApiResponse(
                     status="error", code=400, data="Flora ID not provided"
                 )
 
-            res = await get_flora(flora_id, db, rpc_consumer)
+            res = await get_flora(flora_id, db, rpc_consumer, include_deleted)
 
             if res.code == 200:
                 return ApiResponse(status="success", code=res.code, data=res.data)
@@ -12,8 +12,11 @@
 # 		See the License for the specific language governing permissions and
 # 		limitations under the License.
 
-from typing import Any, Dict, Optional
+import re
+from typing import Any, Dict, Optional, Tuple
 import uuid
+from odmantic import query
+from sqlalchemy import func, or_
 from sqlalchemy.future import select
 from src.db.mongo.mongo_connect import mongo_engine
 from sqlalchemy.ext.asyncio import AsyncSession
@@ -21,45 +24,204 @@ from src.model.common import FloraResponse
 from src.model.error_dto import ErrorDTO
 from src.model.flora import Flora
 from src.model.mongo_flora import FloraMongo
-from src.model.postgres_flora import FloraPG
+from src.model.postgres_flora import FloraPG, Type
 from src.queue.rabbit_consumer import RpcConsumer
 
 
+# Columns get_all_floras sorts by in PostgreSQL, origin lives in Mongo and is sorted there
+PG_SORT_COLUMNS = {
+    "common_name": func.lower(FloraPG.common_name),
+    "scientific_name": func.lower(FloraPG.scientific_name),
+    "type": FloraPG.type,
+}
+MAX_PAGE_SIZE = 100
+
+
+def _escape_regex(value: str) -> str:
+    return re.escape(value.strip())
+
+
+async def _mongo_flora_ids(filter: Dict[str, Any]) -> list[uuid.UUID]:
+    """
+    Return the IDs of the floras whose Mongo document matches the filter.
+    """
+    collection = mongo_engine.get_collection(FloraMongo)
+    ids: list[uuid.UUID] = []
+    for flora_id in await collection.distinct("flora_id", filter):
+        try:
+            ids.append(uuid.UUID(flora_id))
+        except (TypeError, ValueError):
+            continue
+    return ids
+
+
+async def _flora_conditions(flora_query: Dict[str, Any]) -> list:
+    """
+    Translate the FloraQuery of gene_bank_service into SQL conditions. Origin and
+    description live in Mongo, so their matches are turned into ID lists first.
+    """
+    conditions = [FloraPG.deleted_at.is_(None)]
+
+    if flora_query.get("type"):
+        conditions.append(FloraPG.type == flora_query["type"])
+    if flora_query.get("user_id"):
+        conditions.append(FloraPG.user_id == flora_query["user_id"])
+    if flora_query.get("hide_private"):
+        # Private records are only listed for their owner
+        viewer = flora_query.get("viewer") or ""
+        conditions.append(
+            or_(FloraPG.type != Type.private, FloraPG.user_id == viewer)
+        )
+
+    if flora_query.get("origin"):
+        ids = await _mongo_flora_ids(
+            {
+                "Origin": {
+                    "$regex": "^" + _escape_regex(flora_query["origin"]) + "$",
+                    "$options": "i",
+                }
+            }
+        )
+        conditions.append(FloraPG.id.in_(ids))
+
+    q = (flora_query.get("q") or "").strip().lower()
+    if q:
+        ids = await _mongo_flora_ids(
+            {"Description": {"$regex": _escape_regex(q), "$options": "i"}}
+        )
+        conditions.append(
+            or_(
+                func.lower(FloraPG.common_name).contains(q, autoescape=True),
+                func.lower(FloraPG.scientific_name).contains(q, autoescape=True),
+                FloraPG.id.in_(ids),
+            )
+        )
+
+    return conditions
+
+
+async def _page_by_origin(
+    db: AsyncSession, conditions: list, descending: bool, offset: int, limit: int
+) -> list[FloraPG]:
+    """
+    Page the matching floras sorted by their origin, which only Mongo knows.
+    """
+    result = await db.execute(select(FloraPG.id).where(*conditions))
+    ids = [str(flora_id) for flora_id in result.scalars().all()]
+    if not ids:
+        return []
+
+    cursor = (
+        mongo_engine.get_collection(FloraMongo)
+        .find({"flora_id": {"$in": ids}}, {"flora_id": 1})
+        .sort([("Origin", -1 if descending else 1), ("flora_id", 1)])
+        .collation({"locale": "en", "strength": 2})
+        .skip(offset)
+        .limit(limit)
+    )
+    page_ids = [document["flora_id"] async for document in cursor]
+    if not page_ids:
+        return []
+
+    result = await db.execute(
+        select(FloraPG).where(FloraPG.id.in_([uuid.UUID(i) for i in page_ids]))
+    )
+    florasPg = {str(floraPg.id): floraPg for floraPg in result.scalars().all()}
+    return [florasPg[i] for i in page_ids if i in florasPg]
+
+
+async def _query_floras(
+    db: AsyncSession, flora_query: Optional[Dict[str, Any]]
+) -> Tuple[list[FloraPG], Optional[int]]:
+    """
+    Filter, sort and page the floras in the databases and count the matches.
+    Without a query every record is returned uncounted, as older gene_bank_service
+    versions expect.
+    """
+    if not flora_query:
+        result = await db.execute(
+            select(FloraPG).where(FloraPG.deleted_at.is_(None))
+        )
+        return list(result.scalars().all()), None
+
+    conditions = await _flora_conditions(flora_query)
+
+    total = await db.scalar(
+        select(func.count()).select_from(FloraPG).where(*conditions)
+    )
+
+    size = min(max(int(flora_query.get("size") or MAX_PAGE_SIZE), 1), MAX_PAGE_SIZE)
+    page = max(int(flora_query.get("page") or 1), 1)
+    offset = (page - 1) * size
+    if offset >= total:
+        return [], total
+
+    sort = flora_query.get("sort") or ""
+    descending = flora_query.get("order") == "desc"
+    if sort == "origin":
+        return await _page_by_origin(db, conditions, descending, offset, size), total
+
+    statement = select(FloraPG).where(*conditions)
+    if sort in PG_SORT_COLUMNS:
+        column = PG_SORT_COLUMNS[sort]
+        statement = statement.order_by(column.desc() if descending else column.asc())
+    # The ID keeps pages stable between requests
+    statement = statement.order_by(FloraPG.id).offset(offset).limit(size)
+
+    result = await db.execute(statement)
+    return list(result.scalars().all()), total
+
+
 async def get_floras(
-    db: AsyncSession, rpc_consumer: RpcConsumer = None
+    db: AsyncSession,
+    rpc_consumer: RpcConsumer = None,
+    flora_query: Optional[Dict[str, Any]] = None,
 ) -> Optional[FloraResponse]:
     try:
-        result = await db.execute(select(FloraPG))
-        florasPg: list[FloraPG] = result.scalars().all()
+        florasPg, total = await _query_floras(db, flora_query)
 
         if not florasPg:
-            return FloraResponse(code=200, data=None)
+            return FloraResponse(code=200, data=None, total=total)
+
+        try:
+            ids = [str(floraPg.id) for floraPg in florasPg]
+            floraMongos = await mongo_engine.find(
+                FloraMongo, query.in_(FloraMongo.flora_id, ids)
+            )
+        except Exception as e:
+            print(f"An error occurred when retrieving Mongo data: {e}")
+            await rpc_consumer.sendMessage(
+                ErrorDTO(
+                    type="error",
+                    status="Internal Server Error",
+                    code=500,
+                    data={
+                        "error": f"An error occurred when retrieving Mongo data: {e}",
+                    },
+                ).to_dict(),
+                pattern_cmd="flora.getAll",
+                queue_name="error_dump_queue",
+            )
+            return FloraResponse(
+                code=500,
+                data=f"An error occurred when retrieving Mongo data: {e}",
+            )
+        floraMongoById = {floraMongo.flora_id: floraMongo for floraMongo in floraMongos}
+
+        # Lists in thumbnail size leave the full images out, records without a
+        # thumbnail keep theirs so gene_bank_service can create one
+        thumbnails_only = bool(flora_query) and flora_query.get("image") != "full"
 
         floras: list[Dict[str, Any]] = []
         for floraPg in florasPg:
-            try:
-                floraMongo = await mongo_engine.find_one(
-                    FloraMongo, FloraMongo.flora_id == str(floraPg.id)
-                )
-            except Exception as e:
-                print(f"An error occurred when retrieving Mongo data: {e}")
-                await rpc_consumer.sendMessage(
-                    ErrorDTO(
-                        type="error",
-                        status="Internal Server Error",
-                        code=500,
-                        data={
-                            "flora_id": str(floraPg.id),
-                            "error": f"An error occurred when retrieving Mongo data: {e}",
-                        },
-                    ).to_dict(),
-                    pattern_cmd="flora.getAll",
-                    queue_name="error_dump_queue",
-                )
-                return FloraResponse(
-                    code=500,
-                    data=f"An error occurred when retrieving Mongo data: {e}",
-                )
+            floraMongo = floraMongoById.get(str(floraPg.id))
+            if floraMongo is None:
+                print(f"No Mongo data found for flora {floraPg.id}")
+                continue
+
+            image = floraMongo.Image
+            if thumbnails_only and floraMongo.Thumbnail:
+                image = b""
 
             floraRes = Flora(
                 id=str(floraPg.id),
@@ -67,7 +229,8 @@ async def get_floras(
                 common_name=floraPg.common_name,
                 scientific_name=floraPg.scientific_name,
                 type=floraPg.type,
-                image=floraMongo.Image,
+                image=image,
+                thumbnail=floraMongo.Thumbnail,
                 description=floraMongo.Description,
                 origin=floraMongo.Origin,
                 other_details=floraMongo.OtherDetails,
@@ -87,7 +250,7 @@ async def get_floras(
                 pattern_cmd="flora.getAll",
                 queue_name="error_dump_queue",
             )
-            return FloraResponse(code=200, data=None)
+            return FloraResponse(code=200, data=None, total=total)
         await rpc_consumer.sendMessage(
             ErrorDTO(
                 type="success",
@@ -100,7 +263,7 @@ async def get_floras(
             pattern_cmd="flora.getAll",
             queue_name="error_dump_queue",
         )
-        return FloraResponse(code=200, data=floras)
+        return FloraResponse(code=200, data=floras, total=total)
 
     except Exception as e:
         # Handle exceptions and log or raise them accordingly
@@ -121,14 +284,20 @@ async def get_floras(
 
 
 async def get_flora(
-    flora_id: str, db: AsyncSession, rpc_consumer: RpcConsumer = None
+    flora_id: str,
+    db: AsyncSession,
+    rpc_consumer: RpcConsumer = None,
+    include_deleted: bool = False,
 ) -> Optional[FloraResponse]:
     try:
         uid = uuid.UUID(flora_id)
     except ValueError:
         return FloraResponse(code=400, data="Invalid flora ID")
 
-    result = await db.execute(select(FloraPG).where(FloraPG.id == uid))
+    statement = select(FloraPG).where(FloraPG.id == uid)
+    if not include_deleted:
+        statement = statement.where(FloraPG.deleted_at.is_(None))
+    result = await db.execute(statement)
     floraPg: FloraPG = result.scalar()
     if floraPg is None:
         return FloraResponse(code=404, data="Flora not found")
@@ -153,6 +322,7 @@ async def get_flora(
         scientific_name=floraPg.scientific_name,
         type=floraPg.type,
         image=floraMongo.Image,
+        thumbnail=floraMongo.Thumbnail,
         description=floraMongo.Description,
         origin=floraMongo.Origin,
         other_details=floraMongo.OtherDetails,
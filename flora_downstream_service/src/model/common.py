@@ -18,12 +18,21 @@ from typing import Any, Dict, List, Optional
 
 
 class FloraResponse:
-    def __init__(self, code: int, data: Optional[List[Dict[str, Any]]]):
+    def __init__(
+        self,
+        code: int,
+        data: Optional[List[Dict[str, Any]]],
+        total: Optional[int] = None,
+    ):
         self.code = code
         self.data = data
+        self.total = total  # Matching records of a paged query
 
     def to_dict(self) -> Dict[str, Any]:
-        return {"code": self.code, "data": self.data}
+        res = {"code": self.code, "data": self.data}
+        if self.total is not None:
+            res["total"] = self.total
+        return res
 
     def to_json(self) -> str:
         return json.dumps(self.to_dict())
@@ -37,14 +46,22 @@ class FloraResponse:
 
 class ApiResponse:
     def __init__(
-        self, status: str, code: int, data: Optional[List[Dict[str, Any]]] = None
+        self,
+        status: str,
+        code: int,
+        data: Optional[List[Dict[str, Any]]] = None,
+        total: Optional[int] = None,
     ):
         self.status = status
         self.code = code
         self.data = data or []
+        self.total = total  # Matching records of a paged query
 
     def to_dict(self) -> Dict[str, Any]:
-        return {"status": self.status, "code": self.code, "data": self.data}
+        res = {"status": self.status, "code": self.code, "data": self.data}
+        if self.total is not None:
+            res["total"] = self.total
+        return res
 
     def to_json(self) -> str:
         return json.dumps(self.to_dict())
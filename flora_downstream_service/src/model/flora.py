@@ -13,7 +13,7 @@
 # 		limitations under the License.
 
 import base64
-from typing import Any, Dict
+from typing import Any, Dict, Optional
 from pydantic import BaseModel
 from enum import Enum
 
@@ -28,6 +28,7 @@ class Flora(BaseModel):
     scientific_name: str
     type: Type
     image: bytes
+    thumbnail: Optional[bytes] = None
     description: str
     origin: str
     other_details: Dict[str, Any]
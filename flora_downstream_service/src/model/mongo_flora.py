@@ -12,13 +12,14 @@
 # 		See the License for the specific language governing permissions and
 # 		limitations under the License.
 
-from typing import Any, Dict
+from typing import Any, Dict, Optional
 from odmantic import Model
 
 
 class FloraMongo(Model):
     flora_id: str
     Image: bytes  # Representing binary data
+    Thumbnail: Optional[bytes] = None  # Thumbnail created by gene_bank_service
     Description: str
     Origin: str
     OtherDetails: Dict[str, Any]
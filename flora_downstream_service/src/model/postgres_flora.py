@@ -13,7 +13,7 @@
 # 		limitations under the License.
 
 import uuid
-from sqlalchemy import Column, String, Enum
+from sqlalchemy import Column, DateTime, String, Enum
 from sqlalchemy.dialects.postgresql import UUID
 from sqlalchemy.ext.declarative import declarative_base
 from enum import Enum as en
@@ -33,6 +33,8 @@ class FloraPG(Base):
     common_name = Column(String, unique=True, index=True)
     scientific_name = Column(String, unique=True, index=True)
     type = Column(Enum(Type), nullable=False)
+    # Set by soft deletes of flora_upstream_service, deleted records are hidden from reads
+    deleted_at = Column(DateTime, nullable=True)
 
     def __repr__(self):
         return (
@@ -0,0 +1,2 @@
+-- AlterTable
+ALTER TABLE "Flora" ADD COLUMN     "deleted_at" TIMESTAMP(3);
@@ -18,9 +18,22 @@ export class RabbitMqPayload {
   CommonName!: string; // Common name of the plant
   ScientificName!: string; // Scientific name of the plant
   Image!: Uint8Array; // Image data (bytes)
+  Thumbnail?: Uint8Array; // Thumbnail of the image (bytes)
   Description!: string; // Description of the plant
   Origin!: string; // Origin of the plant
   OtherDetails!: object; // Additional details about the plant
   Type!: $Enums.PostType; // Type of post
   UserId!: string; // User ID
 }
+
+export class RabbitMqDeletePayload {
+  ID!: string; // Unique identifier for the plant
+  UserId!: string; // User requesting the change
+  Soft?: boolean; // Hide the plant instead of removing it, restore_flora undoes it
+  Privileged?: boolean; // The user may change plants of other users
+}
+
+export class RabbitMqThumbnailPayload {
+  ID!: string; // Unique identifier for the plant
+  Thumbnail!: Uint8Array; // Thumbnail of the image (bytes)
+}
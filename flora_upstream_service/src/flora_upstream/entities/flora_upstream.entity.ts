@@ -19,6 +19,7 @@ export class FloraUpstream {
   scientific_name!: string;
   user_id!: string;
   Image!: Uint8Array;
+  Thumbnail?: Uint8Array;
   Description!: string;
   Origin!: string;
   OtherDetails!: object;
@@ -19,8 +19,13 @@ import {
   RmqContext,
 } from '@nestjs/microservices';
 import { FloraUpstreamService } from './flora_upstream.service';
-import { RabbitMqPayload } from './dto/rabbit-payload';
+import {
+  RabbitMqDeletePayload,
+  RabbitMqPayload,
+  RabbitMqThumbnailPayload,
+} from './dto/rabbit-payload';
 import { FloraUpstream } from './entities/flora_upstream.entity';
+import { getSubmissionId } from 'src/utils/submission';
 
 @Controller()
 export class FloraUpstreamController {
@@ -30,6 +35,7 @@ export class FloraUpstreamController {
   async create(@Payload() data: RabbitMqPayload, @Ctx() context: RmqContext) {
     const channel = context.getChannelRef();
     const originalMsg = context.getMessage();
+    const submissionId = getSubmissionId(originalMsg);
 
     const createFloraUpstreamDto: FloraUpstream = {
       common_name: data.CommonName,
@@ -37,12 +43,16 @@ export class FloraUpstreamController {
       user_id: data.UserId,
       type: data.Type,
       Image: data.Image,
+      Thumbnail: data.Thumbnail,
       Description: data.Description,
       Origin: data.Origin,
       OtherDetails: data.OtherDetails,
     };
     try {
-      await this.floraUpstreamService.create(createFloraUpstreamDto);
+      await this.floraUpstreamService.create(
+        createFloraUpstreamDto,
+        submissionId,
+      );
     } catch (error) {
       console.log(error);
     } finally {
@@ -54,6 +64,7 @@ export class FloraUpstreamController {
   async update(@Payload() data: RabbitMqPayload, @Ctx() context: RmqContext) {
     const channel = context.getChannelRef();
     const originalMsg = context.getMessage();
+    const submissionId = getSubmissionId(originalMsg);
 
     const updateFloraUpstreamDto: FloraUpstream = {
       common_name: data.CommonName,
@@ -61,6 +72,7 @@ export class FloraUpstreamController {
       user_id: data.UserId,
       type: data.Type,
       Image: data.Image,
+      Thumbnail: data.Thumbnail,
       Description: data.Description,
       Origin: data.Origin,
       OtherDetails: data.OtherDetails,
@@ -69,6 +81,7 @@ export class FloraUpstreamController {
       await this.floraUpstreamService.update(
         data.ID as string,
         updateFloraUpstreamDto,
+        submissionId,
       );
     } catch (error) {
       console.log(error);
@@ -76,4 +89,65 @@ export class FloraUpstreamController {
       channel.ack(originalMsg);
     }
   }
+
+  @MessagePattern({ cmd: 'delete_flora' })
+  async remove(
+    @Payload() data: RabbitMqDeletePayload,
+    @Ctx() context: RmqContext,
+  ) {
+    const channel = context.getChannelRef();
+    const originalMsg = context.getMessage();
+
+    try {
+      await this.floraUpstreamService.remove(
+        data.ID,
+        data.UserId,
+        data.Soft === true,
+        data.Privileged === true,
+      );
+    } catch (error) {
+      console.log(error);
+    } finally {
+      channel.ack(originalMsg);
+    }
+  }
+
+  @MessagePattern({ cmd: 'restore_flora' })
+  async restore(
+    @Payload() data: RabbitMqDeletePayload,
+    @Ctx() context: RmqContext,
+  ) {
+    const channel = context.getChannelRef();
+    const originalMsg = context.getMessage();
+
+    try {
+      await this.floraUpstreamService.restore(
+        data.ID,
+        data.UserId,
+        data.Privileged === true,
+      );
+    } catch (error) {
+      console.log(error);
+    } finally {
+      channel.ack(originalMsg);
+    }
+  }
+
+  // Stores the thumbnail gene_bank_service created for a flora saved without one
+  @MessagePattern({ cmd: 'set_thumbnail' })
+  async setThumbnail(
+    @Payload() data: RabbitMqThumbnailPayload,
+    @Ctx() context: RmqContext,
+  ) {
+    const channel = context.getChannelRef();
+    const originalMsg = context.getMessage();
+
+    try {
+      await this.floraUpstreamService.setThumbnail(data.ID, data.Thumbnail);
+    } catch (error) {
+      console.log(error);
+    } finally {
+      channel.ack(originalMsg);
+    }
+  }
 }
@@ -24,6 +24,7 @@ import { RmqClientModule } from 'src/client/Rmq.client';
     MongooseModule.forFeature([{ name: Flora.name, schema: FloraSchema }]),
     RmqClientModule.register('notification', 'notification_queue'),
     RmqClientModule.register('error', 'error_dump_queue'),
+    RmqClientModule.register('submission', 'flora_submission_queue'),
   ],
   controllers: [FloraUpstreamController],
   providers: [FloraUpstreamService, PrismaService],
@@ -25,6 +25,17 @@ import {
 } from 'src/utils/data-mapper';
 import { ClientProxy } from '@nestjs/microservices';
 import { NotificationResponse } from './dto/notification_response';
+import { isReplaySubmission, withSubmissionId } from 'src/utils/submission';
+
+// Rejection of a flora command with the status code reported to the error dump
+class FloraCommandError extends Error {
+  constructor(
+    readonly code: number,
+    message: string,
+  ) {
+    super(message);
+  }
+}
 
 @Injectable()
 export class FloraUpstreamService {
@@ -33,9 +44,53 @@ export class FloraUpstreamService {
     @InjectModel(FloraMongo.name) private floraModel: Model<FloraMongo>,
     @Inject('NOTIFICATION_SERVICE') private readonly RmqClient: ClientProxy,
     @Inject('ERROR_SERVICE') private readonly errClient: ClientProxy,
+    @Inject('SUBMISSION_SERVICE')
+    private readonly submissionClient: ClientProxy,
   ) {}
 
-  async create(data: FloraUpstream): Promise<FloraUpstream | Error> {
+  // Reports a successful gene bank submission, commands sent without a submission ID are skipped.
+  // Replayed commands are reported to error_handler_service, which tracks their outcome.
+  private reportSubmissionSuccess(submissionId?: string, floraId?: string) {
+    if (!submissionId) {
+      return;
+    }
+    const client = isReplaySubmission(submissionId)
+      ? this.errClient
+      : this.submissionClient;
+    client
+      .emit(
+        'flora.submission',
+        withSubmissionId(
+          {
+            submission_id: submissionId,
+            status: 'succeeded',
+            flora_id: floraId,
+          },
+          submissionId,
+        ),
+      )
+      .subscribe(() => {
+        console.log('Submission status sent successfully');
+      });
+  }
+
+  // Tells gene_bank_service a flora changed, so it can drop its cached copies.
+  // flora.updated and flora.deleted name failure dumps on the error queue, so changes use their own pattern.
+  private reportFloraChanged(
+    change: 'updated' | 'deleted' | 'restored',
+    floraId: string,
+  ) {
+    this.submissionClient
+      .emit('flora.changed', { flora_id: floraId, change })
+      .subscribe(() => {
+        console.log(`flora.changed (${change}) event sent successfully`);
+      });
+  }
+
+  async create(
+    data: FloraUpstream,
+    submissionId?: string,
+  ): Promise<FloraUpstream | Error> {
     let id: string | null = null;
     try {
       const pgData: FloraPg = toFloraPg(data);
@@ -68,6 +123,8 @@ export class FloraUpstreamService {
         console.log('Notification sent successfully');
       });
 
+      this.reportSubmissionSuccess(submissionId, result.id);
+
       return result;
     } catch (error: any) {
       // Rollback PostgreSQL changes if necessary
@@ -94,15 +151,18 @@ export class FloraUpstreamService {
       this.errClient
         .emit(
           'flora.created',
-          new NotificationResponse({
-            type: 'POST',
-            status: 'error',
-            code: 500,
-            data: {
-              values: data,
-              error: JSON.stringify(error.message),
-            },
-          }),
+          withSubmissionId(
+            new NotificationResponse({
+              type: 'POST',
+              status: 'error',
+              code: 500,
+              data: {
+                values: data,
+                error: JSON.stringify(error.message),
+              },
+            }),
+            submissionId,
+          ),
         )
         .subscribe(() => {
           console.log('Error dump sent successfully');
@@ -112,7 +172,7 @@ export class FloraUpstreamService {
     }
   }
 
-  async update(id: string, data: FloraUpstream) {
+  async update(id: string, data: FloraUpstream, submissionId?: string) {
     try {
       const pgData: FloraPg = toFloraPg(data);
       const pgResult = await this.prisma.$transaction(async (prisma) => {
@@ -150,6 +210,9 @@ export class FloraUpstreamService {
         }),
       );
 
+      this.reportSubmissionSuccess(submissionId, id);
+      this.reportFloraChanged('updated', id);
+
       return updatedFlora;
     } catch (error: any) {
       console.log(error);
@@ -166,16 +229,19 @@ export class FloraUpstreamService {
       this.errClient
         .emit(
           'flora.updated',
-          new NotificationResponse({
-            type: 'PUT',
-            status: 'error',
-            code: 500,
-            data: JSON.stringify({
-              values: data,
-              error: JSON.stringify(error.message),
-              id: id,
+          withSubmissionId(
+            new NotificationResponse({
+              type: 'PUT',
+              status: 'error',
+              code: 500,
+              data: JSON.stringify({
+                values: data,
+                error: JSON.stringify(error.message),
+                id: id,
+              }),
             }),
-          }),
+            submissionId,
+          ),
         )
         .subscribe(() => {
           console.log('Error dump sent successfully');
@@ -183,4 +249,113 @@ export class FloraUpstreamService {
       throw error;
     }
   }
+
+  // Soft deletes hide the flora until restore is called, hard deletes remove it from both stores
+  async remove(id: string, userId: string, soft: boolean, privileged = false) {
+    try {
+      const flora = await this.prisma.flora.findUnique({ where: { id } });
+      if (!flora || (soft && flora.deleted_at)) {
+        throw new FloraCommandError(404, `Flora ${id} not found`);
+      }
+      if (!privileged && flora.user_id !== userId) {
+        throw new FloraCommandError(
+          403,
+          `User ${userId} is not allowed to delete flora ${id}`,
+        );
+      }
+
+      if (soft) {
+        await this.prisma.flora.update({
+          where: { id },
+          data: { deleted_at: new Date() },
+        });
+      } else {
+        await this.prisma.flora.delete({ where: { id } });
+        await this.floraModel.deleteOne({ flora_id: id });
+      }
+
+      this.RmqClient.emit(
+        'flora-deleted',
+        new NotificationResponse({
+          type: 'DELETE',
+          status: 'success',
+          code: 200,
+          data: { id: JSON.stringify(id), soft },
+        }),
+      );
+      this.reportFloraChanged('deleted', id);
+
+      return id;
+    } catch (error: any) {
+      this.reportFloraCommandFailed('DELETE', id, error);
+      throw error;
+    }
+  }
+
+  // Makes a soft deleted flora visible again
+  async restore(id: string, userId: string, privileged = false) {
+    try {
+      const flora = await this.prisma.flora.findUnique({ where: { id } });
+      if (!flora || !flora.deleted_at) {
+        throw new FloraCommandError(404, `Deleted flora ${id} not found`);
+      }
+      if (!privileged && flora.user_id !== userId) {
+        throw new FloraCommandError(
+          403,
+          `User ${userId} is not allowed to restore flora ${id}`,
+        );
+      }
+
+      await this.prisma.flora.update({
+        where: { id },
+        data: { deleted_at: null },
+      });
+
+      this.RmqClient.emit(
+        'flora-restored',
+        new NotificationResponse({
+          type: 'POST',
+          status: 'success',
+          code: 200,
+          data: { id: JSON.stringify(id) },
+        }),
+      );
+      // Cached lists left the flora out while it was deleted
+      this.reportFloraChanged('restored', id);
+
+      return id;
+    } catch (error: any) {
+      this.reportFloraCommandFailed('POST', id, error);
+      throw error;
+    }
+  }
+
+  // Backfills the thumbnail of a flora, thumbnails that already exist are kept
+  async setThumbnail(id: string, thumbnail: Uint8Array) {
+    await this.floraModel.updateOne(
+      { flora_id: id, Thumbnail: { $exists: false } },
+      { $set: { Thumbnail: Buffer.from(thumbnail) } },
+    );
+  }
+
+  // Dumps a failed delete or restore, error_handler_service keeps them as flora.deleted failures
+  private reportFloraCommandFailed(type: string, id: string, error: any) {
+    console.log(error);
+    this.errClient
+      .emit(
+        'flora.deleted',
+        new NotificationResponse({
+          type,
+          status: 'error',
+          code: error instanceof FloraCommandError ? error.code : 500,
+          data: {
+            id,
+            error: JSON.stringify(error.message),
+          },
+        }),
+      )
+      .subscribe(() => {
+        console.log('Error dump sent successfully');
+      });
+  }
 }
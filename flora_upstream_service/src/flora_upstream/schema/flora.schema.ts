@@ -24,6 +24,9 @@ export class Flora {
   @Prop({ type: Buffer }) // Use Buffer for binary data
   Image!: Buffer;
 
+  @Prop({ type: Buffer })
+  Thumbnail?: Buffer;
+
   @Prop({ required: true })
   Description!: string;
 
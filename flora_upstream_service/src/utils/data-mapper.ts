@@ -28,6 +28,7 @@ export function toFloraMongo(id: string, upstream: FloraUpstream): Flora {
   return {
     flora_id: id,
     Image: Buffer.from(upstream.Image),
+    Thumbnail: upstream.Thumbnail ? Buffer.from(upstream.Thumbnail) : undefined,
     Description: upstream.Description,
     Origin: upstream.Origin,
     OtherDetails: upstream.OtherDetails,
@@ -45,6 +46,7 @@ export function toFloraUpstream(
     user_id: pgData.user_id,
     type: pgData.type,
     Image: mongoData.Image,
+    Thumbnail: mongoData.Thumbnail,
     Description: mongoData.Description,
     Origin: mongoData.Origin,
     OtherDetails: mongoData.OtherDetails,
@@ -0,0 +1,46 @@
+//	Copyright 2025 Naveen R
+//
+//		Licensed under the Apache License, Version 2.0 (the "License");
+//		you may not use this file except in compliance with the License.
+//		You may obtain a copy of the License at
+//
+//		http://www.apache.org/licenses/LICENSE-2.0
+//
+//		Unless required by applicable law or agreed to in writing, software
+//		distributed under the License is distributed on an "AS IS" BASIS,
+//		WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//		See the License for the specific language governing permissions and
+//		limitations under the License.
+import { RmqRecord, RmqRecordBuilder } from '@nestjs/microservices';
+
+// Correlates flora commands and events with the gene bank submission that caused them
+export const SUBMISSION_ID_HEADER = 'x-submission-id';
+
+// Prefix of the submission IDs error_handler_service gives the commands it replays
+const REPLAY_SUBMISSION_PREFIX = 'replay:';
+
+// Reports whether the submission ID belongs to a command replayed by error_handler_service
+export function isReplaySubmission(submissionId?: string): boolean {
+  return !!submissionId && submissionId.startsWith(REPLAY_SUBMISSION_PREFIX);
+}
+
+// Reads the submission ID header from an incoming RabbitMQ message
+export function getSubmissionId(message: {
+  properties?: { headers?: Record<string, unknown> };
+}): string | undefined {
+  const value = message?.properties?.headers?.[SUBMISSION_ID_HEADER];
+  return typeof value === 'string' && value !== '' ? value : undefined;
+}
+
+// Wraps an event payload so the submission ID travels along as header
+export function withSubmissionId<T>(
+  payload: T,
+  submissionId?: string,
+): T | RmqRecord<T> {
+  if (!submissionId) {
+    return payload;
+  }
+  return new RmqRecordBuilder(payload)
+    .setOptions({ headers: { [SUBMISSION_ID_HEADER]: submissionId } })
+    .build();
+}
@@ -31,9 +31,17 @@ import (
 
 	"project_chimera/gene_bank_service/config"
 	"project_chimera/gene_bank_service/internal/actuator"
+	"project_chimera/gene_bank_service/internal/auth"
+	"project_chimera/gene_bank_service/internal/cache"
 	"project_chimera/gene_bank_service/internal/consul"
+	"project_chimera/gene_bank_service/internal/disconnect"
+	"project_chimera/gene_bank_service/internal/errorevent"
 	"project_chimera/gene_bank_service/internal/flora"
+	"project_chimera/gene_bank_service/internal/idempotency"
+	"project_chimera/gene_bank_service/internal/problem"
 	"project_chimera/gene_bank_service/internal/rabbitmq"
+	"project_chimera/gene_bank_service/internal/ratelimit"
+	"project_chimera/gene_bank_service/internal/submission"
 )
 
 // @title Gene Bank Service API
@@ -53,6 +61,8 @@ func main() {
 	folraQueueName := "flora_upstream_queue"
 	floraDownstreamQueueName := "flora_downstream_queue"
 	errorQueueName := "error_dump_queue"
+	submissionQueueName := "flora_submission_queue"
+	submissionExchangeName := "flora_submission_events"
 
 	rpcClient, err := rabbitmq.NewRabbitMQClient(rabbitURL)
 	if err != nil {
@@ -64,11 +74,28 @@ func main() {
 	rpcClient.StartConsumer()
 	log.Println("RabbitMQ consumer started successfully!")
 
+	// Cache flora reads when enabled, entries are dropped when upstream reports a change
+	var floraCache cache.Cache = cache.Noop{}
+	if config.Env.CacheEnabled {
+		floraCache = cache.NewLRU(config.Env.CacheTTL, config.Env.CacheMaxEntries, config.Env.CacheMaxBytes)
+	}
+
+	// Track submission status from the events that come back from upstream and the error handler.
+	// The events are fanned out to every instance, each keeps its own submissions and cache.
+	submissions := submission.NewStore(config.Env.SubmissionTTL, rpcClient, submissionExchangeName)
+	if err := rpcClient.StartBroadcastConsumer(submissionQueueName, submissionExchangeName, flora.CacheEventHandler(floraCache, submissions.HandleEvent)); err != nil {
+		log.Fatalf("Failed to start submission event consumer: %v", err)
+	}
+
 	// Create queue handler
 	FloraUpstreamQueueHandler := rabbitmq.NewQueueHandler(rpcClient, folraQueueName)
 	floraDownstreamQueueHandler := rabbitmq.NewQueueHandler(rpcClient, floraDownstreamQueueName)
 	errorQueueHandler := rabbitmq.NewQueueHandler(rpcClient, errorQueueName)
 
+	// Report errors to the error queue in the background, buffering them while it is unreachable
+	errorReporter := errorevent.NewReporter(errorQueueHandler, config.Env.ServiceName, config.Env.ErrorReportBufferSize, config.Env.ErrorReportRetryInterval)
+	defer errorReporter.Close()
+
 	// List of RabbitMQ handlers
 	var rmqHandlers = []*rabbitmq.Handler{
 		FloraUpstreamQueueHandler,
@@ -76,15 +103,31 @@ func main() {
 		errorQueueHandler,
 	}
 
-	// Initialize the Fiber app
-	app := fiber.New()
+	// Initialize the Fiber app, request bodies are streamed so image uploads
+	// are read part by part and size limited by the flora handlers
+	app := fiber.New(fiber.Config{
+		StreamRequestBody:            true,
+		DisablePreParseMultipartForm: true,
+		ErrorHandler:                 problem.ErrorHandler,
+		// Take the client IP from the proxy header only when the gateway in front of us sent it
+		ProxyHeader:             config.Env.ProxyHeader,
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          config.Env.TrustedProxies,
+	})
+
+	// Assign every request a trace ID that error responses refer to
+	app.Use(problem.RequestID())
+
+	// Cancel the work of a request once its client goes away
+	app.Use(disconnect.Middleware())
 
 	// set up cross-origin resource sharing (CORS) middleware
 	app.Use(cors.New(
 		cors.Config{
-			AllowOrigins: "*",
-			AllowMethods: "GET,POST,PUT,DELETE,OPTIONS",
-			AllowHeaders: "Content-Type, Authorization",
+			AllowOrigins:  "*",
+			AllowMethods:  "GET,POST,PUT,DELETE,OPTIONS",
+			AllowHeaders:  "Content-Type, Authorization, X-Request-ID, Idempotency-Key, If-None-Match, If-Modified-Since",
+			ExposeHeaders: "X-Request-ID, Idempotent-Replayed, ETag, Last-Modified, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, RateLimit-Policy, Retry-After",
 		},
 	))
 
@@ -152,12 +195,40 @@ func main() {
 	actuatorGroup := app.Group("/actuator")
 	floraGroup := app.Group("/flora")
 
+	// Verify bearer tokens when enabled, so ownership checks do not rely on the X-Auth-UserId header
+	if config.Env.AuthEnabled {
+		verifier, err := auth.NewVerifier(auth.Config{
+			Secret:         config.Env.JWTSecret,
+			SecretFile:     config.Env.JWTSecretFile,
+			JWKSURL:        config.Env.JWKSURL,
+			JWKSFile:       config.Env.JWKSFile,
+			ReloadInterval: config.Env.JWTKeyReloadInterval,
+			Issuer:         config.Env.JWTIssuer,
+			Audience:       config.Env.JWTAudience,
+		})
+		if err != nil {
+			log.Fatalf("Failed to initialize JWT verification: %v", err)
+		}
+		defer verifier.Close()
+
+		floraGroup.Use(auth.Middleware(verifier))
+	}
+
+	// Limit each user, falling back to the client IP, to separate read and write budgets
+	floraGroup.Use(ratelimit.Middleware(ratelimit.Config{
+		Read:  ratelimit.Budget{PerMinute: config.Env.RateLimitReadPerMinute, Burst: config.Env.RateLimitReadBurst},
+		Write: ratelimit.Budget{PerMinute: config.Env.RateLimitWritePerMinute, Burst: config.Env.RateLimitWriteBurst},
+	}))
+
+	// Replay the first response of POST/PUT requests retried with the same Idempotency-Key
+	floraGroup.Use(idempotency.Middleware(idempotency.NewMemoryBackend(config.Env.IdempotencyMaxKeys), config.Env.IdempotencyTTL, flora.MaxBodySize()))
+
 	// Register routes
 	app.Get("/hello", func(c *fiber.Ctx) error {
 		return c.SendString("Hello, World!")
 	})
 	actuator.ActuatorRouter(actuatorGroup, rmqHandlers)
-	flora.FloraRouter(floraGroup, FloraUpstreamQueueHandler, floraDownstreamQueueHandler, errorQueueHandler)
+	flora.FloraRouter(floraGroup, FloraUpstreamQueueHandler, floraDownstreamQueueHandler, errorReporter, submissions, flora.NewFloraPolicy(config.Env.PrivilegedRoles), floraCache)
 
 	// Logger setup
 	app.Use(logger.New(logger.Config{
@@ -18,6 +18,8 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -34,6 +36,52 @@ type Config struct {
 	DeregisterCriticalServiceAfter string
 	AppPort                        string
 	RabbitMQurl                    string
+	RPCTimeout                     time.Duration
+	RPCMaxTimeout                  time.Duration
+	RPCCommandTimeouts             map[string]time.Duration
+	PublishConfirmTimeout          time.Duration
+	PublishMaxAttempts             int
+	SubmissionTTL                  time.Duration
+	MaxImageSize                   int64
+	ImageFetchConnectTimeout       time.Duration
+	ImageFetchReadTimeout          time.Duration
+	ImageFetchMaxRedirects         int
+	ImageFetchAllowedHosts         []string
+	ImageFetchDeniedHosts          []string
+	ImageFetchAllowedCIDRs         []string
+	ImageFetchDeniedCIDRs          []string
+	ImageImportEnabled             bool
+	ImageImportRoot                string
+	ImageMaxPixels                 int
+	ImageMaxDimension              int
+	ImageThumbnailSize             int
+	ImageJPEGQuality               int
+	ErrorReportBufferSize          int
+	ErrorReportRetryInterval       time.Duration
+	AuthEnabled                    bool
+	JWTSecret                      string
+	JWTSecretFile                  string
+	JWKSURL                        string
+	JWKSFile                       string
+	JWTKeyReloadInterval           time.Duration
+	JWTIssuer                      string
+	JWTAudience                    string
+	PrivilegedRoles                []string
+	IdempotencyTTL                 time.Duration
+	IdempotencyMaxKeys             int
+	RateLimitReadPerMinute         int
+	RateLimitReadBurst             int
+	RateLimitWritePerMinute        int
+	RateLimitWriteBurst            int
+	ProxyHeader                    string
+	TrustedProxies                 []string
+	RPCBreakerFailures             int
+	RPCBreakerOpenTimeout          time.Duration
+	RPCBreakerHalfOpenProbes       int
+	CacheEnabled                   bool
+	CacheTTL                       time.Duration
+	CacheMaxEntries                int
+	CacheMaxBytes                  int
 }
 
 var Env Config
@@ -63,7 +111,146 @@ func LoadConfig() {
 		DeregisterCriticalServiceAfter: os.Getenv("DEREGISTER_CRITICAL_SERVICE_AFTER"),
 		AppPort:                        os.Getenv("APP_PORT"),
 		RabbitMQurl:                    os.Getenv("RABBITMQ_URL"),
+		RPCTimeout:                     getDurationEnv("RPC_TIMEOUT", 10*time.Second),
+		RPCMaxTimeout:                  getDurationEnv("RPC_MAX_TIMEOUT", 30*time.Second),
+		RPCCommandTimeouts:             getDurationMapEnv("RPC_COMMAND_TIMEOUTS"),
+		PublishConfirmTimeout:          getDurationEnv("PUBLISH_CONFIRM_TIMEOUT", 5*time.Second),
+		PublishMaxAttempts:             getIntEnv("PUBLISH_MAX_ATTEMPTS", 3),
+		SubmissionTTL:                  getDurationEnv("SUBMISSION_TTL", 24*time.Hour),
+		MaxImageSize:                   int64(getIntEnv("MAX_IMAGE_SIZE", 5<<20)),
+		ImageFetchConnectTimeout:       getDurationEnv("IMAGE_FETCH_CONNECT_TIMEOUT", 5*time.Second),
+		ImageFetchReadTimeout:          getDurationEnv("IMAGE_FETCH_READ_TIMEOUT", 15*time.Second),
+		ImageFetchMaxRedirects:         getIntEnv("IMAGE_FETCH_MAX_REDIRECTS", 3),
+		ImageFetchAllowedHosts:         getListEnv("IMAGE_FETCH_ALLOWED_HOSTS"),
+		ImageFetchDeniedHosts:          getListEnv("IMAGE_FETCH_DENIED_HOSTS"),
+		ImageFetchAllowedCIDRs:         getListEnv("IMAGE_FETCH_ALLOWED_CIDRS"),
+		ImageFetchDeniedCIDRs:          getListEnv("IMAGE_FETCH_DENIED_CIDRS"),
+		ImageImportEnabled:             getBoolEnv("IMAGE_IMPORT_ENABLED", false),
+		ImageImportRoot:                os.Getenv("IMAGE_IMPORT_ROOT"),
+		ImageMaxPixels:                 getIntEnv("IMAGE_MAX_PIXELS", 40_000_000),
+		ImageMaxDimension:              getIntEnv("IMAGE_MAX_DIMENSION", 2048),
+		ImageThumbnailSize:             getIntEnv("IMAGE_THUMBNAIL_SIZE", 256),
+		ImageJPEGQuality:               getIntEnv("IMAGE_JPEG_QUALITY", 85),
+		ErrorReportBufferSize:          getIntEnv("ERROR_REPORT_BUFFER_SIZE", 1000),
+		ErrorReportRetryInterval:       getDurationEnv("ERROR_REPORT_RETRY_INTERVAL", 5*time.Second),
+		AuthEnabled:                    getBoolEnv("AUTH_ENABLED", false),
+		JWTSecret:                      os.Getenv("JWT_SECRET"),
+		JWTSecretFile:                  os.Getenv("JWT_SECRET_FILE"),
+		JWKSURL:                        os.Getenv("JWT_JWKS_URL"),
+		JWKSFile:                       os.Getenv("JWT_JWKS_FILE"),
+		JWTKeyReloadInterval:           getDurationEnv("JWT_KEY_RELOAD_INTERVAL", time.Minute),
+		JWTIssuer:                      os.Getenv("JWT_ISSUER"),
+		JWTAudience:                    os.Getenv("JWT_AUDIENCE"),
+		PrivilegedRoles:                getListEnvDefault("FLORA_PRIVILEGED_ROLES", []string{"admin", "curator"}),
+		IdempotencyTTL:                 getDurationEnv("IDEMPOTENCY_TTL", 24*time.Hour),
+		IdempotencyMaxKeys:             getIntEnv("IDEMPOTENCY_MAX_KEYS", 10000),
+		RateLimitReadPerMinute:         getIntEnv("RATE_LIMIT_READ_PER_MINUTE", 120),
+		RateLimitReadBurst:             getIntEnv("RATE_LIMIT_READ_BURST", 60),
+		RateLimitWritePerMinute:        getIntEnv("RATE_LIMIT_WRITE_PER_MINUTE", 20),
+		RateLimitWriteBurst:            getIntEnv("RATE_LIMIT_WRITE_BURST", 10),
+		ProxyHeader:                    getStringEnv("PROXY_HEADER", "X-Forwarded-For"),
+		TrustedProxies:                 getListEnv("TRUSTED_PROXIES"),
+		RPCBreakerFailures:             getIntEnv("RPC_BREAKER_FAILURES", 5),
+		RPCBreakerOpenTimeout:          getDurationEnv("RPC_BREAKER_OPEN_TIMEOUT", 30*time.Second),
+		RPCBreakerHalfOpenProbes:       getIntEnv("RPC_BREAKER_HALF_OPEN_PROBES", 2),
+		CacheEnabled:                   getBoolEnv("FLORA_CACHE_ENABLED", false),
+		CacheTTL:                       getDurationEnv("FLORA_CACHE_TTL", 5*time.Minute),
+		CacheMaxEntries:                getIntEnv("FLORA_CACHE_MAX_ENTRIES", 1000),
+		CacheMaxBytes:                  getIntEnv("FLORA_CACHE_MAX_BYTES", 64<<20),
 	}
 
 	log.Println("Configuration loaded successfully!")
 }
+
+// getStringEnv reads a string from the environment, falling back to def
+func getStringEnv(key string, def string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return def
+}
+
+// getIntEnv reads an integer from the environment, falling back to def
+func getIntEnv(key string, def int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Error converting %s to int, using %d: %v", key, def, err)
+		return def
+	}
+	return number
+}
+
+// getBoolEnv reads a boolean from the environment, falling back to def
+func getBoolEnv(key string, def bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Error converting %s to bool, using %t: %v", key, def, err)
+		return def
+	}
+	return enabled
+}
+
+// getDurationEnv reads a duration such as "10s" from the environment, falling back to def
+func getDurationEnv(key string, def time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Error parsing %s as duration, using %s: %v", key, def, err)
+		return def
+	}
+	return duration
+}
+
+// getListEnv reads a comma separated list from the environment
+func getListEnv(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getListEnvDefault reads a comma separated list from the environment, using def when it is not set
+func getListEnvDefault(key string, def []string) []string {
+	if values := getListEnv(key); len(values) > 0 {
+		return values
+	}
+	return def
+}
+
+// getDurationMapEnv reads a list like "get_all_floras=20s,get_flora_by_id=5s" from the environment
+func getDurationMapEnv(key string) map[string]time.Duration {
+	durations := map[string]time.Duration{}
+
+	for _, entry := range strings.Split(os.Getenv(key), ",") {
+		name, value, found := strings.Cut(strings.TrimSpace(entry), "=")
+		if !found {
+			continue
+		}
+
+		duration, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			log.Printf("Error parsing %s entry %q as duration: %v", key, entry, err)
+			continue
+		}
+		durations[strings.TrimSpace(name)] = duration
+	}
+
+	return durations
+}
@@ -26,6 +26,18 @@ type ActuatorHandler interface {
 	Health(c *fiber.Ctx) error
 	QueueHealth(c *fiber.Ctx) error
 	RabbitMQHealth(c *fiber.Ctx) error
+	RPCMetrics(c *fiber.Ctx) error
+}
+
+// HealthResponse is the overall health status with the circuit breaker state of every queue
+type HealthResponse struct {
+	Status   string                            `json:"status"`
+	Circuits map[string]rabbitmq.BreakerStatus `json:"circuits"`
+}
+
+// RPCMetricsResponse holds the request coalescing counters of every queue
+type RPCMetricsResponse struct {
+	Queues map[string]rabbitmq.CoalescingStats `json:"queues"`
 }
 
 // actuatorHandler is the concrete implementation of ActuatorHandler
@@ -41,22 +53,33 @@ func NewActuatorHandler(service ActuatorService, rmqHandlers []*rabbitmq.Handler
 
 // Health handler for entire actuator
 // @Summary Get actuator health status
-// @Description Get actuator health status based on queue and RabbitMQ statuses
+// @Description Get actuator health status based on queue and RabbitMQ statuses.
+// @Description The status is Degraded while the circuit breaker of a queue is open or half-open.
 // @Tags Actuator
 // @Produce json
-// @Success 200 {object} common.SuccessResponse
-// @Failure 500 {object} common.ErrorResponse
+// @Success 200 {object} HealthResponse
+// @Failure 500 {object} common.Problem
 // @Router /actuator/health [get]
 func (h *actuatorHandler) Health(c *fiber.Ctx) error {
 	// Iterate over the list of RabbitMQ handlers and check their statuses
 	for _, rmqHandler := range h.rmqHandlers {
 		if err := rmqHandler.CheckQueueStatusHandler(c); err != nil {
-			return c.Status(500).JSON(common.ErrorResponse{Error: "Queue is not reachable"})
+			return &fiber.Error{Code: fiber.StatusInternalServerError, Message: "Queue is not reachable"}
 		} else if err := rmqHandler.CheckRabbitMQStatusHandler(c); err != nil {
-			return c.Status(500).JSON(common.ErrorResponse{Error: "RabbitMQ is not reachable"})
+			return &fiber.Error{Code: fiber.StatusInternalServerError, Message: "RabbitMQ is not reachable"}
+		}
+	}
+
+	// Open circuits mean a consumer is failing, the queues themselves are still reachable
+	response := HealthResponse{Status: "Healthy", Circuits: map[string]rabbitmq.BreakerStatus{}}
+	for _, rmqHandler := range h.rmqHandlers {
+		status := rmqHandler.BreakerStatus()
+		if status.State != rabbitmq.BreakerClosed {
+			response.Status = "Degraded"
 		}
+		response.Circuits[rmqHandler.QueueName()] = status
 	}
-	return c.JSON(common.SuccessResponse{Status: "Healthy"})
+	return c.JSON(response)
 }
 
 // Health handler for the queue
@@ -64,7 +87,7 @@ func (h *actuatorHandler) QueueHealth(c *fiber.Ctx) error {
 	// Check the status of all RabbitMQ handlers for the queue
 	for _, rmqHandler := range h.rmqHandlers {
 		if err := rmqHandler.CheckQueueStatusHandler(c); err != nil {
-			return c.Status(500).JSON(common.ErrorResponse{Error: "Queue is not reachable"})
+			return &fiber.Error{Code: fiber.StatusInternalServerError, Message: "Queue is not reachable"}
 		}
 	}
 	return c.JSON(common.SuccessResponse{Status: "Queue is reachable and healthy"})
@@ -75,12 +98,27 @@ func (h *actuatorHandler) RabbitMQHealth(c *fiber.Ctx) error {
 	// Check the status of all RabbitMQ handlers
 	for _, rmqHandler := range h.rmqHandlers {
 		if err := rmqHandler.CheckRabbitMQStatusHandler(c); err != nil {
-			return c.Status(500).JSON(common.ErrorResponse{Error: "RabbitMQ is not reachable"})
+			return &fiber.Error{Code: fiber.StatusInternalServerError, Message: "RabbitMQ is not reachable"}
 		}
 	}
 	return c.JSON(common.SuccessResponse{Status: "RabbitMQ is running and healthy"})
 }
 
+// RPCMetrics handler for the RPC request coalescing
+// @Summary Get RPC coalescing metrics
+// @Description Get how many requests of every queue shared the RPC of an identical request in flight.
+// @Tags Actuator
+// @Produce json
+// @Success 200 {object} RPCMetricsResponse
+// @Router /actuator/metrics/rpc [get]
+func (h *actuatorHandler) RPCMetrics(c *fiber.Ctx) error {
+	response := RPCMetricsResponse{Queues: map[string]rabbitmq.CoalescingStats{}}
+	for _, rmqHandler := range h.rmqHandlers {
+		response.Queues[rmqHandler.QueueName()] = rmqHandler.CoalescingStats()
+	}
+	return c.JSON(response)
+}
+
 // ActuatorRouter registers actuator-related routes
 func ActuatorRouter(router fiber.Router, rmqHandlers []*rabbitmq.Handler) {
 	service := NewActuatorService()
@@ -89,4 +127,5 @@ func ActuatorRouter(router fiber.Router, rmqHandlers []*rabbitmq.Handler) {
 	router.Get("/health", handler.Health)
 	router.Get("/health/queue", handler.QueueHealth)
 	router.Get("/health/rabbitmq", handler.RabbitMQHealth)
+	router.Get("/metrics/rpc", handler.RPCMetrics)
 }
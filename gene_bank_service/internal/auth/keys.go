@@ -0,0 +1,292 @@
+//	Copyright 2025 Naveen R
+//
+//		Licensed under the Apache License, Version 2.0 (the "License");
+//		you may not use this file except in compliance with the License.
+//		You may obtain a copy of the License at
+//
+//		http://www.apache.org/licenses/LICENSE-2.0
+//
+//		Unless required by applicable law or agreed to in writing, software
+//		distributed under the License is distributed on an "AS IS" BASIS,
+//		WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//		See the License for the specific language governing permissions and
+//		limitations under the License.
+
+package auth
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// maxJWKSSize limits the size of a JWKS document
+const maxJWKSSize = 1 << 20
+
+// jwk is a single JSON Web Key, only the fields needed for verification are read
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+// verificationKey is a key of a JWKS document
+type verificationKey struct {
+	key interface{} // *rsa.PublicKey, *ecdsa.PublicKey or []byte
+	alg string      // Algorithm the key is restricted to, any of its family if empty
+}
+
+// keySet holds the verification keys loaded from one source
+type keySet struct {
+	secret []byte                     // Shared HMAC key
+	keys   map[string]verificationKey // Keys by kid
+}
+
+// equal reports whether both sets hold the same keys
+func (s keySet) equal(other keySet) bool {
+	if !bytes.Equal(s.secret, other.secret) || len(s.keys) != len(other.keys) {
+		return false
+	}
+	for kid, key := range s.keys {
+		otherKey, ok := other.keys[kid]
+		if !ok || key.alg != otherKey.alg || !equalKeys(key.key, otherKey.key) {
+			return false
+		}
+	}
+	return true
+}
+
+// equalKeys compares two keys of any of the supported types
+func equalKeys(a, b interface{}) bool {
+	switch a := a.(type) {
+	case []byte:
+		b, ok := b.([]byte)
+		return ok && bytes.Equal(a, b)
+	case *rsa.PublicKey:
+		return a.Equal(b)
+	case *ecdsa.PublicKey:
+		return a.Equal(b)
+	}
+	return false
+}
+
+// keySource loads a key set and reports whether it changed since the last load
+type keySource interface {
+	load() (keySet, bool, error)
+}
+
+// loadedKeys remembers the last key set of a source
+type loadedKeys struct {
+	current keySet
+}
+
+// update replaces the remembered key set and reports whether it differs from the previous one
+func (l *loadedKeys) update(set keySet) (keySet, bool) {
+	if set.equal(l.current) {
+		return l.current, false
+	}
+	l.current = set
+	return set, true
+}
+
+// secretFileSource reads a shared key from a file and reloads it when the file changes
+type secretFileSource struct {
+	path    string
+	modTime time.Time
+	loadedKeys
+}
+
+func (s *secretFileSource) load() (keySet, bool, error) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return keySet{}, false, err
+	}
+	if info.ModTime().Equal(s.modTime) {
+		return keySet{}, false, nil
+	}
+
+	content, err := os.ReadFile(s.path)
+	if err != nil {
+		return keySet{}, false, err
+	}
+	secret := strings.TrimSpace(string(content))
+	if secret == "" {
+		return keySet{}, false, fmt.Errorf("shared key file %s is empty", s.path)
+	}
+
+	s.modTime = info.ModTime()
+	set, changed := s.update(keySet{secret: []byte(secret)})
+	return set, changed, nil
+}
+
+// staticSecretSource holds a shared key from the environment
+type staticSecretSource struct {
+	secret string
+	loadedKeys
+}
+
+func (s *staticSecretSource) load() (keySet, bool, error) {
+	set, changed := s.update(keySet{secret: []byte(s.secret)})
+	return set, changed, nil
+}
+
+// jwksFileSource reads a JWKS document from a file and reloads it when the file changes
+type jwksFileSource struct {
+	path    string
+	modTime time.Time
+	loadedKeys
+}
+
+func (s *jwksFileSource) load() (keySet, bool, error) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return keySet{}, false, err
+	}
+	if info.ModTime().Equal(s.modTime) {
+		return keySet{}, false, nil
+	}
+
+	file, err := os.Open(s.path)
+	if err != nil {
+		return keySet{}, false, err
+	}
+	defer file.Close()
+
+	keys, err := parseJWKS(file)
+	if err != nil {
+		return keySet{}, false, err
+	}
+
+	s.modTime = info.ModTime()
+	set, changed := s.update(keySet{keys: keys})
+	return set, changed, nil
+}
+
+// jwksURLSource fetches a JWKS document on every reload
+type jwksURLSource struct {
+	url    string
+	client *http.Client
+	loadedKeys
+}
+
+func (s *jwksURLSource) load() (keySet, bool, error) {
+	res, err := s.client.Get(s.url)
+	if err != nil {
+		return keySet{}, false, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return keySet{}, false, fmt.Errorf("JWKS endpoint returned %d", res.StatusCode)
+	}
+
+	keys, err := parseJWKS(io.LimitReader(res.Body, maxJWKSSize))
+	if err != nil {
+		return keySet{}, false, err
+	}
+	set, changed := s.update(keySet{keys: keys})
+	return set, changed, nil
+}
+
+// parseJWKS decodes the signing keys of a JWKS document
+func parseJWKS(r io.Reader) (map[string]verificationKey, error) {
+	var document struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(r).Decode(&document); err != nil {
+		return nil, fmt.Errorf("invalid JWKS document: %v", err)
+	}
+
+	keys := make(map[string]verificationKey, len(document.Keys))
+	for _, key := range document.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		parsed, err := key.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWK %q: %v", key.Kid, err)
+		}
+		keys[key.Kid] = verificationKey{key: parsed, alg: key.Alg}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS document has no signing keys")
+	}
+	return keys, nil
+}
+
+// publicKey converts the JWK into a key usable by the JWT library
+func (k jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() < 3 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("EC point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "oct":
+		secret, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil || len(secret) == 0 {
+			return nil, errors.New("invalid symmetric key")
+		}
+		return secret, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+// decodeBigInt decodes a base64url encoded big-endian integer
+func decodeBigInt(value string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(raw) == 0 {
+		return nil, errors.New("invalid base64url integer")
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
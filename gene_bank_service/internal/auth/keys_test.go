@@ -0,0 +1,210 @@
+//	Copyright 2025 Naveen R
+//
+//		Licensed under the Apache License, Version 2.0 (the "License");
+//		you may not use this file except in compliance with the License.
+//		You may obtain a copy of the License at
+//
+//		http://www.apache.org/licenses/LICENSE-2.0
+//
+//		Unless required by applicable law or agreed to in writing, software
+//		distributed under the License is distributed on an "AS IS" BASIS,
+//		WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//		See the License for the specific language governing permissions and
+//		limitations under the License.
+
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var b64 = base64.RawURLEncoding.EncodeToString
+
+func rsaJWK(t *testing.T, kid, alg string, key *rsa.PublicKey) map[string]string {
+	t.Helper()
+	return map[string]string{"kty": "RSA", "kid": kid, "alg": alg, "n": b64(key.N.Bytes()), "e": b64(big.NewInt(int64(key.E)).Bytes())}
+}
+
+func ecJWK(t *testing.T, kid, alg string, key *ecdsa.PublicKey) map[string]string {
+	t.Helper()
+	return map[string]string{"kty": "EC", "kid": kid, "alg": alg, "crv": key.Curve.Params().Name, "x": b64(key.X.Bytes()), "y": b64(key.Y.Bytes())}
+}
+
+func jwksDocument(t *testing.T, keys ...map[string]string) string {
+	t.Helper()
+	document, err := json.Marshal(map[string]interface{}{"keys": keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(document)
+}
+
+func TestParseJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	offCurve := ecJWK(t, "ec", "", &ecKey.PublicKey)
+	offCurve["y"] = b64([]byte{1})
+	encryption := rsaJWK(t, "enc", "", &rsaKey.PublicKey)
+	encryption["use"] = "enc"
+
+	tests := []struct {
+		name     string
+		document string
+		wantKids []string
+		wantErr  string
+	}{
+		{
+			name:     "RSA, EC and symmetric keys",
+			document: jwksDocument(t, rsaJWK(t, "rsa", "RS256", &rsaKey.PublicKey), ecJWK(t, "ec", "ES256", &ecKey.PublicKey), map[string]string{"kty": "oct", "kid": "hmac", "k": b64([]byte("secret"))}),
+			wantKids: []string{"rsa", "ec", "hmac"},
+		},
+		{
+			name:     "encryption keys are skipped",
+			document: jwksDocument(t, encryption, rsaJWK(t, "sig", "", &rsaKey.PublicKey)),
+			wantKids: []string{"sig"},
+		},
+		{name: "invalid JSON", document: "{", wantErr: "invalid JWKS document"},
+		{name: "no signing keys", document: jwksDocument(t, encryption), wantErr: "no signing keys"},
+		{name: "unsupported key type", document: jwksDocument(t, map[string]string{"kty": "OKP", "kid": "okp"}), wantErr: "unsupported key type"},
+		{name: "unsupported curve", document: jwksDocument(t, map[string]string{"kty": "EC", "kid": "ec", "crv": "P-192", "x": "AQ", "y": "AQ"}), wantErr: "unsupported curve"},
+		{name: "point off the curve", document: jwksDocument(t, offCurve), wantErr: "not on the curve"},
+		{name: "small RSA exponent", document: jwksDocument(t, map[string]string{"kty": "RSA", "kid": "rsa", "n": b64(rsaKey.N.Bytes()), "e": b64([]byte{1})}), wantErr: "invalid RSA exponent"},
+		{name: "invalid base64", document: jwksDocument(t, map[string]string{"kty": "RSA", "kid": "rsa", "n": "!!", "e": "AQAB"}), wantErr: "invalid base64url integer"},
+		{name: "empty symmetric key", document: jwksDocument(t, map[string]string{"kty": "oct", "kid": "hmac"}), wantErr: "invalid symmetric key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := parseJWKS(strings.NewReader(tt.document))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(keys) != len(tt.wantKids) {
+				t.Fatalf("got %d keys, want %v", len(keys), tt.wantKids)
+			}
+			for _, kid := range tt.wantKids {
+				if _, ok := keys[kid]; !ok {
+					t.Fatalf("key %q missing", kid)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadReportsOnlyChangedKeys(t *testing.T) {
+	first, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	firstDocument := jwksDocument(t, rsaJWK(t, "key", "RS256", &first.PublicKey))
+	secondDocument := jwksDocument(t, rsaJWK(t, "key", "RS256", &second.PublicKey))
+
+	dir := t.TempDir()
+	secretPath := filepath.Join(dir, "secret")
+	jwksPath := filepath.Join(dir, "jwks.json")
+
+	// write replaces a file and moves its modification time forward, so the sources read it again
+	modTime := time.Now()
+	write := func(path, content string) {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		modTime = modTime.Add(time.Second)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	document := firstDocument
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(document))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		source keySource
+		before func()
+		update func()
+	}{
+		{
+			name:   "secret file",
+			source: &secretFileSource{path: secretPath},
+			before: func() { write(secretPath, "first") },
+			update: func() { write(secretPath, "second") },
+		},
+		{
+			name:   "JWKS file",
+			source: &jwksFileSource{path: jwksPath},
+			before: func() { write(jwksPath, firstDocument) },
+			update: func() { write(jwksPath, secondDocument) },
+		},
+		{
+			name:   "JWKS URL",
+			source: &jwksURLSource{url: server.URL, client: server.Client()},
+			before: func() { document = firstDocument },
+			update: func() { document = secondDocument },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before()
+			if _, changed, err := tt.source.load(); err != nil || !changed {
+				t.Fatalf("first load: changed %t, error %v", changed, err)
+			}
+
+			// The same keys again, rewritten in place for the file sources
+			tt.before()
+			if _, changed, err := tt.source.load(); err != nil || changed {
+				t.Fatalf("reload of the same keys: changed %t, error %v", changed, err)
+			}
+
+			tt.update()
+			set, changed, err := tt.source.load()
+			if err != nil || !changed {
+				t.Fatalf("reload of new keys: changed %t, error %v", changed, err)
+			}
+			if set.equal(keySet{}) {
+				t.Fatal("reload returned an empty key set")
+			}
+		})
+	}
+
+	static := &staticSecretSource{secret: "secret"}
+	if _, changed, _ := static.load(); !changed {
+		t.Fatal("first load of the static secret reported no change")
+	}
+	if _, changed, _ := static.load(); changed {
+		t.Fatal("static secret reported a change on reload")
+	}
+}
@@ -0,0 +1,330 @@
+//	Copyright 2025 Naveen R
+//
+//		Licensed under the Apache License, Version 2.0 (the "License");
+//		you may not use this file except in compliance with the License.
+//		You may obtain a copy of the License at
+//
+//		http://www.apache.org/licenses/LICENSE-2.0
+//
+//		Unless required by applicable law or agreed to in writing, software
+//		distributed under the License is distributed on an "AS IS" BASIS,
+//		WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//		See the License for the specific language governing permissions and
+//		limitations under the License.
+
+package auth
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Keys of the verified claims in c.Locals
+const (
+	LocalSubject  = "auth.subject"
+	LocalUserID   = "auth.user_id"
+	LocalRoles    = "auth.roles"
+	localEnforced = "auth.enforced"
+)
+
+// Headers set by the gateway, only trusted while the middleware is disabled
+const (
+	userIDHeader = "X-Auth-UserId"
+	roleHeader   = "X-Auth-Role"
+)
+
+const (
+	defaultReloadInterval = time.Minute
+	clockLeeway           = 30 * time.Second
+)
+
+// Config selects where the verification keys come from
+type Config struct {
+	Secret         string        // Shared HMAC key
+	SecretFile     string        // File holding the shared HMAC key, takes precedence over Secret
+	JWKSURL        string        // URL of a JWKS document
+	JWKSFile       string        // File holding a JWKS document
+	ReloadInterval time.Duration // How often the key files and the JWKS URL are checked for changes
+	Issuer         string        // Expected iss claim, not checked if empty
+	Audience       string        // Expected aud claim, not checked if empty
+}
+
+// Verifier checks bearer tokens against the configured keys and keeps them up to date
+type Verifier struct {
+	sources []keySource
+	parser  *jwt.Parser
+
+	mu   sync.RWMutex
+	sets []keySet
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewVerifier loads the configured keys and starts reloading them in the background
+func NewVerifier(config Config) (*Verifier, error) {
+	var sources []keySource
+	switch {
+	case config.SecretFile != "":
+		sources = append(sources, &secretFileSource{path: config.SecretFile})
+	case config.Secret != "":
+		sources = append(sources, &staticSecretSource{secret: config.Secret})
+	}
+	if config.JWKSFile != "" {
+		sources = append(sources, &jwksFileSource{path: config.JWKSFile})
+	}
+	if config.JWKSURL != "" {
+		sources = append(sources, &jwksURLSource{url: config.JWKSURL, client: &http.Client{Timeout: 10 * time.Second}})
+	}
+	if len(sources) == 0 {
+		return nil, errors.New("no JWT key configured, set JWT_SECRET, JWT_SECRET_FILE, JWT_JWKS_FILE or JWT_JWKS_URL")
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"HS256", "HS384", "HS512", "RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(clockLeeway),
+	}
+	if config.Issuer != "" {
+		options = append(options, jwt.WithIssuer(config.Issuer))
+	}
+	if config.Audience != "" {
+		options = append(options, jwt.WithAudience(config.Audience))
+	}
+
+	verifier := &Verifier{
+		sources: sources,
+		parser:  jwt.NewParser(options...),
+		sets:    make([]keySet, len(sources)),
+		done:    make(chan struct{}),
+	}
+
+	// The first load has to succeed, a verifier without keys would reject every request
+	for i, source := range sources {
+		set, _, err := source.load()
+		if err != nil {
+			return nil, err
+		}
+		verifier.sets[i] = set
+	}
+
+	interval := config.ReloadInterval
+	if interval <= 0 {
+		interval = defaultReloadInterval
+	}
+	go verifier.reload(interval)
+
+	return verifier, nil
+}
+
+// Close stops reloading the keys
+func (v *Verifier) Close() {
+	v.closeOnce.Do(func() {
+		close(v.done)
+	})
+}
+
+// reload refreshes every key source, keeping the previous keys when a reload fails
+func (v *Verifier) reload(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-v.done:
+			return
+		case <-ticker.C:
+		}
+
+		for i, source := range v.sources {
+			set, changed, err := source.load()
+			if err != nil {
+				log.Printf("Failed to reload JWT keys, keeping the previous ones: %v", err)
+				continue
+			}
+			if changed {
+				v.mu.Lock()
+				v.sets[i] = set
+				v.mu.Unlock()
+				log.Println("JWT verification keys reloaded")
+			}
+		}
+	}
+}
+
+// keyFunc picks the keys matching the algorithm family and kid of the token, keys restricted to
+// another algorithm by their JWK alg are skipped
+func (v *Verifier) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	var candidates []jwt.VerificationKey
+	for _, set := range v.sets {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok && set.secret != nil {
+			candidates = append(candidates, set.secret)
+		}
+		for keyID, key := range set.keys {
+			if kid != "" && keyID != kid {
+				continue
+			}
+			if key.alg != "" && key.alg != token.Method.Alg() {
+				continue
+			}
+			// Only hand out keys of the token's family, so an RSA key is never used as an HMAC secret
+			switch token.Method.(type) {
+			case *jwt.SigningMethodHMAC:
+				if secret, ok := key.key.([]byte); ok {
+					candidates = append(candidates, secret)
+				}
+			case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS, *jwt.SigningMethodECDSA:
+				if _, ok := key.key.([]byte); !ok {
+					candidates = append(candidates, key.key)
+				}
+			}
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return nil, errors.New("no verification key for token")
+	case 1:
+		return candidates[0], nil
+	}
+	return jwt.VerificationKeySet{Keys: candidates}, nil
+}
+
+// Verify parses and validates a bearer token and returns its claims
+func (v *Verifier) Verify(token string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	if _, err := v.parser.ParseWithClaims(token, claims, v.keyFunc); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// Middleware verifies the Authorization: Bearer token and stores its subject, user ID and roles in c.Locals.
+// Reads without a token continue anonymously, every other request needs a valid token.
+func Middleware(verifier *Verifier) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(localEnforced, true)
+
+		header := c.Get(fiber.HeaderAuthorization)
+		if header == "" {
+			if c.Method() == fiber.MethodGet || c.Method() == fiber.MethodHead || c.Method() == fiber.MethodOptions {
+				return c.Next()
+			}
+			return unauthorized(c, "Missing bearer token")
+		}
+
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || token == "" {
+			return unauthorized(c, "Authorization header must use the Bearer scheme")
+		}
+
+		claims, err := verifier.Verify(token)
+		if err != nil {
+			return unauthorized(c, "Invalid bearer token")
+		}
+
+		subject, _ := claims.GetSubject()
+		userID, _ := claims["userId"].(string)
+		if userID == "" {
+			userID = subject
+		}
+		if userID == "" {
+			return unauthorized(c, "Token has no subject")
+		}
+
+		c.Locals(LocalSubject, subject)
+		c.Locals(LocalUserID, userID)
+		c.Locals(LocalRoles, rolesFromClaims(claims))
+		return c.Next()
+	}
+}
+
+// unauthorized rejects the request with a bearer challenge
+func unauthorized(c *fiber.Ctx, message string) error {
+	c.Set(fiber.HeaderWWWAuthenticate, `Bearer realm="gene-bank"`)
+	return &fiber.Error{Code: fiber.StatusUnauthorized, Message: message}
+}
+
+// rolesFromClaims reads the role (auth service) or roles claim
+func rolesFromClaims(claims jwt.MapClaims) []string {
+	var roles []string
+	if role, ok := claims["role"].(string); ok && role != "" {
+		roles = append(roles, role)
+	}
+	switch value := claims["roles"].(type) {
+	case string:
+		roles = append(roles, strings.Fields(strings.ReplaceAll(value, ",", " "))...)
+	case []interface{}:
+		for _, role := range value {
+			if role, ok := role.(string); ok && role != "" {
+				roles = append(roles, role)
+			}
+		}
+	}
+	return roles
+}
+
+// enforced reports whether the middleware ran for this request
+func enforced(c *fiber.Ctx) bool {
+	value, _ := c.Locals(localEnforced).(bool)
+	return value
+}
+
+// UserID returns the verified user ID, or the gateway header when the middleware is disabled
+func UserID(c *fiber.Ctx) string {
+	if enforced(c) {
+		userID, _ := c.Locals(LocalUserID).(string)
+		return userID
+	}
+	return c.Get(userIDHeader)
+}
+
+// VerifiedUserID returns the user ID of a verified token, and nothing when the middleware is
+// disabled, the gateway header is then set by the client and can not be trusted
+func VerifiedUserID(c *fiber.Ctx) string {
+	if !enforced(c) {
+		return ""
+	}
+	userID, _ := c.Locals(LocalUserID).(string)
+	return userID
+}
+
+// Subject returns the verified subject of the token
+func Subject(c *fiber.Ctx) string {
+	subject, _ := c.Locals(LocalSubject).(string)
+	return subject
+}
+
+// Roles returns the verified roles, or the gateway header when the middleware is disabled
+func Roles(c *fiber.Ctx) []string {
+	if enforced(c) {
+		roles, _ := c.Locals(LocalRoles).([]string)
+		return roles
+	}
+	if role := c.Get(roleHeader); role != "" {
+		return []string{role}
+	}
+	return nil
+}
+
+// HasRole reports whether the caller has the role, compared case-insensitively
+func HasRole(c *fiber.Ctx, role string) bool {
+	for _, r := range Roles(c) {
+		if strings.EqualFold(strings.TrimPrefix(r, "ROLE_"), role) {
+			return true
+		}
+	}
+	return false
+}
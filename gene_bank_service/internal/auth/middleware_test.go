@@ -0,0 +1,187 @@
+//	Copyright 2025 Naveen R
+//
+//		Licensed under the Apache License, Version 2.0 (the "License");
+//		you may not use this file except in compliance with the License.
+//		You may obtain a copy of the License at
+//
+//		http://www.apache.org/licenses/LICENSE-2.0
+//
+//		Unless required by applicable law or agreed to in writing, software
+//		distributed under the License is distributed on an "AS IS" BASIS,
+//		WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//		See the License for the specific language governing permissions and
+//		limitations under the License.
+
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// sign creates a token for user-1 with the given expiry, signed by key and naming kid in its header
+func sign(t *testing.T, method jwt.SigningMethod, key interface{}, kid string, expiry time.Duration) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, jwt.MapClaims{"sub": "user-1", "exp": time.Now().Add(expiry).Unix()})
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestVerify(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherRSAKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwksPath := filepath.Join(t.TempDir(), "jwks.json")
+	document := jwksDocument(t,
+		rsaJWK(t, "rsa", "RS256", &rsaKey.PublicKey),
+		rsaJWK(t, "other", "", &otherRSAKey.PublicKey),
+		ecJWK(t, "ec", "ES256", &ecKey.PublicKey),
+	)
+	if err := os.WriteFile(jwksPath, []byte(document), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	verifier, err := NewVerifier(Config{Secret: "shared-secret", JWKSFile: jwksPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer verifier.Close()
+
+	publicKeyBytes := rsaKey.PublicKey.N.Bytes()
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{name: "HS256 with the shared secret", token: sign(t, jwt.SigningMethodHS256, []byte("shared-secret"), "", time.Hour), valid: true},
+		{name: "HS256 with another secret", token: sign(t, jwt.SigningMethodHS256, []byte("other-secret"), "", time.Hour)},
+		{name: "RS256 from the JWKS", token: sign(t, jwt.SigningMethodRS256, rsaKey, "rsa", time.Hour), valid: true},
+		{name: "RS256 without kid", token: sign(t, jwt.SigningMethodRS256, rsaKey, "", time.Hour), valid: true},
+		{name: "ES256 from the JWKS", token: sign(t, jwt.SigningMethodES256, ecKey, "ec", time.Hour), valid: true},
+		{name: "key without alg accepts its family", token: sign(t, jwt.SigningMethodPS256, otherRSAKey, "other", time.Hour), valid: true},
+		{name: "unknown kid", token: sign(t, jwt.SigningMethodRS256, rsaKey, "missing", time.Hour)},
+		{name: "kid of another key", token: sign(t, jwt.SigningMethodRS256, rsaKey, "other", time.Hour)},
+		{name: "alg other than the one of the JWK", token: sign(t, jwt.SigningMethodRS512, rsaKey, "rsa", time.Hour)},
+		{name: "RSA-PSS with a key restricted to RS256", token: sign(t, jwt.SigningMethodPS256, rsaKey, "rsa", time.Hour)},
+		{name: "HMAC signed with the RSA public key", token: sign(t, jwt.SigningMethodHS256, publicKeyBytes, "rsa", time.Hour)},
+		{name: "alg none", token: sign(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "", time.Hour)},
+		{name: "expired beyond the leeway", token: sign(t, jwt.SigningMethodHS256, []byte("shared-secret"), "", -time.Minute)},
+		{name: "expired within the leeway", token: sign(t, jwt.SigningMethodHS256, []byte("shared-secret"), "", -10*time.Second), valid: true},
+		{name: "malformed", token: "not.a.token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := verifier.Verify(tt.token)
+			if tt.valid {
+				if err != nil {
+					t.Fatalf("got %v, want a valid token", err)
+				}
+				if subject, _ := claims.GetSubject(); subject != "user-1" {
+					t.Fatalf("subject = %q, want user-1", subject)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("token was accepted")
+			}
+		})
+	}
+}
+
+func TestVerifyRequiresExpiry(t *testing.T) {
+	verifier, err := NewVerifier(Config{Secret: "shared-secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer verifier.Close()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "user-1"}).SignedString([]byte("shared-secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verifier.Verify(token); err == nil {
+		t.Fatal("token without exp was accepted")
+	}
+}
+
+func TestVerifierReloadsRotatedKeys(t *testing.T) {
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	document := jwksDocument(t, rsaJWK(t, "old", "RS256", &oldKey.PublicKey))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Write([]byte(document))
+	}))
+	defer server.Close()
+
+	verifier, err := NewVerifier(Config{JWKSURL: server.URL, ReloadInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer verifier.Close()
+
+	oldToken := sign(t, jwt.SigningMethodRS256, oldKey, "old", time.Hour)
+	newToken := sign(t, jwt.SigningMethodRS256, newKey, "new", time.Hour)
+	if _, err := verifier.Verify(oldToken); err != nil {
+		t.Fatalf("token of the initial key: %v", err)
+	}
+	if _, err := verifier.Verify(newToken); err == nil {
+		t.Fatal("token of a key not published yet was accepted")
+	}
+
+	mu.Lock()
+	document = jwksDocument(t, rsaJWK(t, "new", "RS256", &newKey.PublicKey))
+	mu.Unlock()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, err := verifier.Verify(newToken); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("rotated key was never loaded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := verifier.Verify(oldToken); err == nil {
+		t.Fatal("token of the retired key is still accepted")
+	}
+}
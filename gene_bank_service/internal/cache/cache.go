@@ -0,0 +1,159 @@
+//	Copyright 2025 Naveen R
+//
+//		Licensed under the Apache License, Version 2.0 (the "License");
+//		you may not use this file except in compliance with the License.
+//		You may obtain a copy of the License at
+//
+//		http://www.apache.org/licenses/LICENSE-2.0
+//
+//		Unless required by applicable law or agreed to in writing, software
+//		distributed under the License is distributed on an "AS IS" BASIS,
+//		WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//		See the License for the specific language governing permissions and
+//		limitations under the License.
+
+package cache
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Entry is a cached value and the time it was stored
+type Entry struct {
+	Value    []byte
+	StoredAt time.Time
+}
+
+// Cache stores encoded values by key. Values are bytes so that a shared
+// store such as Redis can implement it without knowing the cached types.
+type Cache interface {
+	// Get returns the entry of a key, or false if it is unknown or expired
+	Get(key string) (Entry, bool)
+	// Set stores a value under the key
+	Set(key string, value []byte)
+	// Delete removes the keys
+	Delete(keys ...string)
+	// DeletePrefix removes every key starting with the prefix
+	DeletePrefix(prefix string)
+}
+
+// Noop is a cache that stores nothing, used when caching is disabled
+type Noop struct{}
+
+func (Noop) Get(string) (Entry, bool) { return Entry{}, false }
+func (Noop) Set(string, []byte)       {}
+func (Noop) Delete(...string)         {}
+func (Noop) DeletePrefix(string)      {}
+
+const (
+	defaultTTL        = 5 * time.Minute
+	defaultMaxEntries = 1000
+)
+
+type lruEntry struct {
+	key   string
+	entry Entry
+}
+
+// LRU is an in-process cache evicting the least recently used entries once
+// maxEntries or maxBytes is reached, entries expire after the TTL
+type LRU struct {
+	ttl        time.Duration
+	maxEntries int
+	maxBytes   int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List // Most recently used first
+	size    int        // Total size of the cached values
+}
+
+// NewLRU creates an LRU cache, a maxBytes of 0 only limits the number of entries
+func NewLRU(ttl time.Duration, maxEntries int, maxBytes int) *LRU {
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+	if maxEntries <= 0 {
+		maxEntries = defaultMaxEntries
+	}
+
+	return &LRU{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+func (l *LRU) Get(key string) (Entry, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	element, ok := l.entries[key]
+	if !ok {
+		return Entry{}, false
+	}
+
+	cached := element.Value.(*lruEntry)
+	if time.Since(cached.entry.StoredAt) > l.ttl {
+		l.remove(element)
+		return Entry{}, false
+	}
+
+	l.order.MoveToFront(element)
+	return cached.entry, true
+}
+
+func (l *LRU) Set(key string, value []byte) {
+	// Values larger than the whole cache would only evict everything else
+	if l.maxBytes > 0 && len(value) > l.maxBytes {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if element, ok := l.entries[key]; ok {
+		l.remove(element)
+	}
+
+	l.entries[key] = l.order.PushFront(&lruEntry{key: key, entry: Entry{Value: value, StoredAt: time.Now()}})
+	l.size += len(value)
+
+	for l.order.Len() > l.maxEntries || (l.maxBytes > 0 && l.size > l.maxBytes) {
+		l.remove(l.order.Back())
+	}
+}
+
+func (l *LRU) Delete(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, key := range keys {
+		if element, ok := l.entries[key]; ok {
+			l.remove(element)
+		}
+	}
+}
+
+func (l *LRU) DeletePrefix(prefix string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, element := range l.entries {
+		if strings.HasPrefix(key, prefix) {
+			l.remove(element)
+		}
+	}
+}
+
+func (l *LRU) remove(element *list.Element) {
+	cached := element.Value.(*lruEntry)
+	l.order.Remove(element)
+	delete(l.entries, cached.key)
+	l.size -= len(cached.entry.Value)
+}
@@ -0,0 +1,144 @@
+//	Copyright 2025 Naveen R
+//
+//		Licensed under the Apache License, Version 2.0 (the "License");
+//		you may not use this file except in compliance with the License.
+//		You may obtain a copy of the License at
+//
+//		http://www.apache.org/licenses/LICENSE-2.0
+//
+//		Unless required by applicable law or agreed to in writing, software
+//		distributed under the License is distributed on an "AS IS" BASIS,
+//		WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//		See the License for the specific language governing permissions and
+//		limitations under the License.
+
+package cache
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func keys(l *LRU) []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var keys []string
+	for element := l.order.Front(); element != nil; element = element.Next() {
+		keys = append(keys, element.Value.(*lruEntry).key)
+	}
+	return keys
+}
+
+func TestLRUEviction(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxEntries int
+		maxBytes   int
+		run        func(l *LRU)
+		want       []string // Remaining keys, most recently used first
+	}{
+		{
+			name:       "oldest entry goes once maxEntries is reached",
+			maxEntries: 2,
+			run: func(l *LRU) {
+				l.Set("a", []byte("1"))
+				l.Set("b", []byte("2"))
+				l.Set("c", []byte("3"))
+			},
+			want: []string{"c", "b"},
+		},
+		{
+			name:       "reading an entry keeps it",
+			maxEntries: 2,
+			run: func(l *LRU) {
+				l.Set("a", []byte("1"))
+				l.Set("b", []byte("2"))
+				l.Get("a")
+				l.Set("c", []byte("3"))
+			},
+			want: []string{"c", "a"},
+		},
+		{
+			name:       "replacing an entry does not count twice",
+			maxEntries: 2,
+			run: func(l *LRU) {
+				l.Set("a", []byte("1"))
+				l.Set("b", []byte("2"))
+				l.Set("a", []byte("3"))
+			},
+			want: []string{"a", "b"},
+		},
+		{
+			name:       "least recently used entries go once maxBytes is reached",
+			maxEntries: 10,
+			maxBytes:   10,
+			run: func(l *LRU) {
+				l.Set("a", []byte("1234"))
+				l.Set("b", []byte("1234"))
+				l.Get("a")
+				l.Set("c", []byte("1234"))
+			},
+			want: []string{"c", "a"},
+		},
+		{
+			name:       "value larger than the cache is not stored",
+			maxEntries: 10,
+			maxBytes:   10,
+			run: func(l *LRU) {
+				l.Set("a", []byte("1234"))
+				l.Set("b", []byte("12345678901"))
+			},
+			want: []string{"a"},
+		},
+		{
+			name:       "prefix deletion",
+			maxEntries: 10,
+			run: func(l *LRU) {
+				l.Set("flora:1", []byte("1"))
+				l.Set("flora-list:a", []byte("2"))
+				l.Set("flora-list:b", []byte("3"))
+				l.DeletePrefix("flora-list:")
+				l.Delete("missing")
+			},
+			want: []string{"flora:1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLRU(time.Minute, tt.maxEntries, tt.maxBytes)
+			tt.run(l)
+
+			if got := keys(l); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("keys = %v, want %v", got, tt.want)
+			}
+			size := 0
+			for _, key := range tt.want {
+				entry, _ := l.Get(key)
+				size += len(entry.Value)
+			}
+			if l.size != size {
+				t.Fatalf("size = %d, want %d", l.size, size)
+			}
+		})
+	}
+}
+
+func TestLRUExpiry(t *testing.T) {
+	l := NewLRU(20*time.Millisecond, 10, 0)
+	l.Set("a", []byte("1"))
+
+	if entry, ok := l.Get("a"); !ok || string(entry.Value) != "1" {
+		t.Fatalf("got (%q, %t), want the fresh entry", entry.Value, ok)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if _, ok := l.Get("a"); ok {
+		t.Fatal("expired entry was returned")
+	}
+	if len(keys(l)) != 0 || l.size != 0 {
+		t.Fatal("expired entry was not removed")
+	}
+}
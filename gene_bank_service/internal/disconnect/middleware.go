@@ -0,0 +1,41 @@
+//	Copyright 2025 Naveen R
+//
+//		Licensed under the Apache License, Version 2.0 (the "License");
+//		you may not use this file except in compliance with the License.
+//		You may obtain a copy of the License at
+//
+//		http://www.apache.org/licenses/LICENSE-2.0
+//
+//		Unless required by applicable law or agreed to in writing, software
+//		distributed under the License is distributed on an "AS IS" BASIS,
+//		WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//		See the License for the specific language governing permissions and
+//		limitations under the License.
+
+package disconnect
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Middleware cancels the user context of a request when its client disconnects or the server
+// shuts down, so RPC calls and image fetches made on behalf of the request stop with it
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithCancel(c.UserContext())
+
+		// The fasthttp request context is only done when the server shuts down
+		stopShutdown := context.AfterFunc(c.Context(), cancel)
+		stopWatch := watch(c.Context().Conn(), cancel)
+		defer func() {
+			stopWatch()
+			stopShutdown()
+			cancel()
+		}()
+
+		c.SetUserContext(ctx)
+		return c.Next()
+	}
+}
@@ -0,0 +1,24 @@
+//	Copyright 2025 Naveen R
+//
+//		Licensed under the Apache License, Version 2.0 (the "License");
+//		you may not use this file except in compliance with the License.
+//		You may obtain a copy of the License at
+//
+//		http://www.apache.org/licenses/LICENSE-2.0
+//
+//		Unless required by applicable law or agreed to in writing, software
+//		distributed under the License is distributed on an "AS IS" BASIS,
+//		WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//		See the License for the specific language governing permissions and
+//		limitations under the License.
+
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package disconnect
+
+import "net"
+
+// watch is not supported on this platform, requests are only canceled on shutdown
+func watch(conn net.Conn, cancel func()) (stop func()) {
+	return func() {}
+}
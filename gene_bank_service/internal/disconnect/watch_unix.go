@@ -0,0 +1,70 @@
+//	Copyright 2025 Naveen R
+//
+//		Licensed under the Apache License, Version 2.0 (the "License");
+//		you may not use this file except in compliance with the License.
+//		You may obtain a copy of the License at
+//
+//		http://www.apache.org/licenses/LICENSE-2.0
+//
+//		Unless required by applicable law or agreed to in writing, software
+//		distributed under the License is distributed on an "AS IS" BASIS,
+//		WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//		See the License for the specific language governing permissions and
+//		limitations under the License.
+
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package disconnect
+
+import (
+	"errors"
+	"net"
+	"syscall"
+	"time"
+)
+
+// disconnectPollInterval is how often a waiting request checks whether its client went away
+const disconnectPollInterval = 200 * time.Millisecond
+
+// watch calls cancel once the peer of conn closes the connection and returns a function
+// stopping the watch. fasthttp does not report disconnects, so the socket is peeked without blocking:
+// a read of zero bytes means the client is gone, pending bytes of a pipelined request do not.
+// Connections without a file descriptor, such as TLS connections, are not watched.
+func watch(conn net.Conn, cancel func()) (stop func()) {
+	sysConn, ok := conn.(syscall.Conn)
+	if !ok {
+		return func() {}
+	}
+	raw, err := sysConn.SyscallConn()
+	if err != nil {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(disconnectPollInterval)
+		defer ticker.Stop()
+
+		buf := make([]byte, 1)
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			closed := false
+			controlErr := raw.Read(func(fd uintptr) bool {
+				n, _, err := syscall.Recvfrom(int(fd), buf, syscall.MSG_PEEK|syscall.MSG_DONTWAIT)
+				closed = n == 0 && err == nil || err != nil && !errors.Is(err, syscall.EAGAIN) && !errors.Is(err, syscall.EINTR)
+				return true
+			})
+			if closed || controlErr != nil {
+				cancel()
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
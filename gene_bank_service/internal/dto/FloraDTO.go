@@ -13,6 +13,8 @@
 //	limitations under the License.
 package dto
 
+import "time"
+
 type Flora struct {
 	ID             string                 `json:"id,omitempty"`              // Unique identifier for the plant
 	CommonName     string                 `json:"common_name,omitempty"`     // Common name of the plant
@@ -25,15 +27,17 @@ type Flora struct {
 }
 
 type FloraData struct {
-	ID             string                 `json:"id"`              // Unique identifier
-	UserID         string                 `json:"user_id"`         // User associated with this flora
-	CommonName     string                 `json:"common_name"`     // Common name of the plant
-	ScientificName string                 `json:"scientific_name"` // Scientific name of the plant
-	Type           string                 `json:"type"`            // Type of flora (e.g., public/private)
-	Image          string                 `json:"image"`           // Base64-encoded image
-	Description    string                 `json:"description"`     // Description of the plant
-	Origin         string                 `json:"origin"`          // Geographical origin
-	OtherDetails   map[string]interface{} `json:"other_details"`   // Additional details as key-value pairs
+	ID             string                 `json:"id"`                  // Unique identifier
+	UserID         string                 `json:"user_id"`             // User associated with this flora
+	CommonName     string                 `json:"common_name"`         // Common name of the plant
+	ScientificName string                 `json:"scientific_name"`     // Scientific name of the plant
+	Type           string                 `json:"type"`                // Type of flora (e.g., public/private)
+	Image          string                 `json:"image,omitempty"`     // Base64-encoded image
+	Thumbnail      string                 `json:"thumbnail,omitempty"` // Base64-encoded thumbnail
+	ImageURL       string                 `json:"image_url,omitempty"` // Link serving the full image
+	Description    string                 `json:"description"`         // Description of the plant
+	Origin         string                 `json:"origin"`              // Geographical origin
+	OtherDetails   map[string]interface{} `json:"other_details"`       // Additional details as key-value pairs
 }
 
 type Type string
@@ -44,30 +48,60 @@ const (
 )
 
 type FloraResponse struct {
-	Flora []FloraData `json:"flora,omitempty"`
+	Flora        []FloraData `json:"flora,omitempty"`
+	Total        int         `json:"total,omitempty"`       // Total number of records matching the query
+	Page         int         `json:"page,omitempty"`        // Current page number (1 based)
+	Size         int         `json:"size,omitempty"`        // Page size used for the query
+	NextCursor   string      `json:"next_cursor,omitempty"` // Opaque cursor for the next page, empty on the last page
+	Links        *PageLinks  `json:"links,omitempty"`       // Navigation links for the paginated result
+	LastModified time.Time   `json:"-"`                     // When the data was fetched, sent as Last-Modified
+}
+
+type PageLinks struct {
+	Self  string `json:"self"`           // Link to the current page
+	First string `json:"first"`          // Link to the first page
+	Last  string `json:"last"`           // Link to the last page
+	Next  string `json:"next,omitempty"` // Link to the next page
+	Prev  string `json:"prev,omitempty"` // Link to the previous page
+}
+
+// FloraQuery is the structured payload forwarded with get_all_floras
+type FloraQuery struct {
+	Page   int    `json:"page"`              // Page number (1 based)
+	Size   int    `json:"size"`              // Number of records per page
+	Sort   string `json:"sort,omitempty"`    // Field to sort by
+	Order  string `json:"order,omitempty"`   // Sort order (asc/desc)
+	Type   string `json:"type,omitempty"`    // Filter by type of post
+	Origin string `json:"origin,omitempty"`  // Filter by origin
+	UserID string `json:"user_id,omitempty"` // Filter by owner
+	Q      string `json:"q,omitempty"`       // Free text search on the names and description
+	Image  string `json:"image,omitempty"`   // Image size returned in the list (thumbnail/full)
+	// Set for callers without a privileged role, private records are then only returned to their owner Viewer
+	HidePrivate bool   `json:"hide_private,omitempty"`
+	Viewer      string `json:"viewer,omitempty"`
 }
 
 type FloraRequest struct {
-	CommonName     string                 `json:"common_name,omitempty"`     // Common name of the plant
-	ScientificName string                 `json:"scientific_name,omitempty"` // Scientific name of the plant
-	ImageURL       string                 `json:"image_url,omitempty"`       // Image URL or file reference
-	ImagePath      string                 `json:"image_path,omitempty"`      // Image URL or file reference
-	Image          []byte                 `json:"image,omitempty"`           // Image data (bytes)
-	Description    string                 `json:"description,omitempty"`     // Description of the plant
-	Origin         string                 `json:"origin,omitempty"`          // Origin of the plant
-	OtherDetails   map[string]interface{} `json:"other_details,omitempty"`   // Additional details about the plant
-	Type           string                 `json:"type,omitempty"`            // Type of post
+	CommonName     string                 `json:"common_name,omitempty" validate:"required,notblank,max=100"`            // Common name of the plant
+	ScientificName string                 `json:"scientific_name,omitempty" validate:"required,max=150,scientific_name"` // Scientific name of the plant
+	ImageURL       string                 `json:"image_url,omitempty" validate:"omitempty,max=2048,url"`                 // Image URL or file reference
+	ImagePath      string                 `json:"image_path,omitempty" validate:"omitempty,max=255"`                     // Image URL or file reference
+	Image          []byte                 `json:"image,omitempty"`                                                       // Image data (bytes)
+	Description    string                 `json:"description,omitempty" validate:"required,notblank,max=2000"`           // Description of the plant
+	Origin         string                 `json:"origin,omitempty" validate:"required,notblank,max=100"`                 // Origin of the plant
+	OtherDetails   map[string]interface{} `json:"other_details,omitempty" validate:"omitempty,max=50,json_size=8192"`    // Additional details about the plant
+	Type           string                 `json:"type,omitempty" validate:"required,oneof=public private"`               // Type of post
 }
 
 type FloraUpdateRequest struct {
-	ID             string                 `json:"id"`                        // Unique identifier for the plant
-	CommonName     string                 `json:"common_name,omitempty"`     // Common name of the plant
-	ScientificName string                 `json:"scientific_name,omitempty"` // Scientific name of the plant
-	ImageURL       string                 `json:"image_url,omitempty"`       // Image URL or file reference
-	ImagePath      string                 `json:"image_path,omitempty"`      // Image URL or file reference
-	Image          []byte                 `json:"image,omitempty"`           // Image data (bytes)
-	Description    string                 `json:"description,omitempty"`     // Description of the plant
-	Origin         string                 `json:"origin,omitempty"`          // Origin of the plant
-	OtherDetails   map[string]interface{} `json:"other_details,omitempty"`   // Additional details about the plant
-	Type           string                 `json:"type,omitempty"`            // Type of post
+	ID             string                 `json:"id" validate:"required,uuid"`                                            // Unique identifier for the plant
+	CommonName     string                 `json:"common_name,omitempty" validate:"omitempty,notblank,max=100"`            // Common name of the plant
+	ScientificName string                 `json:"scientific_name,omitempty" validate:"omitempty,max=150,scientific_name"` // Scientific name of the plant
+	ImageURL       string                 `json:"image_url,omitempty" validate:"omitempty,max=2048,url"`                  // Image URL or file reference
+	ImagePath      string                 `json:"image_path,omitempty" validate:"omitempty,max=255"`                      // Image URL or file reference
+	Image          []byte                 `json:"image,omitempty"`                                                        // Image data (bytes)
+	Description    string                 `json:"description,omitempty" validate:"omitempty,notblank,max=2000"`           // Description of the plant
+	Origin         string                 `json:"origin,omitempty" validate:"omitempty,notblank,max=100"`                 // Origin of the plant
+	OtherDetails   map[string]interface{} `json:"other_details,omitempty" validate:"omitempty,max=50,json_size=8192"`     // Additional details about the plant
+	Type           string                 `json:"type,omitempty" validate:"omitempty,oneof=public private"`               // Type of post
 }
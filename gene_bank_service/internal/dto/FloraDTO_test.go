@@ -0,0 +1,116 @@
+// Copyright 2025 Naveen R
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package dto_test
+
+import (
+	"errors"
+	"project_chimera/gene_bank_service/internal/dto"
+	"project_chimera/gene_bank_service/pkg/utils"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func validRequest() dto.FloraRequest {
+	return dto.FloraRequest{
+		CommonName:     "Dog rose",
+		ScientificName: "Rosa canina",
+		ImageURL:       "https://example.com/rosa.png",
+		Description:    "A climbing wild rose",
+		Origin:         "Europe",
+		OtherDetails:   map[string]interface{}{"height": "3m"},
+		Type:           "public",
+	}
+}
+
+// failingFields returns the sorted JSON names of the fields rejected by ValidateStruct
+func failingFields(t *testing.T, payload interface{}) []string {
+	t.Helper()
+	err := utils.ValidateStruct(payload)
+	if err == nil {
+		return nil
+	}
+
+	var validationErr *utils.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("got %v, want a *utils.ValidationError", err)
+	}
+	fields := make([]string, 0, len(validationErr.Fields))
+	for _, field := range validationErr.Fields {
+		fields = append(fields, field.Field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func TestFloraRequestValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*dto.FloraRequest)
+		want   []string
+	}{
+		{name: "valid", modify: func(r *dto.FloraRequest) {}},
+		{name: "optional fields left out", modify: func(r *dto.FloraRequest) { r.ImageURL, r.OtherDetails = "", nil }},
+		{name: "hybrid and infraspecific names", modify: func(r *dto.FloraRequest) { r.ScientificName = "Brassica oleracea var. capitata" }},
+		{name: "empty request", modify: func(r *dto.FloraRequest) { *r = dto.FloraRequest{} }, want: []string{"common_name", "description", "origin", "scientific_name", "type"}},
+		{name: "blank names", modify: func(r *dto.FloraRequest) { r.CommonName, r.Origin = " ", "\t" }, want: []string{"common_name", "origin"}},
+		{name: "lower case genus", modify: func(r *dto.FloraRequest) { r.ScientificName = "rosa canina" }, want: []string{"scientific_name"}},
+		{name: "genus only", modify: func(r *dto.FloraRequest) { r.ScientificName = "Rosa" }, want: []string{"scientific_name"}},
+		{name: "scientific name too long", modify: func(r *dto.FloraRequest) { r.ScientificName = "Rosa " + strings.Repeat("a", 150) }, want: []string{"scientific_name"}},
+		{name: "common name too long", modify: func(r *dto.FloraRequest) { r.CommonName = strings.Repeat("a", 101) }, want: []string{"common_name"}},
+		{name: "invalid image URL", modify: func(r *dto.FloraRequest) { r.ImageURL = "not a url" }, want: []string{"image_url"}},
+		{name: "image path too long", modify: func(r *dto.FloraRequest) { r.ImagePath = strings.Repeat("a", 256) }, want: []string{"image_path"}},
+		{name: "unknown type", modify: func(r *dto.FloraRequest) { r.Type = "shared" }, want: []string{"type"}},
+		{name: "other details too large", modify: func(r *dto.FloraRequest) {
+			r.OtherDetails = map[string]interface{}{"notes": strings.Repeat("a", 8192)}
+		}, want: []string{"other_details"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := validRequest()
+			tt.modify(&request)
+
+			if got := failingFields(t, request); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("failing fields = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFloraUpdateRequestValidation(t *testing.T) {
+	const id = "8f14e45f-ceea-467f-a0e6-1c0e6b7a9a10"
+
+	tests := []struct {
+		name    string
+		request dto.FloraUpdateRequest
+		want    []string
+	}{
+		{name: "only the ID", request: dto.FloraUpdateRequest{ID: id}},
+		{name: "partial update", request: dto.FloraUpdateRequest{ID: id, ScientificName: "Mentha × piperita", Type: "private"}},
+		{name: "missing ID", request: dto.FloraUpdateRequest{CommonName: "Dog rose"}, want: []string{"id"}},
+		{name: "ID is not a UUID", request: dto.FloraUpdateRequest{ID: "42"}, want: []string{"id"}},
+		{name: "blank description", request: dto.FloraUpdateRequest{ID: id, Description: "  "}, want: []string{"description"}},
+		{name: "invalid scientific name", request: dto.FloraUpdateRequest{ID: id, ScientificName: "Rosa Canina"}, want: []string{"scientific_name"}},
+		{name: "unknown type", request: dto.FloraUpdateRequest{ID: id, Type: "shared"}, want: []string{"type"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := failingFields(t, tt.request); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("failing fields = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+//	Copyright 2025 Naveen R
+//
+//		Licensed under the Apache License, Version 2.0 (the "License");
+//		you may not use this file except in compliance with the License.
+//		You may obtain a copy of the License at
+//
+//		http://www.apache.org/licenses/LICENSE-2.0
+//
+//		Unless required by applicable law or agreed to in writing, software
+//		distributed under the License is distributed on an "AS IS" BASIS,
+//		WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//		See the License for the specific language governing permissions and
+//		limitations under the License.
+
+package errorevent
+
+import "time"
+
+// Name is the pattern an error event is published with on the error queue
+type Name string
+
+// Event names matched by error_handler_service, see its pkg/models/event.go.
+// Both sides are tested against contracts/error_event.json.
+const (
+	FloraGetAll    Name = "flora.getall"
+	FloraGetByID   Name = "flora.getbyid"
+	FloraGetImage  Name = "flora.getimage"
+	FloraPost      Name = "flora.post"
+	FloraPut       Name = "flora.put"
+	FloraDelete    Name = "flora.delete"
+	FloraRestore   Name = "flora.restore"
+	FloraForbidden Name = "flora.forbidden"
+)
+
+// Data holds the event specific details, "error" carries the error message
+type Data map[string]interface{}
+
+// ErrorEvent is the body of an error event. The code, status, type and data fields
+// keep the layout the error handler already stores; error_handler_service decodes it
+// as models.ErrorEvent.
+type ErrorEvent struct {
+	Code      int             `json:"code"`              // HTTP status returned to the client
+	Status    string          `json:"status"`            // Text of the HTTP status
+	Type      string          `json:"type"`              // HTTP method of the failed request
+	Data      interface{}     `json:"data"`              // Event specific details
+	Service   string          `json:"service,omitempty"` // Service that reported the event
+	Stack     []string        `json:"stack,omitempty"`   // Call stack where the event was reported
+	Request   *RequestContext `json:"request,omitempty"` // Request that caused the event
+	Timestamp time.Time       `json:"timestamp"`         // When the event was reported
+}
+
+// RequestContext describes the HTTP request an error event was reported for
+type RequestContext struct {
+	TraceID   string `json:"trace_id,omitempty"`
+	Method    string `json:"method"`
+	Path      string `json:"path"`
+	UserID    string `json:"user_id,omitempty"`
+	IP        string `json:"ip,omitempty"`
+	UserAgent string `json:"user_agent,omitempty"`
+}
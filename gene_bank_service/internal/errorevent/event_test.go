@@ -0,0 +1,97 @@
+//	Copyright 2025 Naveen R
+//
+//		Licensed under the Apache License, Version 2.0 (the "License");
+//		you may not use this file except in compliance with the License.
+//		You may obtain a copy of the License at
+//
+//		http://www.apache.org/licenses/LICENSE-2.0
+//
+//		Unless required by applicable law or agreed to in writing, software
+//		distributed under the License is distributed on an "AS IS" BASIS,
+//		WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//		See the License for the specific language governing permissions and
+//		limitations under the License.
+
+package errorevent
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// contractFile is shared with error_handler_service, which checks its models.ErrorEvent against it
+const contractFile = "../../../contracts/error_event.json"
+
+type contract struct {
+	Events  []Name `json:"events"`
+	Message struct {
+		Pattern Name            `json:"pattern"`
+		Data    json.RawMessage `json:"data"`
+	} `json:"message"`
+}
+
+func loadContract(t *testing.T) contract {
+	t.Helper()
+
+	raw, err := os.ReadFile(contractFile)
+	if err != nil {
+		t.Fatalf("reading %s: %v", contractFile, err)
+	}
+	var c contract
+	if err := json.Unmarshal(raw, &c); err != nil {
+		t.Fatalf("decoding %s: %v", contractFile, err)
+	}
+	return c
+}
+
+func TestEventNamesMatchContract(t *testing.T) {
+	names := []Name{
+		FloraGetAll, FloraGetByID, FloraGetImage, FloraPost,
+		FloraPut, FloraDelete, FloraRestore, FloraForbidden,
+	}
+
+	if got := loadContract(t).Events; !reflect.DeepEqual(names, got) {
+		t.Errorf("event names = %v, contract has %v", names, got)
+	}
+}
+
+func TestErrorEventMatchesContract(t *testing.T) {
+	c := loadContract(t)
+
+	event := ErrorEvent{
+		Code:    400,
+		Status:  "Bad Request",
+		Type:    "POST",
+		Data:    Data{"error": "User ID not found in request"},
+		Service: "gene_bank_service",
+		Stack:   []string{"project_chimera/gene_bank_service/internal/flora.(*floraService).CreateFlora"},
+		Request: &RequestContext{
+			TraceID:   "4bf92f3577b34da6a3ce929d0e0e4736",
+			Method:    "POST",
+			Path:      "/flora",
+			UserID:    "user-1",
+			IP:        "10.0.0.7",
+			UserAgent: "curl/8.5.0",
+		},
+		Timestamp: time.Date(2025, 4, 12, 9, 30, 0, 0, time.UTC),
+	}
+
+	encoded, err := json.Marshal(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got, want interface{}
+	if err := json.Unmarshal(encoded, &got); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(c.Message.Data, &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("encoded event differs from the contract\n got: %s\nwant: %s", encoded, c.Message.Data)
+	}
+}
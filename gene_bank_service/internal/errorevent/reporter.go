@@ -0,0 +1,231 @@
+//	Copyright 2025 Naveen R
+//
+//		Licensed under the Apache License, Version 2.0 (the "License");
+//		you may not use this file except in compliance with the License.
+//		You may obtain a copy of the License at
+//
+//		http://www.apache.org/licenses/LICENSE-2.0
+//
+//		Unless required by applicable law or agreed to in writing, software
+//		distributed under the License is distributed on an "AS IS" BASIS,
+//		WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//		See the License for the specific language governing permissions and
+//		limitations under the License.
+
+package errorevent
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"project_chimera/gene_bank_service/internal/auth"
+	"project_chimera/gene_bank_service/internal/problem"
+	"project_chimera/gene_bank_service/pkg/utils"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	defaultBufferSize    = 1000
+	defaultRetryInterval = 5 * time.Second
+	maxStackFrames       = 16
+)
+
+// Sender publishes an event on the error queue, implemented by *rabbitmq.Handler
+type Sender interface {
+	SendEvent(event interface{}, cmd string) error
+}
+
+// pendingEvent is an encoded event waiting to be published
+type pendingEvent struct {
+	seq  uint64
+	name Name
+	body json.RawMessage
+}
+
+// Reporter publishes error events in the background and buffers them
+// in memory while the error queue cannot be reached
+type Reporter struct {
+	sender        Sender
+	service       string
+	bufferSize    int
+	retryInterval time.Duration
+
+	mu      sync.Mutex
+	pending []pendingEvent
+	nextSeq uint64
+	dropped int
+
+	// unreachable is only touched by the publishing loop, it limits logging to state changes
+	unreachable bool
+
+	wake      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewReporter creates a reporter and starts its publishing loop
+func NewReporter(sender Sender, service string, bufferSize int, retryInterval time.Duration) *Reporter {
+	if bufferSize <= 0 {
+		bufferSize = defaultBufferSize
+	}
+	if retryInterval <= 0 {
+		retryInterval = defaultRetryInterval
+	}
+
+	reporter := &Reporter{
+		sender:        sender,
+		service:       service,
+		bufferSize:    bufferSize,
+		retryInterval: retryInterval,
+		wake:          make(chan struct{}, 1),
+		done:          make(chan struct{}),
+	}
+
+	go reporter.run()
+
+	return reporter
+}
+
+// Report queues an error event for the request c, which may be nil outside of requests
+func (r *Reporter) Report(c *fiber.Ctx, name Name, code int, data interface{}) {
+	event := ErrorEvent{
+		Code:      code,
+		Status:    utils.StatusMessage(code),
+		Data:      data,
+		Service:   r.service,
+		Stack:     callers(),
+		Timestamp: time.Now().UTC(),
+	}
+	if c != nil {
+		event.Type = c.Method()
+		event.Request = &RequestContext{
+			TraceID:   problem.TraceID(c),
+			Method:    c.Method(),
+			Path:      c.Path(),
+			UserID:    auth.UserID(c),
+			IP:        c.IP(),
+			UserAgent: c.Get(fiber.HeaderUserAgent),
+		}
+	}
+
+	// Encode right away, strings read from the Fiber context are only valid during the request
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Failed to encode %s error event: %v", name, err)
+		return
+	}
+
+	r.mu.Lock()
+	if len(r.pending) >= r.bufferSize {
+		// Keep the most recent events, the oldest ones are the least useful
+		r.pending = r.pending[1:]
+		r.dropped++
+	}
+	r.nextSeq++
+	r.pending = append(r.pending, pendingEvent{seq: r.nextSeq, name: name, body: body})
+	r.mu.Unlock()
+
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Pending returns the number of events waiting to be published
+func (r *Reporter) Pending() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.pending)
+}
+
+// Close stops the publishing loop, events still buffered are logged
+func (r *Reporter) Close() {
+	r.closeOnce.Do(func() {
+		close(r.done)
+	})
+}
+
+// run publishes queued events and retries the buffered ones on every tick
+func (r *Reporter) run() {
+	ticker := time.NewTicker(r.retryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			if pending := r.Pending(); pending > 0 {
+				log.Printf("Error reporter closed with %d unsent events", pending)
+			}
+			return
+		case <-r.wake:
+		case <-ticker.C:
+		}
+		r.flush()
+	}
+}
+
+// flush publishes buffered events in order and stops at the first failure
+func (r *Reporter) flush() {
+	r.mu.Lock()
+	if r.dropped > 0 {
+		log.Printf("Error reporter buffer full, dropped %d events", r.dropped)
+		r.dropped = 0
+	}
+	r.mu.Unlock()
+
+	for {
+		r.mu.Lock()
+		if len(r.pending) == 0 {
+			r.mu.Unlock()
+			return
+		}
+		next := r.pending[0]
+		r.mu.Unlock()
+
+		if err := r.sender.SendEvent(next.body, string(next.name)); err != nil {
+			if !r.unreachable {
+				log.Printf("Error queue unreachable, buffering events until it recovers: %v", err)
+				r.unreachable = true
+			}
+			return
+		}
+		if r.unreachable {
+			log.Printf("Error queue reachable again, sending %d buffered events", r.Pending())
+			r.unreachable = false
+		}
+
+		r.mu.Lock()
+		// Report may have dropped the head meanwhile, only remove it if it is still the one sent
+		if len(r.pending) > 0 && r.pending[0].seq == next.seq {
+			r.pending = r.pending[1:]
+		}
+		r.mu.Unlock()
+	}
+}
+
+// callers returns the call stack of the code that called Report
+func callers() []string {
+	pcs := make([]uintptr, maxStackFrames)
+	// Skip runtime.Callers, callers and Report
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	stack := make([]string, 0, n)
+	for {
+		frame, more := frames.Next()
+		// Frames below the handlers belong to Fiber and the runtime
+		if strings.HasPrefix(frame.Function, "github.com/gofiber/") || strings.HasPrefix(frame.Function, "runtime.") {
+			break
+		}
+		stack = append(stack, fmt.Sprintf("%s (%s:%d)", frame.Function, frame.File, frame.Line))
+		if !more {
+			break
+		}
+	}
+	return stack
+}
@@ -0,0 +1,166 @@
+//	Copyright 2025 Naveen R
+//
+//		Licensed under the Apache License, Version 2.0 (the "License");
+//		you may not use this file except in compliance with the License.
+//		You may obtain a copy of the License at
+//
+//		http://www.apache.org/licenses/LICENSE-2.0
+//
+//		Unless required by applicable law or agreed to in writing, software
+//		distributed under the License is distributed on an "AS IS" BASIS,
+//		WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//		See the License for the specific language governing permissions and
+//		limitations under the License.
+
+package flora
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"project_chimera/gene_bank_service/internal/cache"
+	"project_chimera/gene_bank_service/internal/dto"
+	"project_chimera/gene_bank_service/internal/submission"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// Cache key prefixes of get_flora_by_id records, get_all_floras pages and resized images
+const (
+	floraCachePrefix = "flora:"
+	listCachePrefix  = "flora-list:"
+	imageCachePrefix = "flora-image:"
+)
+
+// Events from flora_upstream_service that change a flora record
+const (
+	eventFloraChanged    = "flora.changed"
+	eventFloraSubmission = "flora.submission"
+)
+
+func floraCacheKey(id string) string {
+	return floraCachePrefix + id
+}
+
+// imageCacheKey identifies a copy of the image of a flora resized to size pixels
+func imageCacheKey(id string, size int) string {
+	return imageCachePrefix + id + ":" + strconv.Itoa(size)
+}
+
+// listCacheKey identifies a page, the links and image URLs in it depend on the URL it was requested on
+func listCacheKey(c *fiber.Ctx, query dto.FloraQuery) (string, error) {
+	encoded, err := json.Marshal(query)
+	if err != nil {
+		return "", err
+	}
+	return listCachePrefix + c.BaseURL() + c.Path() + "|" + query.Image + "|" + string(encoded), nil
+}
+
+// invalidateFlora drops a cached record, its resized images and every cached page, since any page may list it
+func invalidateFlora(store cache.Cache, id string) {
+	if id != "" {
+		store.Delete(floraCacheKey(id))
+		store.DeletePrefix(imageCachePrefix + id + ":")
+	}
+	store.DeletePrefix(listCachePrefix)
+}
+
+// floraEvent is the part of the upstream events needed to find the changed record
+type floraEvent struct {
+	Pattern string `json:"pattern"`
+	Data    struct {
+		FloraID string            `json:"flora_id"`
+		ID      string            `json:"id"`
+		Status  submission.Status `json:"status"`
+	} `json:"data"`
+}
+
+// CacheEventHandler invalidates cached flora when flora.changed arrives or a submission
+// succeeded, and hands every other event and all submission events on to next.
+// Each instance keeps its own cache, the events are fanned out so that every instance sees them.
+func CacheEventHandler(store cache.Cache, next func(amqp091.Delivery) error) func(amqp091.Delivery) error {
+	return func(msg amqp091.Delivery) error {
+		var event floraEvent
+		if err := json.Unmarshal(msg.Body, &event); err != nil {
+			return next(msg)
+		}
+
+		id := event.Data.FloraID
+		if id == "" {
+			id = event.Data.ID
+		}
+
+		switch event.Pattern {
+		case eventFloraChanged:
+			invalidateFlora(store, id)
+			return nil
+		case eventFloraSubmission:
+			if event.Data.Status == submission.Succeeded || event.Data.Status == submission.AutoFixed {
+				invalidateFlora(store, id)
+			}
+		}
+		return next(msg)
+	}
+}
+
+// sendConditional writes a JSON response with ETag and Last-Modified validators,
+// answering 304 Not Modified when the copy the client holds is still current
+func sendConditional(c *fiber.Ctx, res dto.FloraResponse) error {
+	body, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	lastModified := res.LastModified.UTC().Truncate(time.Second)
+
+	c.Set(fiber.HeaderETag, etag)
+	c.Set(fiber.HeaderLastModified, lastModified.Format(http.TimeFormat))
+	// Responses depend on the caller, shared caches must not reuse them
+	c.Set(fiber.HeaderCacheControl, "private, no-cache")
+	c.Vary(fiber.HeaderAuthorization)
+
+	if notModified(c, etag, lastModified) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Status(fiber.StatusOK).Send(body)
+}
+
+// notModified evaluates If-None-Match, falling back to If-Modified-Since when it is absent
+func notModified(c *fiber.Ctx, etag string, lastModified time.Time) bool {
+	if match := c.Get(fiber.HeaderIfNoneMatch); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if since := c.Get(fiber.HeaderIfModifiedSince); since != "" {
+		if t, err := http.ParseTime(since); err == nil {
+			return !lastModified.After(t)
+		}
+	}
+	return false
+}
+
+// cacheJSON stores a value in the cache, values that cannot be encoded are skipped
+func cacheJSON(store cache.Cache, key string, value interface{}) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("Failed to cache %s: %v", key, err)
+		return
+	}
+	store.Set(key, encoded)
+}
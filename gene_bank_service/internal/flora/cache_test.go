@@ -0,0 +1,177 @@
+//	Copyright 2025 Naveen R
+//
+//		Licensed under the Apache License, Version 2.0 (the "License");
+//		you may not use this file except in compliance with the License.
+//		You may obtain a copy of the License at
+//
+//		http://www.apache.org/licenses/LICENSE-2.0
+//
+//		Unless required by applicable law or agreed to in writing, software
+//		distributed under the License is distributed on an "AS IS" BASIS,
+//		WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//		See the License for the specific language governing permissions and
+//		limitations under the License.
+
+package flora
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"project_chimera/gene_bank_service/internal/cache"
+	"project_chimera/gene_bank_service/internal/dto"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rabbitmq/amqp091-go"
+)
+
+func TestCacheEventHandlerInvalidates(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantRecord  bool // flora:1 is still cached
+		wantPages   bool // the cached page is still there
+		wantForward bool // the event reached next
+	}{
+		{
+			name:       "flora changed",
+			body:       `{"pattern":"flora.changed","data":{"flora_id":"1","change":"updated"}}`,
+			wantRecord: false, wantPages: false, wantForward: false,
+		},
+		{
+			name:       "flora deleted",
+			body:       `{"pattern":"flora.changed","data":{"flora_id":"1","change":"deleted"}}`,
+			wantRecord: false, wantPages: false, wantForward: false,
+		},
+		{
+			name:       "other flora changed",
+			body:       `{"pattern":"flora.changed","data":{"flora_id":"2","change":"updated"}}`,
+			wantRecord: true, wantPages: false, wantForward: false,
+		},
+		{
+			name:       "submission succeeded",
+			body:       `{"pattern":"flora.submission","data":{"submission_id":"s1","status":"succeeded","flora_id":"1"}}`,
+			wantRecord: false, wantPages: false, wantForward: true,
+		},
+		{
+			name:       "submission auto-fixed",
+			body:       `{"pattern":"flora.submission","data":{"submission_id":"s1","status":"auto-fixed","flora_id":"1"}}`,
+			wantRecord: false, wantPages: false, wantForward: true,
+		},
+		{
+			name:       "submission failed",
+			body:       `{"pattern":"flora.submission","data":{"submission_id":"s1","status":"failed","flora_id":"1"}}`,
+			wantRecord: true, wantPages: true, wantForward: true,
+		},
+		{
+			name:       "failure dump pattern is not a change",
+			body:       `{"pattern":"flora.updated","data":{"id":"1"}}`,
+			wantRecord: true, wantPages: true, wantForward: true,
+		},
+		{
+			name:       "not JSON",
+			body:       `flora`,
+			wantRecord: true, wantPages: true, wantForward: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := cache.NewLRU(time.Minute, 10, 0)
+			store.Set(floraCacheKey("1"), []byte(`{}`))
+			store.Set(listCachePrefix+"page-1", []byte(`{}`))
+
+			forwarded := false
+			handler := CacheEventHandler(store, func(amqp091.Delivery) error {
+				forwarded = true
+				return nil
+			})
+			if err := handler(amqp091.Delivery{Body: []byte(tt.body)}); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, ok := store.Get(floraCacheKey("1")); ok != tt.wantRecord {
+				t.Errorf("record cached = %t, want %t", ok, tt.wantRecord)
+			}
+			if _, ok := store.Get(listCachePrefix + "page-1"); ok != tt.wantPages {
+				t.Errorf("page cached = %t, want %t", ok, tt.wantPages)
+			}
+			if forwarded != tt.wantForward {
+				t.Errorf("forwarded = %t, want %t", forwarded, tt.wantForward)
+			}
+		})
+	}
+}
+
+func TestSendConditional(t *testing.T) {
+	lastModified := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	response := dto.FloraResponse{Total: 1, LastModified: lastModified}
+
+	app := fiber.New()
+	app.Get("/flora/:id", func(c *fiber.Ctx) error {
+		return sendConditional(c, response)
+	})
+
+	get := func(header, value string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest(fiber.MethodGet, "/flora/1", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	first := get("", "")
+	etag := first.Header.Get(fiber.HeaderETag)
+	if first.StatusCode != fiber.StatusOK || etag == "" {
+		t.Fatalf("first response: status %d, ETag %q", first.StatusCode, etag)
+	}
+	if again := get("", ""); again.Header.Get(fiber.HeaderETag) != etag {
+		t.Fatal("ETag of the same response changed")
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		want   int
+	}{
+		{name: "matching ETag", header: fiber.HeaderIfNoneMatch, value: etag, want: fiber.StatusNotModified},
+		{name: "weak matching ETag", header: fiber.HeaderIfNoneMatch, value: "W/" + etag, want: fiber.StatusNotModified},
+		{name: "ETag in a list", header: fiber.HeaderIfNoneMatch, value: `"stale", ` + etag, want: fiber.StatusNotModified},
+		{name: "wildcard", header: fiber.HeaderIfNoneMatch, value: "*", want: fiber.StatusNotModified},
+		{name: "stale ETag", header: fiber.HeaderIfNoneMatch, value: `"stale"`, want: fiber.StatusOK},
+		{name: "not modified since", header: fiber.HeaderIfModifiedSince, value: lastModified.Format(http.TimeFormat), want: fiber.StatusNotModified},
+		{name: "modified since", header: fiber.HeaderIfModifiedSince, value: lastModified.Add(-time.Hour).Format(http.TimeFormat), want: fiber.StatusOK},
+		{name: "invalid date", header: fiber.HeaderIfModifiedSince, value: "yesterday", want: fiber.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := get(tt.header, tt.value)
+			if resp.StatusCode != tt.want {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+			if resp.Header.Get(fiber.HeaderETag) != etag {
+				t.Fatalf("ETag = %q, want %q", resp.Header.Get(fiber.HeaderETag), etag)
+			}
+		})
+	}
+
+	// If-None-Match takes precedence, a stale ETag is not rescued by a matching date
+	req := httptest.NewRequest(fiber.MethodGet, "/flora/1", nil)
+	req.Header.Set(fiber.HeaderIfNoneMatch, `"stale"`)
+	req.Header.Set(fiber.HeaderIfModifiedSince, lastModified.Format(http.TimeFormat))
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, fiber.StatusOK)
+	}
+}
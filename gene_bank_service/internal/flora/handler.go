@@ -15,20 +15,43 @@
 package flora
 
 import (
-	"log"
+	"project_chimera/gene_bank_service/internal/cache"
+	"project_chimera/gene_bank_service/internal/dto"
+	"project_chimera/gene_bank_service/internal/errorevent"
 	"project_chimera/gene_bank_service/internal/rabbitmq"
+	"project_chimera/gene_bank_service/internal/submission"
 	"project_chimera/gene_bank_service/pkg/common"
+	"project_chimera/gene_bank_service/pkg/utils/helpers"
+	"strconv"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+	maxSearchLength = 100
+)
+
+// sortableFields lists the flora fields GET /flora can be sorted by
+var sortableFields = map[string]bool{
+	"common_name":     true,
+	"scientific_name": true,
+	"origin":          true,
+	"type":            true,
+}
+
 // FloraHandler defines the interface for flora handlers
 type FloraHandler interface {
 	GetFlora(c *fiber.Ctx) error
 	GetFloraById(c *fiber.Ctx) error
+	GetFloraImage(c *fiber.Ctx) error
 	PostFlora(c *fiber.Ctx) error
 	PutFlora(c *fiber.Ctx) error
+	GetSubmission(c *fiber.Ctx) error
 	DeleteFlora(c *fiber.Ctx) error
+	RestoreFlora(c *fiber.Ctx) error
 }
 
 // floraHandler is the concrete implementation of FloraHandler
@@ -46,27 +69,48 @@ func NewFloraHandler(service FloraService) FloraHandler {
 // @Tags Flora
 // @Accept json
 // @Produce json
+// @Param If-None-Match header string false "ETag of a previous response"
+// @Param page query int false "Page number (1 based)"
+// @Param size query int false "Page size (max 100)"
+// @Param cursor query string false "Cursor returned as next_cursor, takes precedence over page"
+// @Param sort query string false "Sort field, prefix with - for descending (common_name, scientific_name, origin, type)"
+// @Param type query string false "Filter by type (public/private)"
+// @Param origin query string false "Filter by origin"
+// @Param user_id query string false "Filter by owner"
+// @Param q query string false "Search in names and description"
+// @Param image query string false "Image returned per record: thumbnail (default) or full"
 // @Success 200 {object} dto.FloraResponse
-// @Failure 500 {object} common.ErrorResponse
+// @Success 304 "Not modified, the If-None-Match ETag is still current"
+// @Failure 400 {object} common.Problem
+// @Failure 500 {object} common.Problem
 // @Router /flora [get]
 func (h *floraHandler) GetFlora(c *fiber.Ctx) error {
-	res, err := h.service.GetFlora(c)
+	query, err := parseFloraQuery(c)
+	if err != nil {
+		return err
+	}
+
+	res, err := h.service.GetFlora(c, query)
 
 	if err != nil {
 		return err
 	}
 
-	return c.Status(200).JSON(res)
+	return sendConditional(c, res)
 }
 
 // GetFloraById handler for retrieving flora data by ID
 // @Summary Retrieve flora data by ID from the database
+// @Description Private flora of other users is reported as not found, unless the caller has a privileged role.
 // @Tags Flora
 // @Accept json
 // @Produce json
+// @Param If-None-Match header string false "ETag of a previous response"
 // @Param id path string true "Flora ID"
 // @Success 200 {object} dto.FloraResponse
-// @Failure 500 {object} common.ErrorResponse
+// @Success 304 "Not modified, the If-None-Match ETag is still current"
+// @Failure 404 {object} common.Problem
+// @Failure 500 {object} common.Problem
 // @Router /flora/{id} [get]
 func (h *floraHandler) GetFloraById(c *fiber.Ctx) error {
 	res, err := h.service.GetFloraById(c)
@@ -75,60 +119,237 @@ func (h *floraHandler) GetFloraById(c *fiber.Ctx) error {
 		return err
 	}
 
-	return c.Status(200).JSON(res)
+	return sendConditional(c, res)
+}
+
+// GetFloraImage handler for serving the image of a flora
+// @Summary Retrieve the image of a flora
+// @Description Serves the stored image. size is full (default), thumbnail or the longest side in pixels.
+// @Tags Flora
+// @Produce jpeg,png
+// @Param id path string true "Flora ID"
+// @Param size query string false "full, thumbnail or a pixel count"
+// @Success 200 {file} binary
+// @Failure 400 {object} common.Problem
+// @Failure 404 {object} common.Problem
+// @Failure 500 {object} common.Problem
+// @Router /flora/{id}/image [get]
+func (h *floraHandler) GetFloraImage(c *fiber.Ctx) error {
+	image, contentType, err := h.service.GetFloraImage(c)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderCacheControl, "private, max-age=3600")
+	return c.Status(200).Send(image)
 }
 
 // PostFlora handler for adding flora data
 // @Summary Add a flora data to the database
+// @Description Accepts JSON or multipart/form-data with the metadata fields and an image file part (JPEG, PNG or WebP).
 // @Tags Flora
-// @Accept json
+// @Accept json,mpfd
 // @Produce json
+// @Param Idempotency-Key header string false "Replays the first response when the request is retried with the same key"
 // @Param flora body dto.FloraRequest true "Flora data"
-// @Success 200 {object} common.SuccessResponse
-// @Failure 400 {object} common.ErrorResponse
-// @Failure 500 {object} common.ErrorResponse
+// @Success 202 {object} common.SubmissionResponse
+// @Failure 400 {object} common.Problem
+// @Failure 401 {object} common.Problem
+// @Failure 409 {object} common.Problem
+// @Failure 413 {object} common.Problem
+// @Failure 415 {object} common.Problem
+// @Failure 422 {object} common.Problem
+// @Failure 500 {object} common.Problem
+// @Failure 503 {object} common.Problem
 // @Router /flora [post]
 func (h *floraHandler) PostFlora(c *fiber.Ctx) error {
-	err := h.service.PostFlora(c)
-	log.Println(err)
+	sub, err := h.service.PostFlora(c)
 	if err != nil {
 		return err
 	}
-	return c.Status(200).JSON(common.SuccessResponse{Status: "Flora submitted successfully"})
+	return c.Status(fiber.StatusAccepted).JSON(submissionResponse(c, sub))
 }
 
 // PutFlora handler for updating flora data
 // @Summary Update a flora data in the database
+// @Description Accepts JSON or multipart/form-data with the metadata fields and an image file part (JPEG, PNG or WebP).
+// @Description Only the owner of the flora or an admin or curator can update it.
 // @Tags Flora
-// @Accept json
+// @Accept json,mpfd
 // @Produce json
+// @Param id path string false "Flora ID, overrides the id in the body"
+// @Param Idempotency-Key header string false "Replays the first response when the request is retried with the same key"
 // @Param flora body dto.FloraUpdateRequest true "Flora data"
-// @Success 200 {object} common.SuccessResponse
-// @Failure 400 {object} common.ErrorResponse
-// @Failure 500 {object} common.ErrorResponse
-// @Router /flora [put]
+// @Success 202 {object} common.SubmissionResponse
+// @Failure 400 {object} common.Problem
+// @Failure 401 {object} common.Problem
+// @Failure 403 {object} common.Problem
+// @Failure 404 {object} common.Problem
+// @Failure 409 {object} common.Problem
+// @Failure 413 {object} common.Problem
+// @Failure 415 {object} common.Problem
+// @Failure 422 {object} common.Problem
+// @Failure 500 {object} common.Problem
+// @Failure 503 {object} common.Problem
+// @Router /flora/{id} [put]
 func (h *floraHandler) PutFlora(c *fiber.Ctx) error {
-	err := h.service.PutFlora(c)
-	log.Println(err)
+	sub, err := h.service.PutFlora(c)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusAccepted).JSON(submissionResponse(c, sub))
+}
+
+// GetSubmission handler for retrieving the status of a flora submission
+// @Summary Retrieve the processing status of a POST/PUT flora submission
+// @Tags Flora
+// @Produce json
+// @Param id path string true "Submission ID"
+// @Success 200 {object} submission.Submission
+// @Failure 404 {object} common.Problem
+// @Router /flora/submissions/{id} [get]
+func (h *floraHandler) GetSubmission(c *fiber.Ctx) error {
+	sub, err := h.service.GetSubmission(c)
 	if err != nil {
 		return err
 	}
-	return c.Status(200).JSON(common.SuccessResponse{Status: "Flora submitted successfully"})
+	return c.Status(200).JSON(sub)
 }
 
 // DeleteFlora handler for deleting flora data
+// @Summary Delete a flora data from the database
+// @Description Only the owner of the flora or an admin or curator can delete it. Pass soft=true to keep the record restorable.
+// @Tags Flora
+// @Accept json
+// @Produce json
+// @Param id path string true "Flora ID"
+// @Param soft query bool false "Soft delete the flora so it can be restored later"
+// @Success 200 {object} common.SuccessResponse
+// @Failure 400 {object} common.Problem
+// @Failure 401 {object} common.Problem
+// @Failure 403 {object} common.Problem
+// @Failure 404 {object} common.Problem
+// @Failure 500 {object} common.Problem
+// @Router /flora/{id} [delete]
 func (h *floraHandler) DeleteFlora(c *fiber.Ctx) error {
-	return nil
+	err := h.service.DeleteFlora(c)
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(common.SuccessResponse{Status: "Flora deletion submitted successfully"})
+}
+
+// RestoreFlora handler for restoring soft deleted flora data
+// @Summary Restore a soft deleted flora data
+// @Description Only the owner of the flora or an admin or curator can restore it.
+// @Tags Flora
+// @Accept json
+// @Produce json
+// @Param id path string true "Flora ID"
+// @Success 200 {object} common.SuccessResponse
+// @Failure 400 {object} common.Problem
+// @Failure 401 {object} common.Problem
+// @Failure 403 {object} common.Problem
+// @Failure 404 {object} common.Problem
+// @Failure 500 {object} common.Problem
+// @Router /flora/{id}/restore [post]
+func (h *floraHandler) RestoreFlora(c *fiber.Ctx) error {
+	err := h.service.RestoreFlora(c)
+	if err != nil {
+		return err
+	}
+	return c.Status(200).JSON(common.SuccessResponse{Status: "Flora restore submitted successfully"})
+}
+
+// parseFloraQuery reads and validates the pagination, filter and sort query parameters
+func parseFloraQuery(c *fiber.Ctx) (dto.FloraQuery, error) {
+	query := dto.FloraQuery{Page: 1, Size: defaultPageSize}
+
+	if page := c.Query("page"); page != "" {
+		value, err := strconv.Atoi(page)
+		if err != nil || value < 1 {
+			return query, &fiber.Error{Code: fiber.StatusBadRequest, Message: "page must be a positive integer"}
+		}
+		query.Page = value
+	}
+
+	if cursor := c.Query("cursor"); cursor != "" {
+		value, err := helpers.DecodeCursor(cursor)
+		if err != nil {
+			return query, &fiber.Error{Code: fiber.StatusBadRequest, Message: "cursor is invalid"}
+		}
+		query.Page = value
+	}
+
+	if size := c.Query("size"); size != "" {
+		value, err := strconv.Atoi(size)
+		if err != nil || value < 1 || value > maxPageSize {
+			return query, &fiber.Error{Code: fiber.StatusBadRequest, Message: "size must be between 1 and " + strconv.Itoa(maxPageSize)}
+		}
+		query.Size = value
+	}
+
+	if sort := c.Query("sort"); sort != "" {
+		query.Order = "asc"
+		if strings.HasPrefix(sort, "-") {
+			query.Order = "desc"
+			sort = strings.TrimPrefix(sort, "-")
+		}
+		if !sortableFields[sort] {
+			return query, &fiber.Error{Code: fiber.StatusBadRequest, Message: "sort field " + sort + " is not supported"}
+		}
+		query.Sort = sort
+	}
+
+	if floraType := c.Query("type"); floraType != "" {
+		if floraType != string(dto.Public) && floraType != string(dto.Private) {
+			return query, &fiber.Error{Code: fiber.StatusBadRequest, Message: "type must be public or private"}
+		}
+		query.Type = floraType
+	}
+
+	query.Origin = strings.TrimSpace(c.Query("origin"))
+	query.UserID = strings.TrimSpace(c.Query("user_id"))
+
+	query.Q = strings.TrimSpace(c.Query("q"))
+	if len(query.Q) > maxSearchLength {
+		return query, &fiber.Error{Code: fiber.StatusBadRequest, Message: "q must not exceed " + strconv.Itoa(maxSearchLength) + " characters"}
+	}
+
+	switch image := c.Query("image"); image {
+	case "", imageSizeThumbnail:
+		query.Image = imageSizeThumbnail
+	case imageSizeFull:
+		query.Image = imageSizeFull
+	default:
+		return query, &fiber.Error{Code: fiber.StatusBadRequest, Message: "image must be thumbnail or full"}
+	}
+
+	return query, nil
+}
+
+// submissionResponse builds the 202 body pointing the client at the submission status endpoint
+func submissionResponse(c *fiber.Ctx, sub submission.Submission) common.SubmissionResponse {
+	return common.SubmissionResponse{
+		Status:       "Flora submitted successfully",
+		SubmissionID: sub.ID,
+		StatusURL:    c.BaseURL() + "/flora/submissions/" + sub.ID,
+	}
 }
 
 // FloraRouter sets up the routes for flora endpoints
-func FloraRouter(router fiber.Router, upStreamHandler *rabbitmq.Handler, downStreamHandler *rabbitmq.Handler, errorQueueHandler *rabbitmq.Handler) {
-	service := NewFloraService(upStreamHandler, downStreamHandler, errorQueueHandler)
+func FloraRouter(router fiber.Router, upStreamHandler *rabbitmq.Handler, downStreamHandler *rabbitmq.Handler, reporter *errorevent.Reporter, submissions *submission.Store, policy FloraPolicy, store cache.Cache) {
+	service := NewFloraService(upStreamHandler, downStreamHandler, reporter, submissions, policy, store)
 	handler := NewFloraHandler(service)
 
 	router.Get("/", handler.GetFlora)
+	router.Get("/submissions/:id", handler.GetSubmission)
 	router.Get("/:id", handler.GetFloraById)
+	router.Get("/:id/image", handler.GetFloraImage)
 	router.Post("/", handler.PostFlora)
 	router.Put("/", handler.PutFlora)
-	router.Delete("/", handler.DeleteFlora)
+	router.Put("/:id", handler.PutFlora)
+	router.Delete("/:id", handler.DeleteFlora)
+	router.Post("/:id/restore", handler.RestoreFlora)
 }
@@ -0,0 +1,204 @@
+//	Copyright 2025 Naveen R
+//
+//		Licensed under the Apache License, Version 2.0 (the "License");
+//		you may not use this file except in compliance with the License.
+//		You may obtain a copy of the License at
+//
+//		http://www.apache.org/licenses/LICENSE-2.0
+//
+//		Unless required by applicable law or agreed to in writing, software
+//		distributed under the License is distributed on an "AS IS" BASIS,
+//		WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//		See the License for the specific language governing permissions and
+//		limitations under the License.
+
+package flora
+
+import (
+	"encoding/base64"
+	"log"
+	"project_chimera/gene_bank_service/internal/dto"
+	"project_chimera/gene_bank_service/internal/rabbitmq"
+	"project_chimera/gene_bank_service/pkg/utils"
+	"strconv"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Values accepted by the image query parameter of GET /flora and the size parameter of GET /flora/:id/image
+const (
+	imageSizeFull      = "full"
+	imageSizeThumbnail = "thumbnail"
+	minImageSize       = 16
+)
+
+// imageURL returns the link serving the full image of a flora
+func imageURL(c *fiber.Ctx, id string) string {
+	return c.BaseURL() + "/flora/" + id + "/image"
+}
+
+// maxBackfilledThumbnails caps the thumbnails kept until the downstream service serves them
+const maxBackfilledThumbnails = 1000
+
+// thumbnailBackfill creates the missing thumbnails of records stored before thumbnails existed.
+// Each one is created once and sent upstream with set_thumbnail, and served from memory until
+// the downstream service returns the stored copy.
+type thumbnailBackfill struct {
+	upstream *rabbitmq.Handler
+
+	mu         sync.Mutex
+	thumbnails map[string]string // Base64 thumbnails by flora ID
+}
+
+func newThumbnailBackfill(upstream *rabbitmq.Handler) *thumbnailBackfill {
+	return &thumbnailBackfill{upstream: upstream, thumbnails: map[string]string{}}
+}
+
+// thumbnail returns the thumbnail of a record without a stored one, creating and storing it on first use
+func (b *thumbnailBackfill) thumbnail(flora dto.FloraData) (string, error) {
+	b.mu.Lock()
+	thumbnail, ok := b.thumbnails[flora.ID]
+	b.mu.Unlock()
+	if ok {
+		return thumbnail, nil
+	}
+
+	thumbnail, err := thumbnailFromBase64(flora.Image)
+	if err != nil {
+		return "", err
+	}
+
+	b.mu.Lock()
+	if len(b.thumbnails) < maxBackfilledThumbnails {
+		b.thumbnails[flora.ID] = thumbnail
+	}
+	b.mu.Unlock()
+
+	go b.store(flora.ID, thumbnail)
+	return thumbnail, nil
+}
+
+// store sends the thumbnail upstream, failures are forgotten so the next request tries again
+func (b *thumbnailBackfill) store(id string, thumbnail string) {
+	decoded, err := base64.StdEncoding.DecodeString(thumbnail)
+	if err == nil {
+		err = b.upstream.SendAckRequest(map[string]interface{}{
+			"ID":        id,
+			"Thumbnail": decoded,
+		}, "set_thumbnail", false)
+	}
+	if err != nil {
+		log.Printf("Error storing thumbnail for flora %s: %v", id, err)
+		b.forget(id)
+	}
+}
+
+// forget drops a thumbnail once the downstream service serves the stored copy
+func (b *thumbnailBackfill) forget(id string) {
+	b.mu.Lock()
+	delete(b.thumbnails, id)
+	b.mu.Unlock()
+}
+
+// withThumbnails replaces the full images of a flora list with thumbnails unless full images were requested.
+// Records stored before thumbnails existed get one through the backfill.
+func (s *floraService) withThumbnails(c *fiber.Ctx, floraList []dto.FloraData, full bool) []dto.FloraData {
+	for i := range floraList {
+		flora := &floraList[i]
+		flora.ImageURL = imageURL(c, flora.ID)
+
+		if thumbnail, err := decodeStoredImage(flora.Thumbnail); err == nil && len(thumbnail) > 0 {
+			flora.Thumbnail = base64.StdEncoding.EncodeToString(thumbnail)
+			s.thumbnails.forget(flora.ID)
+		} else if flora.Image != "" {
+			thumbnail, err := s.thumbnails.thumbnail(*flora)
+			if err != nil {
+				log.Printf("Error creating thumbnail for flora %s: %v", flora.ID, err)
+			} else {
+				flora.Thumbnail = thumbnail
+			}
+		}
+
+		if !full {
+			flora.Image = ""
+		}
+	}
+	return floraList
+}
+
+// thumbnailFromBase64 creates a base64 thumbnail from a base64 encoded image
+func thumbnailFromBase64(image string) (string, error) {
+	imageBytes, err := decodeStoredImage(image)
+	if err != nil {
+		return "", err
+	}
+	thumbnail, _, err := utils.ResizeImage(imageBytes, utils.ThumbnailSize())
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(thumbnail), nil
+}
+
+// selectImage returns the stored image in the requested size: full, thumbnail or a pixel count for the longest side
+func (s *floraService) selectImage(flora dto.FloraData, size string) ([]byte, string, error) {
+	image, err := decodeStoredImage(flora.Image)
+	if err != nil || len(image) == 0 {
+		return nil, "", &fiber.Error{Code: fiber.StatusNotFound, Message: "Flora has no image"}
+	}
+
+	switch size {
+	case "", imageSizeFull:
+		contentType, err := utils.DetectImageType(image)
+		if err != nil {
+			return nil, "", err
+		}
+		return image, contentType, nil
+	case imageSizeThumbnail:
+		if thumbnail, err := decodeStoredImage(flora.Thumbnail); err == nil && len(thumbnail) > 0 {
+			if contentType, err := utils.DetectImageType(thumbnail); err == nil {
+				return thumbnail, contentType, nil
+			}
+		}
+		return s.resizedImage(flora.ID, image, utils.ThumbnailSize())
+	}
+
+	pixels, err := strconv.Atoi(size)
+	if err != nil || pixels < minImageSize {
+		return nil, "", &fiber.Error{Code: fiber.StatusBadRequest, Message: "size must be full, thumbnail or a pixel count of at least " + strconv.Itoa(minImageSize)}
+	}
+	return s.resizedImage(flora.ID, image, pixels)
+}
+
+// resizedImage returns the image scaled to size pixels, resized copies are cached by flora ID and size
+func (s *floraService) resizedImage(id string, image []byte, size int) ([]byte, string, error) {
+	key := imageCacheKey(id, size)
+	if entry, ok := s.cache.Get(key); ok {
+		if contentType, err := utils.DetectImageType(entry.Value); err == nil {
+			return entry.Value, contentType, nil
+		}
+	}
+
+	resized, contentType, err := utils.ResizeImage(image, size)
+	if err != nil {
+		return nil, "", err
+	}
+	s.cache.Set(key, resized)
+	return resized, contentType, nil
+}
+
+// decodeStoredImage decodes an image sent by the downstream service.
+// Upstream stores the JSON base64 text of the bytes, so the downstream value can be base64 encoded twice.
+func decodeStoredImage(image string) ([]byte, error) {
+	decoded, err := base64.StdEncoding.DecodeString(image)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := utils.DetectImageType(decoded); err == nil {
+		return decoded, nil
+	}
+	if inner, err := base64.StdEncoding.DecodeString(string(decoded)); err == nil {
+		return inner, nil
+	}
+	return decoded, nil
+}
@@ -0,0 +1,161 @@
+//	Copyright 2025 Naveen R
+//
+//		Licensed under the Apache License, Version 2.0 (the "License");
+//		you may not use this file except in compliance with the License.
+//		You may obtain a copy of the License at
+//
+//		http://www.apache.org/licenses/LICENSE-2.0
+//
+//		Unless required by applicable law or agreed to in writing, software
+//		distributed under the License is distributed on an "AS IS" BASIS,
+//		WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//		See the License for the specific language governing permissions and
+//		limitations under the License.
+
+package flora
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/jpeg"
+	"project_chimera/gene_bank_service/internal/cache"
+	"project_chimera/gene_bank_service/internal/dto"
+	"project_chimera/gene_bank_service/pkg/utils"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// testJPEG encodes a plain JPEG of the given size
+func testJPEG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height)), nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func imageSize(t *testing.T, data []byte) image.Point {
+	t.Helper()
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return image.Pt(cfg.Width, cfg.Height)
+}
+
+func TestSelectImage(t *testing.T) {
+	original := testJPEG(t, 600, 300)
+	stored := base64.StdEncoding.EncodeToString(original)
+	storedThumbnail := testJPEG(t, 20, 10)
+
+	tests := []struct {
+		name     string
+		flora    dto.FloraData
+		size     string
+		want     image.Point
+		wantCode int
+	}{
+		{name: "full by default", flora: dto.FloraData{ID: "1", Image: stored}, want: image.Pt(600, 300)},
+		{name: "full", flora: dto.FloraData{ID: "1", Image: stored}, size: "full", want: image.Pt(600, 300)},
+		{name: "stored thumbnail", flora: dto.FloraData{ID: "1", Image: stored, Thumbnail: base64.StdEncoding.EncodeToString(storedThumbnail)}, size: "thumbnail", want: image.Pt(20, 10)},
+		{name: "thumbnail created from the image", flora: dto.FloraData{ID: "1", Image: stored}, size: "thumbnail", want: image.Pt(utils.ThumbnailSize(), utils.ThumbnailSize()/2)},
+		{name: "image encoded twice", flora: dto.FloraData{ID: "1", Image: base64.StdEncoding.EncodeToString([]byte(stored))}, size: "100", want: image.Pt(100, 50)},
+		{name: "pixel count", flora: dto.FloraData{ID: "1", Image: stored}, size: "120", want: image.Pt(120, 60)},
+		{name: "pixel count above the image size", flora: dto.FloraData{ID: "1", Image: stored}, size: "1000", want: image.Pt(600, 300)},
+		{name: "pixel count below the minimum", flora: dto.FloraData{ID: "1", Image: stored}, size: "8", wantCode: fiber.StatusBadRequest},
+		{name: "unknown size", flora: dto.FloraData{ID: "1", Image: stored}, size: "large", wantCode: fiber.StatusBadRequest},
+		{name: "no image", flora: dto.FloraData{ID: "1"}, size: "thumbnail", wantCode: fiber.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &floraService{cache: cache.NewLRU(time.Minute, 100, 0)}
+
+			data, contentType, err := s.selectImage(tt.flora, tt.size)
+			if tt.wantCode != 0 {
+				var fiberErr *fiber.Error
+				if !errors.As(err, &fiberErr) || fiberErr.Code != tt.wantCode {
+					t.Fatalf("got %v, want status %d", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if contentType != utils.ImageJPEG {
+				t.Fatalf("content type = %s, want %s", contentType, utils.ImageJPEG)
+			}
+			if got := imageSize(t, data); got != tt.want {
+				t.Fatalf("size = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResizedImagesAreCached(t *testing.T) {
+	store := cache.NewLRU(time.Minute, 100, 0)
+	s := &floraService{cache: store}
+	flora := dto.FloraData{ID: "1", Image: base64.StdEncoding.EncodeToString(testJPEG(t, 600, 300))}
+
+	first, _, err := s.selectImage(flora, "120")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry, ok := store.Get(imageCacheKey("1", 120)); !ok || !bytes.Equal(entry.Value, first) {
+		t.Fatal("resized image was not cached by ID and size")
+	}
+
+	// A cached copy is served without resizing the stored image again
+	flora.Image = base64.StdEncoding.EncodeToString(testJPEG(t, 300, 600))
+	second, _, err := s.selectImage(flora, "120")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(second, first) {
+		t.Fatal("resized image was computed again")
+	}
+
+	// Other sizes and other flora have their own entries
+	if other, _, _ := s.selectImage(flora, "60"); imageSize(t, other) != image.Pt(30, 60) {
+		t.Fatalf("size 60 = %v, want the new image resized", imageSize(t, other))
+	}
+	if _, ok := store.Get(imageCacheKey("2", 120)); ok {
+		t.Fatal("image of another flora is cached")
+	}
+
+	invalidateFlora(store, "1")
+	if _, ok := store.Get(imageCacheKey("1", 120)); ok {
+		t.Fatal("resized image survived the invalidation of its flora")
+	}
+	resized, _, err := s.selectImage(flora, "120")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := imageSize(t, resized); got != image.Pt(60, 120) {
+		t.Fatalf("size after invalidation = %v, want 60x120", got)
+	}
+}
+
+func TestThumbnailFromBase64(t *testing.T) {
+	thumbnail, err := thumbnailFromBase64(base64.StdEncoding.EncodeToString(testJPEG(t, 300, 600)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(thumbnail)
+	if err != nil {
+		t.Fatal(err)
+	}
+	size := utils.ThumbnailSize()
+	if got := imageSize(t, decoded); got != image.Pt(size/2, size) {
+		t.Fatalf("size = %v, want %dx%d", got, size/2, size)
+	}
+
+	if _, err := thumbnailFromBase64("not base64"); err == nil {
+		t.Fatal("invalid base64 was accepted")
+	}
+}
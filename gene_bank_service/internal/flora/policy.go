@@ -0,0 +1,91 @@
+//	Copyright 2025 Naveen R
+//
+//		Licensed under the Apache License, Version 2.0 (the "License");
+//		you may not use this file except in compliance with the License.
+//		You may obtain a copy of the License at
+//
+//		http://www.apache.org/licenses/LICENSE-2.0
+//
+//		Unless required by applicable law or agreed to in writing, software
+//		distributed under the License is distributed on an "AS IS" BASIS,
+//		WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//		See the License for the specific language governing permissions and
+//		limitations under the License.
+
+package flora
+
+import (
+	"project_chimera/gene_bank_service/internal/auth"
+	"project_chimera/gene_bank_service/internal/dto"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Action is what a caller attempts on a flora record
+type Action string
+
+const (
+	ActionView   Action = "view"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// FloraPolicy decides which flora records a caller may see and change
+type FloraPolicy interface {
+	// Privileged reports whether the caller may act on every record
+	Privileged(c *fiber.Ctx) bool
+	// Allowed reports whether the caller may perform the action on the record
+	Allowed(c *fiber.Ctx, action Action, flora dto.FloraData) bool
+	// Visible removes the records the caller may not see
+	Visible(c *fiber.Ctx, floraList []dto.FloraData) []dto.FloraData
+}
+
+// rolePolicy lets owners act on their records and privileged roles act on everything
+type rolePolicy struct {
+	privilegedRoles []string
+}
+
+// NewFloraPolicy creates a policy granting full access to the given roles, e.g. admin and curator
+func NewFloraPolicy(privilegedRoles []string) FloraPolicy {
+	return &rolePolicy{privilegedRoles: privilegedRoles}
+}
+
+func (p *rolePolicy) Privileged(c *fiber.Ctx) bool {
+	for _, role := range p.privilegedRoles {
+		if auth.HasRole(c, role) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *rolePolicy) Allowed(c *fiber.Ctx, action Action, flora dto.FloraData) bool {
+	if p.Privileged(c) {
+		return true
+	}
+
+	userId := auth.UserID(c)
+	owner := userId != "" && flora.UserID == userId
+
+	switch action {
+	case ActionView:
+		return owner || flora.Type != string(dto.Private)
+	case ActionUpdate, ActionDelete:
+		return owner
+	}
+	return false
+}
+
+func (p *rolePolicy) Visible(c *fiber.Ctx, floraList []dto.FloraData) []dto.FloraData {
+	if p.Privileged(c) {
+		return floraList
+	}
+
+	visible := make([]dto.FloraData, 0, len(floraList))
+	for _, flora := range floraList {
+		if p.Allowed(c, ActionView, flora) {
+			visible = append(visible, flora)
+		}
+	}
+	return visible
+}
@@ -15,23 +15,33 @@
 package flora
 
 import (
-	"fmt"
+	"encoding/json"
+	"errors"
 	"log"
+	"project_chimera/gene_bank_service/internal/auth"
+	"project_chimera/gene_bank_service/internal/cache"
 	"project_chimera/gene_bank_service/internal/dto"
+	"project_chimera/gene_bank_service/internal/errorevent"
 	"project_chimera/gene_bank_service/internal/rabbitmq"
+	"project_chimera/gene_bank_service/internal/submission"
 	"project_chimera/gene_bank_service/pkg/utils"
 	"project_chimera/gene_bank_service/pkg/utils/helpers"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
 // FloraService defines the interface for flora services
 type FloraService interface {
-	GetFlora(c *fiber.Ctx) (dto.FloraResponse, error)
+	GetFlora(c *fiber.Ctx, query dto.FloraQuery) (dto.FloraResponse, error)
 	GetFloraById(c *fiber.Ctx) (dto.FloraResponse, error)
-	PostFlora(c *fiber.Ctx) error
-	PutFlora(c *fiber.Ctx) error
+	GetFloraImage(c *fiber.Ctx) ([]byte, string, error)
+	PostFlora(c *fiber.Ctx) (submission.Submission, error)
+	PutFlora(c *fiber.Ctx) (submission.Submission, error)
+	GetSubmission(c *fiber.Ctx) (submission.Submission, error)
 	DeleteFlora(c *fiber.Ctx) error
+	RestoreFlora(c *fiber.Ctx) error
 }
 
 // FloraService is the concrete implementation of FloraService
@@ -40,134 +50,282 @@ type floraService struct {
 
 	downStreamHandler *rabbitmq.Handler
 
-	errorQueueHandler *rabbitmq.Handler
+	reporter *errorevent.Reporter
+
+	submissions *submission.Store
+
+	policy FloraPolicy
+
+	cache cache.Cache
+
+	thumbnails *thumbnailBackfill
 }
 
-func NewFloraService(upStreamHandler *rabbitmq.Handler, downStreamHandler *rabbitmq.Handler, errorQueueHandler *rabbitmq.Handler) FloraService {
-	return &floraService{upStreamHandler: upStreamHandler, downStreamHandler: downStreamHandler, errorQueueHandler: errorQueueHandler}
+func NewFloraService(upStreamHandler *rabbitmq.Handler, downStreamHandler *rabbitmq.Handler, reporter *errorevent.Reporter, submissions *submission.Store, policy FloraPolicy, store cache.Cache) FloraService {
+	if store == nil {
+		store = cache.Noop{}
+	}
+	return &floraService{upStreamHandler: upStreamHandler, downStreamHandler: downStreamHandler, reporter: reporter, submissions: submissions, policy: policy, cache: store, thumbnails: newThumbnailBackfill(upStreamHandler)}
 }
 
 // GetFlora handler for retrieving flora data
-func (s *floraService) GetFlora(c *fiber.Ctx) (dto.FloraResponse, error) {
-	res, err := s.downStreamHandler.SendRequest(c, "get_all_floras", "")
+func (s *floraService) GetFlora(c *fiber.Ctx, query dto.FloraQuery) (dto.FloraResponse, error) {
+	// Private records of other users are hidden from callers without a privileged role
+	if !s.policy.Privileged(c) {
+		query.HidePrivate = true
+		query.Viewer = auth.UserID(c)
+	}
+
+	// Pages with full images are too large to keep around
+	cacheKey := ""
+	if query.Image != imageSizeFull {
+		if key, err := listCacheKey(c, query); err == nil {
+			cacheKey = key
+		}
+	}
+	if cacheKey != "" {
+		if entry, ok := s.cache.Get(cacheKey); ok {
+			var response dto.FloraResponse
+			if err := json.Unmarshal(entry.Value, &response); err == nil {
+				response.LastModified = entry.StoredAt
+				return response, nil
+			}
+		}
+	}
+
+	res, err := s.downStreamHandler.SendRequest(c, "get_all_floras", query)
 	if err != nil {
 		log.Printf("Error in SendRequest: %v", err)
 
-		data := map[string]interface{}{
-			"code":   500,
-			"status": "Internal Server Error",
-			"type":   "GET",
-			"data": map[string]interface{}{
+		// Calls rejected by an open circuit are not reported, that would only add load during the outage
+		if !rabbitmq.IsCircuitOpen(err) {
+			s.reporter.Report(c, errorevent.FloraGetAll, 500, errorevent.Data{
 				"error": err.Error(),
-			},
+			})
 		}
-
-		s.errorQueueHandler.SendAckRequest(data, "flora.getall", true)
 		return dto.FloraResponse{}, err
 	}
 
 	if res.Code != utils.SUCCESS {
-		data := map[string]interface{}{
-			"code":   res.Code,
-			"status": res.Status,
-			"type":   "GET",
-			"data":   res.Data,
-		}
-
-		s.errorQueueHandler.SendAckRequest(data, "flora.getall", true)
+		s.reporter.Report(c, errorevent.FloraGetAll, res.Code, res.Data)
 		return dto.FloraResponse{}, helpers.HandleRPCError(res)
 	}
 
 	floraList, err := helpers.ProcessFloraData(res.Data)
 	if err != nil {
-		data := map[string]interface{}{
-			"code":   500,
-			"status": "Internal Server Error",
-			"type":   "GET",
-			"data": map[string]interface{}{
-				"error": err.Error(),
-			},
-		}
-
-		s.errorQueueHandler.SendAckRequest(data, "flora.getall", true)
+		s.reporter.Report(c, errorevent.FloraGetAll, 500, errorevent.Data{
+			"error": err.Error(),
+		})
 		return dto.FloraResponse{}, err
 	}
 
-	return dto.FloraResponse{Flora: floraList}, nil
+	// Older downstream versions ignore the query and send every record back without a total
+	total := res.Total
+	if total == 0 && len(floraList) > 0 {
+		floraList, total = helpers.ApplyFloraQuery(s.policy.Visible(c, floraList), query)
+	} else {
+		// Paginating downstream versions filter with hide_private, this only guards against leaks
+		floraList = s.policy.Visible(c, floraList)
+	}
+
+	response := dto.FloraResponse{
+		Flora: s.withThumbnails(c, floraList, query.Image == imageSizeFull),
+		Total: total,
+		Page:  query.Page,
+		Size:  query.Size,
+		Links: helpers.BuildPageLinks(c.BaseURL()+c.Path(), query, total),
+	}
+	if query.Page < helpers.LastPage(total, query.Size) {
+		response.NextCursor = helpers.EncodeCursor(query.Page + 1)
+	}
+
+	if cacheKey != "" {
+		cacheJSON(s.cache, cacheKey, response)
+	}
+	response.LastModified = time.Now()
+
+	return response, nil
 }
 
 func (s *floraService) GetFloraById(c *fiber.Ctx) (dto.FloraResponse, error) {
-	res, err := s.downStreamHandler.SendRequest(c, "get_flora_by_id", c.Params("id"))
+	floraList, fetchedAt, err := s.findCachedFlora(c, c.Params("id"))
 	if err != nil {
-		data := map[string]interface{}{
-			"code":   500,
-			"status": "Internal Server Error",
-			"type":   "GET",
-			"data": map[string]interface{}{
-				"error": err.Error(),
-			},
+		return dto.FloraResponse{}, err
+	}
+
+	// Private records of other users are reported as missing, so their existence is not revealed
+	if len(floraList) > 0 && !s.policy.Allowed(c, ActionView, floraList[0]) {
+		s.reportDenied(c, ActionView, floraList[0], fiber.StatusNotFound)
+		return dto.FloraResponse{}, &fiber.Error{Code: fiber.StatusNotFound, Message: "Flora not found"}
+	}
+
+	for i := range floraList {
+		floraList[i].ImageURL = imageURL(c, floraList[i].ID)
+	}
+
+	return dto.FloraResponse{Flora: floraList, LastModified: fetchedAt}, nil
+}
+
+// findCachedFlora serves get_flora_by_id from the cache, the policy is applied by the caller on every read
+func (s *floraService) findCachedFlora(c *fiber.Ctx, id string) ([]dto.FloraData, time.Time, error) {
+	key := floraCacheKey(id)
+	if entry, ok := s.cache.Get(key); ok {
+		var floraList []dto.FloraData
+		if err := json.Unmarshal(entry.Value, &floraList); err == nil {
+			return floraList, entry.StoredAt, nil
 		}
+	}
 
-		s.errorQueueHandler.SendAckRequest(data, "flora.getbyid", true)
-		log.Printf("Error in SendRequest: %v", err)
-		return dto.FloraResponse{}, err
+	floraList, err := s.findFlora(c, id, errorevent.FloraGetByID)
+	if err != nil {
+		return nil, time.Time{}, err
 	}
+	if len(floraList) > 0 {
+		cacheJSON(s.cache, key, floraList)
+	}
+	return floraList, time.Now(), nil
+}
 
-	if res.Code != utils.SUCCESS {
-		data := map[string]interface{}{
-			"code":   res.Code,
-			"status": res.Status,
-			"type":   "GET",
-			"data":   res.Data,
+// findFlora fetches a flora record from the downstream service, reporting failures as the given event.
+// The param is the flora ID, or a floraLookup to include soft deleted records.
+func (s *floraService) findFlora(c *fiber.Ctx, param interface{}, event errorevent.Name) ([]dto.FloraData, error) {
+	res, err := s.downStreamHandler.SendRequest(c, "get_flora_by_id", param)
+	if err != nil {
+		if !rabbitmq.IsCircuitOpen(err) {
+			s.reporter.Report(c, event, 500, errorevent.Data{
+				"error": err.Error(),
+			})
 		}
+		log.Printf("Error in SendRequest: %v", err)
+		return nil, err
+	}
 
-		s.errorQueueHandler.SendAckRequest(data, "flora.getbyid", true)
-		return dto.FloraResponse{}, helpers.HandleRPCError(res)
+	if res.Code != utils.SUCCESS {
+		s.reporter.Report(c, event, res.Code, res.Data)
+		return nil, helpers.HandleRPCError(res)
 	}
 
 	floraList, err := helpers.ProcessFloraData(res.Data)
 	if err != nil {
-		data := map[string]interface{}{
-			"code":   500,
-			"status": "Internal Server Error",
-			"type":   "GET",
-			"data": map[string]interface{}{
-				"error": err.Error(),
-			},
+		s.reporter.Report(c, event, 500, errorevent.Data{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	return floraList, nil
+}
+
+// floraLookup is the get_flora_by_id param asking for soft deleted records as well
+type floraLookup struct {
+	ID             string `json:"id"`
+	IncludeDeleted bool   `json:"include_deleted"`
+}
+
+// authorizeChange looks up the record an update, delete or restore targets and checks the caller may change it.
+// Restores and hard deletes target soft deleted records too, which are only found with deleted set.
+func (s *floraService) authorizeChange(c *fiber.Ctx, id string, action Action, event errorevent.Name, deleted bool) (dto.FloraData, error) {
+	var param interface{} = id
+	if deleted {
+		param = floraLookup{ID: id, IncludeDeleted: true}
+	}
+
+	floraList, err := s.findFlora(c, param, event)
+	if err != nil {
+		return dto.FloraData{}, err
+	}
+
+	if len(floraList) == 0 {
+		s.reporter.Report(c, event, 404, errorevent.Data{
+			"error": "Flora not found",
+			"id":    id,
+		})
+		return dto.FloraData{}, &fiber.Error{Code: fiber.StatusNotFound, Message: "Flora not found"}
+	}
+
+	flora := floraList[0]
+	if !s.policy.Allowed(c, ActionView, flora) {
+		s.reportDenied(c, action, flora, fiber.StatusNotFound)
+		return dto.FloraData{}, &fiber.Error{Code: fiber.StatusNotFound, Message: "Flora not found"}
+	}
+	if !s.policy.Allowed(c, action, flora) {
+		s.reportDenied(c, action, flora, fiber.StatusForbidden)
+		return dto.FloraData{}, &fiber.Error{Code: fiber.StatusForbidden, Message: "You are not allowed to " + string(action) + " this flora"}
+	}
+
+	return flora, nil
+}
+
+// reportDenied reports a request refused by the policy, code is the status returned to the client
+func (s *floraService) reportDenied(c *fiber.Ctx, action Action, flora dto.FloraData, code int) {
+	s.reporter.Report(c, errorevent.FloraForbidden, code, errorevent.Data{
+		"error":    "User is not allowed to " + string(action) + " this flora",
+		"action":   action,
+		"id":       flora.ID,
+		"owner_id": flora.UserID,
+		"user_id":  auth.UserID(c),
+		"roles":    auth.Roles(c),
+	})
+}
+
+// GetFloraImage returns the image of a flora in the size requested by the size query parameter
+func (s *floraService) GetFloraImage(c *fiber.Ctx) ([]byte, string, error) {
+	res, err := s.GetFloraById(c)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(res.Flora) == 0 {
+		return nil, "", &fiber.Error{Code: fiber.StatusNotFound, Message: "Flora not found"}
+	}
+
+	image, contentType, err := s.selectImage(res.Flora[0], c.Query("size"))
+	if err != nil {
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) && fiberErr.Code < fiber.StatusInternalServerError {
+			return nil, "", fiberErr
 		}
 
-		s.errorQueueHandler.SendAckRequest(data, "flora.getbyid", true)
-		return dto.FloraResponse{}, err
+		fiberErr = processError(err)
+		s.reporter.Report(c, errorevent.FloraGetImage, fiberErr.Code, errorevent.Data{
+			"flora_id": c.Params("id"),
+			"error":    err.Error(),
+		})
+		return nil, "", fiberErr
 	}
 
-	return dto.FloraResponse{Flora: floraList}, nil
+	return image, contentType, nil
 }
 
 // PostFlora handler for adding flora data
-func (s *floraService) PostFlora(c *fiber.Ctx) error {
+func (s *floraService) PostFlora(c *fiber.Ctx) (submission.Submission, error) {
 	var payload dto.FloraRequest
 
-	if err := c.BodyParser(&payload); err != nil {
-		data := map[string]interface{}{
-			"code":   400,
-			"status": "Bad Request",
-			"type":   "POST",
-			"data": map[string]interface{}{
-				"error": err.Error(),
-			},
+	uploaded, err := bindFloraRequest(c, &payload)
+	if err != nil {
+		var fiberErr *fiber.Error
+		if !errors.As(err, &fiberErr) {
+			fiberErr = &fiber.Error{Code: fiber.StatusBadRequest, Message: "Invalid request body"}
 		}
 
-		s.errorQueueHandler.SendAckRequest(data, "flora.post", true)
-		return &fiber.Error{Code: fiber.StatusBadRequest, Message: "Invalid JSON"}
+		s.reporter.Report(c, errorevent.FloraPost, fiberErr.Code, errorevent.Data{
+			"error": err.Error(),
+		})
+		return submission.Submission{}, fiberErr
+	}
+	if uploaded != nil {
+		payload.Image = uploaded
+	}
+
+	if err := s.validatePayload(c, payload, errorevent.FloraPost); err != nil {
+		return submission.Submission{}, err
 	}
 
 	// Handle image conversion to byte array
 	var imageBytes []byte
-	var err error
 
 	if payload.ImageURL != "" {
 		// If the image is provided via a URL, fetch it
-		imageBytes, err = utils.FetchImageFromURL(payload.ImageURL)
+		imageBytes, err = utils.FetchImageFromURL(c.UserContext(), payload.ImageURL)
 	} else if payload.ImagePath != "" {
 		// If the image is provided via a local path, read it
 		imageBytes, err = utils.FetchImageFromPath(payload.ImagePath)
@@ -175,104 +333,115 @@ func (s *floraService) PostFlora(c *fiber.Ctx) error {
 		// If the image is provided as a byte array, use it directly
 		imageBytes = payload.Image
 	} else {
-		data := map[string]interface{}{
-			"code":   400,
-			"status": "Bad Request",
-			"type":   "POST",
-			"data": map[string]interface{}{
-				"error": "No image provided",
-			},
-		}
-
-		s.errorQueueHandler.SendAckRequest(data, "flora.post", true)
+		s.reporter.Report(c, errorevent.FloraPost, 400, errorevent.Data{
+			"error": "No image provided",
+		})
 		// Handle case where there is no image provided
-		return &fiber.Error{Code: fiber.StatusBadRequest, Message: "No image URL or path provided"}
+		return submission.Submission{}, &fiber.Error{Code: fiber.StatusBadRequest, Message: "No image URL or path provided"}
 	}
 
 	if err != nil {
-		data := map[string]interface{}{
-			"code":   500,
-			"status": "Internal Server Error",
-			"type":   "POST",
-			"data": map[string]interface{}{
-				"error": err.Error(),
-				"url":   payload.ImageURL,
-			},
-		}
-		s.errorQueueHandler.SendAckRequest(data, "flora.post", true)
-		return &fiber.Error{Code: fiber.StatusInternalServerError, Message: fmt.Sprintf("Error fetching image: %v", err)}
+		fiberErr := fetchError(err)
+		s.reporter.Report(c, errorevent.FloraPost, fiberErr.Code, errorevent.Data{
+			"error": err.Error(),
+			"url":   payload.ImageURL,
+		})
+		return submission.Submission{}, fiberErr
 	}
 
-	userId := c.Get("X-Auth-UserId")
+	// Decode, orient and re-encode the image, which also strips its EXIF data
+	processed, err := utils.ProcessImage(imageBytes)
+	if err != nil {
+		fiberErr := processError(err)
+		s.reporter.Report(c, errorevent.FloraPost, fiberErr.Code, errorevent.Data{
+			"error": err.Error(),
+		})
+		return submission.Submission{}, fiberErr
+	}
 
-	if userId == "" {
-		data := map[string]interface{}{
-			"code":   400,
-			"status": "Bad Request",
-			"type":   "POST",
-			"data": map[string]interface{}{
-				"error": "User ID not found in request",
-			},
-		}
+	userId := auth.UserID(c)
 
-		s.errorQueueHandler.SendAckRequest(data, "flora.post", true)
-		return &fiber.Error{Code: fiber.StatusBadRequest, Message: "User ID not found in request"}
+	if userId == "" {
+		s.reporter.Report(c, errorevent.FloraPost, 400, errorevent.Data{
+			"error": "User ID not found in request",
+		})
+		return submission.Submission{}, &fiber.Error{Code: fiber.StatusBadRequest, Message: "User ID not found in request"}
 	}
 
 	// Send Ack request
 	var data map[string]interface{} = map[string]interface{}{
 		"CommonName":     payload.CommonName,
 		"ScientificName": payload.ScientificName,
-		"Image":          imageBytes,
+		"Image":          processed.Image,
+		"Thumbnail":      processed.Thumbnail,
 		"Description":    payload.Description,
 		"Origin":         payload.Origin,
 		"OtherDetails":   payload.OtherDetails,
 		"type":           payload.Type,
 		"UserId":         userId,
 	}
-	err = s.upStreamHandler.SendAckRequest(data, "add_flora", false)
+	// Register the submission first, its status events can arrive as soon as the command is sent
+	created := s.submissions.Create(uuid.New().String(), "add_flora", userId, "")
+	err = s.upStreamHandler.SendTrackedAckRequest(data, "add_flora", false, created.ID)
 	if err != nil {
-		errData := map[string]interface{}{
-			"code":   500,
-			"status": "Internal Server Error",
-			"type":   "POST",
-			"data": map[string]interface{}{
-				"error": err.Error(),
-			},
-		}
-
-		s.errorQueueHandler.SendAckRequest(errData, "flora.post", true)
-		return err
+		s.submissions.Fail(created.ID, err.Error())
+		s.reporter.Report(c, errorevent.FloraPost, 500, errorevent.Data{
+			"error": err.Error(),
+		})
+		return submission.Submission{}, err
 	}
 
-	return nil
+	return created, nil
 }
 
 // PutFlora handler for updating flora data
-func (s *floraService) PutFlora(c *fiber.Ctx) error {
+func (s *floraService) PutFlora(c *fiber.Ctx) (submission.Submission, error) {
 	var payload dto.FloraUpdateRequest
 
-	if err := c.BodyParser(&payload); err != nil {
-		data := map[string]interface{}{
-			"code":   400,
-			"status": "Bad Request",
-			"type":   "PUT",
-			"data": map[string]interface{}{
-				"error": err.Error(),
-			},
+	uploaded, err := bindFloraRequest(c, &payload)
+	if err != nil {
+		var fiberErr *fiber.Error
+		if !errors.As(err, &fiberErr) {
+			fiberErr = &fiber.Error{Code: fiber.StatusBadRequest, Message: "Invalid request body"}
 		}
 
-		s.errorQueueHandler.SendAckRequest(data, "flora.put", true)
-		return &fiber.Error{Code: fiber.StatusBadRequest, Message: "Invalid JSON"}
+		s.reporter.Report(c, errorevent.FloraPut, fiberErr.Code, errorevent.Data{
+			"error": err.Error(),
+		})
+		return submission.Submission{}, fiberErr
+	}
+	if uploaded != nil {
+		payload.Image = uploaded
+	}
+	if id := c.Params("id"); id != "" {
+		payload.ID = id
+	}
+
+	if err := s.validatePayload(c, payload, errorevent.FloraPut); err != nil {
+		return submission.Submission{}, err
+	}
+
+	userId := auth.UserID(c)
+
+	if userId == "" {
+		s.reporter.Report(c, errorevent.FloraPut, 400, errorevent.Data{
+			"error": "User ID not found in request",
+		})
+		return submission.Submission{}, &fiber.Error{Code: fiber.StatusBadRequest, Message: "User ID not found in request"}
+	}
+
+	// Only the owner or a privileged role may overwrite the record
+	existing, err := s.authorizeChange(c, payload.ID, ActionUpdate, errorevent.FloraPut, false)
+	if err != nil {
+		return submission.Submission{}, err
 	}
 
 	// Handle image conversion to byte array
 	var imageBytes []byte
-	var err error
 
 	if payload.ImageURL != "" {
 		// If the image is provided via a URL, fetch it
-		imageBytes, err = utils.FetchImageFromURL(payload.ImageURL)
+		imageBytes, err = utils.FetchImageFromURL(c.UserContext(), payload.ImageURL)
 	} else if payload.ImagePath != "" {
 		// If the image is provided via a local path, read it
 		imageBytes, err = utils.FetchImageFromPath(payload.ImagePath)
@@ -280,71 +449,154 @@ func (s *floraService) PutFlora(c *fiber.Ctx) error {
 		imageBytes = payload.Image
 	} else {
 		// Handle case where there is no image provided
-		data := map[string]interface{}{
-			"code":   400,
-			"status": "Bad Request",
-			"type":   "PUT",
-			"data": map[string]interface{}{
-				"error": "No image provided",
-			},
-		}
+		s.reporter.Report(c, errorevent.FloraPut, 400, errorevent.Data{
+			"error": "No image provided",
+		})
+		return submission.Submission{}, &fiber.Error{Code: fiber.StatusBadRequest, Message: "No image URL or path provided"}
+	}
 
-		s.errorQueueHandler.SendAckRequest(data, "flora.put", true)
-		return &fiber.Error{Code: fiber.StatusBadRequest, Message: "No image URL or path provided"}
+	if err != nil {
+		fiberErr := fetchError(err)
+		s.reporter.Report(c, errorevent.FloraPut, fiberErr.Code, errorevent.Data{
+			"error": err.Error(),
+			"url":   payload.ImageURL,
+		})
+		return submission.Submission{}, fiberErr
 	}
 
+	// Decode, orient and re-encode the image, which also strips its EXIF data
+	processed, err := utils.ProcessImage(imageBytes)
 	if err != nil {
-		data := map[string]interface{}{
-			"code":   500,
-			"status": "Internal Server Error",
-			"type":   "PUT",
-			"data": map[string]interface{}{
-				"error": err.Error(),
-				"url":   payload.ImageURL,
-			},
-		}
+		fiberErr := processError(err)
+		s.reporter.Report(c, errorevent.FloraPut, fiberErr.Code, errorevent.Data{
+			"error": err.Error(),
+		})
+		return submission.Submission{}, fiberErr
+	}
 
-		s.errorQueueHandler.SendAckRequest(data, "flora.put", true)
-		return &fiber.Error{Code: fiber.StatusInternalServerError, Message: fmt.Sprintf("Error fetching image: %v", err)}
+	// Send Ack request, the record keeps its owner when a privileged role updates it
+	data := utils.CreateFloraDataMap(payload, existing.UserID, processed.Image, processed.Thumbnail)
+	// Register the submission first, its status events can arrive as soon as the command is sent
+	created := s.submissions.Create(uuid.New().String(), "update_flora", userId, payload.ID)
+	err = s.upStreamHandler.SendTrackedAckRequest(data, "update_flora", false, created.ID)
+	if err != nil {
+		s.submissions.Fail(created.ID, err.Error())
+		s.reporter.Report(c, errorevent.FloraPut, 500, errorevent.Data{
+			"error": err.Error(),
+		})
+		return submission.Submission{}, err
 	}
 
-	userId := c.Get("X-Auth-UserId")
+	invalidateFlora(s.cache, payload.ID)
 
-	if userId == "" {
-		data := map[string]interface{}{
-			"code":   400,
-			"status": "Bad Request",
-			"type":   "PUT",
-			"data": map[string]interface{}{
-				"error": "User ID not found in request",
-			},
-		}
+	return created, nil
+}
+
+// validatePayload checks the request DTO and reports every failing field to the error queue
+func (s *floraService) validatePayload(c *fiber.Ctx, payload interface{}, event errorevent.Name) error {
+	err := utils.ValidateStruct(payload)
+	if err == nil {
+		return nil
+	}
+
+	var validationErr *utils.ValidationError
+	if !errors.As(err, &validationErr) {
+		return err
+	}
+
+	s.reporter.Report(c, event, fiber.StatusUnprocessableEntity, errorevent.Data{
+		"error":  validationErr.Error(),
+		"fields": validationErr.Fields,
+	})
+	return validationErr
+}
+
+// GetSubmission returns the processing status of a POST/PUT submission
+func (s *floraService) GetSubmission(c *fiber.Ctx) (submission.Submission, error) {
+	sub, ok := s.submissions.Get(c.Params("id"))
+
+	// Submissions of other users and of anonymous callers are reported as missing
+	userId := auth.UserID(c)
+	if !ok || userId == "" || sub.UserID != userId {
+		return submission.Submission{}, &fiber.Error{Code: fiber.StatusNotFound, Message: "Submission not found"}
+	}
+
+	return sub, nil
+}
 
-		s.errorQueueHandler.SendAckRequest(data, "flora.put", true)
+// DeleteFlora handler for deleting flora data
+func (s *floraService) DeleteFlora(c *fiber.Ctx) error {
+	id := c.Params("id")
+	soft := c.QueryBool("soft", false)
+	userId := auth.UserID(c)
+
+	if userId == "" {
+		s.reporter.Report(c, errorevent.FloraDelete, 400, errorevent.Data{
+			"error": "User ID not found in request",
+		})
 		return &fiber.Error{Code: fiber.StatusBadRequest, Message: "User ID not found in request"}
 	}
 
-	// Send Ack request
-	data := utils.CreateFloraDataMap(payload, userId, imageBytes)
-	err = s.upStreamHandler.SendAckRequest(data, "update_flora", false)
-	if err != nil {
-		errData := map[string]interface{}{
-			"code":   500,
-			"status": "Internal Server Error",
-			"type":   "PUT",
-			"data": map[string]interface{}{
-				"error": err.Error(),
-			},
-		}
+	// Only the owner or a privileged role may delete the record. A hard delete also
+	// purges records that were soft deleted before, so those have to be found too.
+	if _, err := s.authorizeChange(c, id, ActionDelete, errorevent.FloraDelete, !soft); err != nil {
+		return err
+	}
 
-		s.errorQueueHandler.SendAckRequest(errData, "flora.put", true)
+	// Send Ack request, soft deletes can later be undone with restore_flora.
+	// flora_upstream_service repeats the owner check, Privileged lets it accept other owners.
+	data := map[string]interface{}{
+		"ID":         id,
+		"UserId":     userId,
+		"Soft":       soft,
+		"Privileged": s.policy.Privileged(c),
+	}
+	err := s.upStreamHandler.SendAckRequest(data, "delete_flora", false)
+	if err != nil {
+		s.reporter.Report(c, errorevent.FloraDelete, 500, errorevent.Data{
+			"error": err.Error(),
+			"id":    id,
+		})
 		return err
 	}
 
+	invalidateFlora(s.cache, id)
+
 	return nil
 }
 
-// DeleteFlora handler for deleting flora data
-func (s *floraService) DeleteFlora(c *fiber.Ctx) error {
+// RestoreFlora handler for restoring soft deleted flora data
+func (s *floraService) RestoreFlora(c *fiber.Ctx) error {
+	id := c.Params("id")
+	userId := auth.UserID(c)
+
+	if userId == "" {
+		s.reporter.Report(c, errorevent.FloraRestore, 400, errorevent.Data{
+			"error": "User ID not found in request",
+		})
+		return &fiber.Error{Code: fiber.StatusBadRequest, Message: "User ID not found in request"}
+	}
+
+	// Restoring is allowed to whoever may delete the record
+	if _, err := s.authorizeChange(c, id, ActionDelete, errorevent.FloraRestore, true); err != nil {
+		return err
+	}
+
+	data := map[string]interface{}{
+		"ID":         id,
+		"UserId":     userId,
+		"Privileged": s.policy.Privileged(c),
+	}
+	err := s.upStreamHandler.SendAckRequest(data, "restore_flora", false)
+	if err != nil {
+		s.reporter.Report(c, errorevent.FloraRestore, 500, errorevent.Data{
+			"error": err.Error(),
+			"id":    id,
+		})
+		return err
+	}
+
+	invalidateFlora(s.cache, id)
+
 	return nil
 }
@@ -0,0 +1,223 @@
+//	Copyright 2025 Naveen R
+//
+//		Licensed under the Apache License, Version 2.0 (the "License");
+//		you may not use this file except in compliance with the License.
+//		You may obtain a copy of the License at
+//
+//		http://www.apache.org/licenses/LICENSE-2.0
+//
+//		Unless required by applicable law or agreed to in writing, software
+//		distributed under the License is distributed on an "AS IS" BASIS,
+//		WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//		See the License for the specific language governing permissions and
+//		limitations under the License.
+
+package flora
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"project_chimera/gene_bank_service/config"
+	"project_chimera/gene_bank_service/pkg/utils"
+	"project_chimera/gene_bank_service/pkg/utils/helpers"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// defaultMaxImageSize is used when no image size limit is configured
+	defaultMaxImageSize = 5 << 20
+	// maxFieldSize caps each metadata field of a multipart upload
+	maxFieldSize = 64 << 10
+	// maxFormSize caps the metadata fields of a multipart upload together
+	maxFormSize = 256 << 10
+	// maxFormParts caps the number of parts of a multipart upload, the image included
+	maxFormParts = 32
+	// maxJSONBodySlack is the room left for metadata next to a base64 encoded image in a JSON body
+	maxJSONBodySlack = 1 << 20
+)
+
+// imageFormField is the multipart part carrying the image file
+const imageFormField = "image"
+
+// maxImageSize returns the configured image size limit in bytes
+func maxImageSize() int64 {
+	if config.Env.MaxImageSize > 0 {
+		return config.Env.MaxImageSize
+	}
+	return defaultMaxImageSize
+}
+
+// MaxBodySize returns the largest JSON body accepted, base64 grows the image by a third
+// and room is left for the metadata. Multipart bodies stay below it as well.
+func MaxBodySize() int64 {
+	return maxImageSize()*4/3 + maxJSONBodySlack
+}
+
+// bindFloraRequest fills payload from a JSON or a multipart/form-data body.
+// For multipart bodies the image part is streamed, size limited and sniffed,
+// and returned separately from the metadata fields.
+func bindFloraRequest(c *fiber.Ctx, payload interface{}) ([]byte, error) {
+	mediaType, params, _ := mime.ParseMediaType(c.Get(fiber.HeaderContentType))
+	if mediaType != fiber.MIMEMultipartForm {
+		if _, err := helpers.ReadBody(c, MaxBodySize()); err != nil {
+			if errors.Is(err, helpers.ErrBodyTooLarge) {
+				c.Context().SetConnectionClose()
+				return nil, &fiber.Error{Code: fiber.StatusRequestEntityTooLarge, Message: utils.ErrImageTooLarge.Error()}
+			}
+			return nil, err
+		}
+		return nil, c.BodyParser(payload)
+	}
+
+	boundary := params["boundary"]
+	if boundary == "" {
+		return nil, errors.New("multipart boundary not found")
+	}
+
+	body := c.Context().RequestBodyStream()
+	if body == nil {
+		body = bytes.NewReader(c.Body())
+	}
+
+	fields := map[string]interface{}{}
+	var image []byte
+	parts, formSize := 0, 0
+
+	reader := multipart.NewReader(body, boundary)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		parts++
+		if parts > maxFormParts {
+			part.Close()
+			c.Context().SetConnectionClose()
+			return nil, &fiber.Error{Code: fiber.StatusRequestEntityTooLarge, Message: "form has more than " + strconv.Itoa(maxFormParts) + " parts"}
+		}
+
+		if part.FormName() == imageFormField {
+			image, err = readImagePart(part)
+			if err != nil {
+				// Closing the part would read the rest of the rejected image, drop the connection instead
+				c.Context().SetConnectionClose()
+				return nil, err
+			}
+			part.Close()
+			continue
+		}
+
+		value, err := io.ReadAll(io.LimitReader(part, maxFieldSize+1))
+		part.Close()
+		if err != nil {
+			return nil, err
+		}
+		if len(value) > maxFieldSize {
+			c.Context().SetConnectionClose()
+			return nil, &fiber.Error{Code: fiber.StatusRequestEntityTooLarge, Message: "form field " + part.FormName() + " is too large"}
+		}
+		if formSize += len(value); formSize > maxFormSize {
+			c.Context().SetConnectionClose()
+			return nil, &fiber.Error{Code: fiber.StatusRequestEntityTooLarge, Message: "form fields are too large"}
+		}
+
+		// other_details is sent as a JSON object inside the form
+		if part.FormName() == "other_details" {
+			var details map[string]interface{}
+			if err := json.Unmarshal(value, &details); err != nil {
+				return nil, errors.New("other_details must be a JSON object")
+			}
+			fields["other_details"] = details
+			continue
+		}
+		fields[part.FormName()] = strings.TrimSpace(string(value))
+	}
+
+	// Drain what follows the closing boundary, such as the end of a chunked body, so the connection can be reused
+	if _, err := io.Copy(io.Discard, io.LimitReader(body, maxFieldSize)); err != nil {
+		return nil, err
+	}
+
+	// Map the form fields onto the payload through its json tags
+	encoded, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(encoded, payload); err != nil {
+		return nil, err
+	}
+
+	return image, nil
+}
+
+// readImagePart streams the image part, rejecting unsupported types before reading the rest
+func readImagePart(part *multipart.Part) ([]byte, error) {
+	limit := maxImageSize()
+	buffered := bufio.NewReader(part)
+
+	header, _ := buffered.Peek(utils.SniffImageSize)
+	if len(header) == 0 {
+		return nil, nil
+	}
+	if _, err := utils.DetectImageType(header); err != nil {
+		return nil, imageError(err)
+	}
+
+	image, err := io.ReadAll(io.LimitReader(buffered, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(image)) > limit {
+		return nil, imageError(utils.ErrImageTooLarge)
+	}
+
+	return image, nil
+}
+
+// imageError maps image validation errors to their HTTP status
+func imageError(err error) error {
+	switch {
+	case errors.Is(err, utils.ErrImageTooLarge):
+		return &fiber.Error{Code: fiber.StatusRequestEntityTooLarge, Message: err.Error()}
+	case errors.Is(err, utils.ErrUnsupportedImageType):
+		return &fiber.Error{Code: fiber.StatusUnsupportedMediaType, Message: err.Error()}
+	case errors.Is(err, utils.ErrForbiddenImageURL), errors.Is(err, utils.ErrForbiddenImagePath):
+		return &fiber.Error{Code: fiber.StatusBadRequest, Message: err.Error()}
+	case errors.Is(err, utils.ErrImagePathDisabled):
+		return &fiber.Error{Code: fiber.StatusForbidden, Message: err.Error()}
+	case errors.Is(err, utils.ErrInvalidImage):
+		return &fiber.Error{Code: fiber.StatusUnprocessableEntity, Message: err.Error()}
+	}
+	return err
+}
+
+// fetchError maps errors from fetching the image_url or image_path to their HTTP status
+func fetchError(err error) *fiber.Error {
+	var fiberErr *fiber.Error
+	if errors.As(imageError(err), &fiberErr) {
+		return fiberErr
+	}
+	return &fiber.Error{Code: fiber.StatusInternalServerError, Message: fmt.Sprintf("Error fetching image: %v", err)}
+}
+
+// processError maps errors from normalising the image to their HTTP status
+func processError(err error) *fiber.Error {
+	var fiberErr *fiber.Error
+	if errors.As(imageError(err), &fiberErr) {
+		return fiberErr
+	}
+	return &fiber.Error{Code: fiber.StatusInternalServerError, Message: fmt.Sprintf("Error processing image: %v", err)}
+}
@@ -0,0 +1,180 @@
+//	Copyright 2025 Naveen R
+//
+//		Licensed under the Apache License, Version 2.0 (the "License");
+//		you may not use this file except in compliance with the License.
+//		You may obtain a copy of the License at
+//
+//		http://www.apache.org/licenses/LICENSE-2.0
+//
+//		Unless required by applicable law or agreed to in writing, software
+//		distributed under the License is distributed on an "AS IS" BASIS,
+//		WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//		See the License for the specific language governing permissions and
+//		limitations under the License.
+
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"mime"
+	"project_chimera/gene_bank_service/internal/auth"
+	"project_chimera/gene_bank_service/pkg/utils/helpers"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+const (
+	// KeyHeader is the request header carrying the idempotency key
+	KeyHeader = "Idempotency-Key"
+	// ReplayedHeader is set on responses replayed from the store
+	ReplayedHeader = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+	defaultTTL   = 24 * time.Hour
+)
+
+// replayedHeaders are the response headers stored with the body
+var replayedHeaders = []string{fiber.HeaderContentType, fiber.HeaderLocation}
+
+// Middleware replays the first response of a POST or PUT for requests repeating its Idempotency-Key.
+// Keys are scoped to the caller, and reusing a key with a different request is rejected.
+// Only successful responses are stored, so a request that failed can be retried with the same key.
+// Requests with a key and a body over maxBodySize are rejected before the body is hashed.
+func Middleware(backend Backend, ttl time.Duration, maxBodySize int64) fiber.Handler {
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+
+	return func(c *fiber.Ctx) error {
+		if c.Method() != fiber.MethodPost && c.Method() != fiber.MethodPut {
+			return c.Next()
+		}
+
+		key := c.Get(KeyHeader)
+		if key == "" {
+			return c.Next()
+		}
+		if len(key) > maxKeyLength {
+			return &fiber.Error{Code: fiber.StatusBadRequest, Message: "Idempotency-Key must not exceed 255 characters"}
+		}
+
+		// Copy the key, strings read from the Fiber context are only valid during the request
+		key = auth.UserID(c) + ":" + utils.CopyString(key)
+		hash, err := requestHash(c, maxBodySize)
+		if err != nil {
+			return err
+		}
+
+		reserved, err := backend.Reserve(key, Record{RequestHash: hash}, ttl)
+		if err != nil {
+			return err
+		}
+		if !reserved {
+			record, err := backend.Get(key)
+			if err != nil {
+				return err
+			}
+			if record != nil {
+				return replay(c, record, hash)
+			}
+			// The key expired in between, claim it again
+			if reserved, err = backend.Reserve(key, Record{RequestHash: hash}, ttl); err != nil {
+				return err
+			}
+			if !reserved {
+				return &fiber.Error{Code: fiber.StatusConflict, Message: "A request with this Idempotency-Key is still being processed"}
+			}
+		}
+
+		completed := false
+		defer func() {
+			// Release the key when the request failed or panicked, so that it can be retried
+			if !completed {
+				if err := backend.Delete(key); err != nil {
+					log.Printf("Failed to release idempotency key: %v", err)
+				}
+			}
+		}()
+
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		status := c.Response().StatusCode()
+		if status >= fiber.StatusInternalServerError {
+			return nil
+		}
+
+		record := Record{
+			RequestHash: hash,
+			Completed:   true,
+			Status:      status,
+			Headers:     map[string]string{},
+			Body:        utils.CopyBytes(c.Response().Body()),
+		}
+		for _, header := range replayedHeaders {
+			if value := c.GetRespHeader(header); value != "" {
+				record.Headers[header] = utils.CopyString(value)
+			}
+		}
+
+		if err := backend.Set(key, record, ttl); err != nil {
+			log.Printf("Failed to store idempotent response: %v", err)
+			return nil
+		}
+		completed = true
+
+		return nil
+	}
+}
+
+// replay writes the stored response, or rejects the request if it differs from the first one
+func replay(c *fiber.Ctx, record *Record, hash string) error {
+	if record.RequestHash != hash {
+		return &fiber.Error{Code: fiber.StatusUnprocessableEntity, Message: "Idempotency-Key was already used with a different request"}
+	}
+	if !record.Completed {
+		return &fiber.Error{Code: fiber.StatusConflict, Message: "A request with this Idempotency-Key is still being processed"}
+	}
+
+	for header, value := range record.Headers {
+		c.Set(header, value)
+	}
+	c.Set(ReplayedHeader, "true")
+	return c.Status(record.Status).Send(record.Body)
+}
+
+// requestHash fingerprints the method, path and body of the request.
+// Multipart boundaries are left out, clients pick a new one on every retry.
+func requestHash(c *fiber.Ctx, maxBodySize int64) (string, error) {
+	hasher := sha256.New()
+	hasher.Write([]byte(c.Method()))
+	hasher.Write([]byte{0})
+	hasher.Write([]byte(c.Path()))
+	hasher.Write([]byte{0})
+
+	// Reads the body up to the limit, bindFloraRequest falls back to it once the stream is consumed
+	body, err := helpers.ReadBody(c, maxBodySize)
+	if err != nil {
+		if errors.Is(err, helpers.ErrBodyTooLarge) {
+			return "", &fiber.Error{Code: fiber.StatusRequestEntityTooLarge, Message: "Request body is too large"}
+		}
+		return "", err
+	}
+
+	mediaType, params, _ := mime.ParseMediaType(c.Get(fiber.HeaderContentType))
+	hasher.Write([]byte(mediaType))
+	hasher.Write([]byte{0})
+	if boundary := params["boundary"]; mediaType == fiber.MIMEMultipartForm && boundary != "" {
+		body = bytes.ReplaceAll(body, []byte(boundary), nil)
+	}
+	hasher.Write(body)
+
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
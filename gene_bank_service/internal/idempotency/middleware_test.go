@@ -0,0 +1,201 @@
+//	Copyright 2025 Naveen R
+//
+//		Licensed under the Apache License, Version 2.0 (the "License");
+//		you may not use this file except in compliance with the License.
+//		You may obtain a copy of the License at
+//
+//		http://www.apache.org/licenses/LICENSE-2.0
+//
+//		Unless required by applicable law or agreed to in writing, software
+//		distributed under the License is distributed on an "AS IS" BASIS,
+//		WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//		See the License for the specific language governing permissions and
+//		limitations under the License.
+
+package idempotency
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// newApp serves POST /flora behind the middleware, numbering the responses it creates.
+// Requests to /flora/slow wait for release before answering, those to /flora/fail answer 503.
+func newApp(release <-chan struct{}) (*fiber.App, *atomic.Int32) {
+	var calls atomic.Int32
+	app := fiber.New()
+	app.Use(Middleware(NewMemoryBackend(100), time.Hour, 1<<10))
+
+	app.Post("/flora", func(c *fiber.Ctx) error {
+		n := strconv.Itoa(int(calls.Add(1)))
+		c.Set(fiber.HeaderLocation, "/flora/"+n)
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{"id": n})
+	})
+	app.Post("/flora/slow", func(c *fiber.Ctx) error {
+		calls.Add(1)
+		<-release
+		return c.SendStatus(fiber.StatusCreated)
+	})
+	app.Post("/flora/fail", func(c *fiber.Ctx) error {
+		calls.Add(1)
+		return c.SendStatus(fiber.StatusServiceUnavailable)
+	})
+	return app, &calls
+}
+
+func post(t *testing.T, app *fiber.App, path, user, key, body string) *http.Response {
+	t.Helper()
+	req := httptest.NewRequest(fiber.MethodPost, path, strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	if user != "" {
+		req.Header.Set("X-Auth-UserId", user)
+	}
+	if key != "" {
+		req.Header.Set(KeyHeader, key)
+	}
+
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func readBody(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestReplaysStoredResponse(t *testing.T) {
+	app, calls := newApp(nil)
+
+	first := post(t, app, "/flora", "user-1", "key-1", `{"name":"Rosa"}`)
+	second := post(t, app, "/flora", "user-1", "key-1", `{"name":"Rosa"}`)
+
+	if calls.Load() != 1 {
+		t.Fatalf("handler ran %d times, want 1", calls.Load())
+	}
+	if second.StatusCode != fiber.StatusCreated {
+		t.Fatalf("replayed status = %d, want %d", second.StatusCode, fiber.StatusCreated)
+	}
+	if firstBody, secondBody := readBody(t, first), readBody(t, second); firstBody != secondBody {
+		t.Fatalf("replayed body %q, want %q", secondBody, firstBody)
+	}
+	if location := second.Header.Get(fiber.HeaderLocation); location != "/flora/1" {
+		t.Fatalf("replayed Location = %q, want /flora/1", location)
+	}
+	if second.Header.Get(ReplayedHeader) != "true" || first.Header.Get(ReplayedHeader) != "" {
+		t.Fatalf("%s only belongs on the replayed response", ReplayedHeader)
+	}
+}
+
+func TestRejectsKeyReusedWithDifferentRequest(t *testing.T) {
+	app, calls := newApp(nil)
+
+	post(t, app, "/flora", "user-1", "key-1", `{"name":"Rosa"}`)
+
+	tests := []struct {
+		name string
+		path string
+		body string
+	}{
+		{name: "different body", path: "/flora", body: `{"name":"Tulipa"}`},
+		{name: "different path", path: "/flora/fail", body: `{"name":"Rosa"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := post(t, app, tt.path, "user-1", "key-1", tt.body)
+			if resp.StatusCode != fiber.StatusUnprocessableEntity {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, fiber.StatusUnprocessableEntity)
+			}
+		})
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("handler ran %d times, want 1", calls.Load())
+	}
+}
+
+func TestRejectsKeyStillInFlight(t *testing.T) {
+	release := make(chan struct{})
+	app, calls := newApp(release)
+
+	first := make(chan *http.Response)
+	go func() {
+		first <- post(t, app, "/flora/slow", "user-1", "key-1", `{}`)
+	}()
+	for calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if resp := post(t, app, "/flora/slow", "user-1", "key-1", `{}`); resp.StatusCode != fiber.StatusConflict {
+		t.Fatalf("status while in flight = %d, want %d", resp.StatusCode, fiber.StatusConflict)
+	}
+
+	close(release)
+	if resp := <-first; resp.StatusCode != fiber.StatusCreated {
+		t.Fatalf("first request status = %d, want %d", resp.StatusCode, fiber.StatusCreated)
+	}
+	if resp := post(t, app, "/flora/slow", "user-1", "key-1", `{}`); resp.Header.Get(ReplayedHeader) != "true" {
+		t.Fatal("request after completion was not replayed")
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("handler ran %d times, want 1", calls.Load())
+	}
+}
+
+func TestKeysAreScopedPerUser(t *testing.T) {
+	app, calls := newApp(nil)
+
+	first := readBody(t, post(t, app, "/flora", "user-1", "key-1", `{"name":"Rosa"}`))
+	second := readBody(t, post(t, app, "/flora", "user-2", "key-1", `{"name":"Rosa"}`))
+	if calls.Load() != 2 {
+		t.Fatalf("handler ran %d times, want once per user", calls.Load())
+	}
+	if first == second {
+		t.Fatalf("user-2 got the response of user-1: %s", second)
+	}
+
+	// Another user reusing the key with a different body is not a conflict either
+	if resp := post(t, app, "/flora", "user-3", "key-1", `{"name":"Tulipa"}`); resp.StatusCode != fiber.StatusCreated {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, fiber.StatusCreated)
+	}
+}
+
+func TestFailedResponseReleasesKey(t *testing.T) {
+	app, calls := newApp(nil)
+
+	for i := 0; i < 2; i++ {
+		if resp := post(t, app, "/flora/fail", "user-1", "key-1", `{}`); resp.Header.Get(ReplayedHeader) != "" {
+			t.Fatal("failed response was replayed")
+		}
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("handler ran %d times, want the retry to run again", calls.Load())
+	}
+}
+
+func TestRequestsWithoutKeyAreNotStored(t *testing.T) {
+	app, calls := newApp(nil)
+
+	post(t, app, "/flora", "user-1", "", `{}`)
+	post(t, app, "/flora", "user-1", "", `{}`)
+	if calls.Load() != 2 {
+		t.Fatalf("handler ran %d times, want 2", calls.Load())
+	}
+
+	if resp := post(t, app, "/flora", "user-1", strings.Repeat("k", maxKeyLength+1), `{}`); resp.StatusCode != fiber.StatusBadRequest {
+		t.Fatalf("status for an overlong key = %d, want %d", resp.StatusCode, fiber.StatusBadRequest)
+	}
+}
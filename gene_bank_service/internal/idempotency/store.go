@@ -0,0 +1,165 @@
+//	Copyright 2025 Naveen R
+//
+//		Licensed under the Apache License, Version 2.0 (the "License");
+//		you may not use this file except in compliance with the License.
+//		You may obtain a copy of the License at
+//
+//		http://www.apache.org/licenses/LICENSE-2.0
+//
+//		Unless required by applicable law or agreed to in writing, software
+//		distributed under the License is distributed on an "AS IS" BASIS,
+//		WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//		See the License for the specific language governing permissions and
+//		limitations under the License.
+
+package idempotency
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Record is what is kept for an idempotency key
+type Record struct {
+	RequestHash string            // Hash of the request the key was first used with
+	Completed   bool              // False while the first request is still being handled
+	Status      int               // Status code of the first response
+	Headers     map[string]string // Response headers replayed with the body
+	Body        []byte            // Body of the first response
+}
+
+// Backend stores idempotency records. Implementations must make Reserve atomic,
+// e.g. SET NX on Redis, so that two concurrent requests cannot both claim a key.
+type Backend interface {
+	// Get returns the record of a key, or nil if it is unknown or expired
+	Get(key string) (*Record, error)
+	// Reserve stores the record only if the key is not in use and reports whether it did
+	Reserve(key string, record Record, ttl time.Duration) (bool, error)
+	// Set stores the record, replacing the previous one
+	Set(key string, record Record, ttl time.Duration) error
+	// Delete forgets a key
+	Delete(key string) error
+}
+
+// defaultMaxEntries is used when no limit is configured for the memory backend
+const defaultMaxEntries = 10000
+
+type memoryEntry struct {
+	key       string
+	record    Record
+	expiresAt time.Time
+}
+
+// MemoryBackend keeps records in memory, evicting the oldest keys once maxEntries is reached
+type MemoryBackend struct {
+	mu         sync.Mutex
+	entries    map[string]*list.Element
+	order      *list.List // Oldest entry first
+	maxEntries int
+}
+
+// NewMemoryBackend creates an in-memory backend holding at most maxEntries keys
+func NewMemoryBackend(maxEntries int) *MemoryBackend {
+	if maxEntries <= 0 {
+		maxEntries = defaultMaxEntries
+	}
+
+	backend := &MemoryBackend{
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+		maxEntries: maxEntries,
+	}
+
+	go backend.cleanup()
+
+	return backend
+}
+
+func (b *MemoryBackend) Get(key string) (*Record, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	entry := b.lookup(key)
+	if entry == nil {
+		return nil, nil
+	}
+	record := entry.record
+	return &record, nil
+}
+
+func (b *MemoryBackend) Reserve(key string, record Record, ttl time.Duration) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.lookup(key) != nil {
+		return false, nil
+	}
+	b.store(key, record, ttl)
+	return true, nil
+}
+
+func (b *MemoryBackend) Set(key string, record Record, ttl time.Duration) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.store(key, record, ttl)
+	return nil
+}
+
+func (b *MemoryBackend) Delete(key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if element, ok := b.entries[key]; ok {
+		b.remove(element)
+	}
+	return nil
+}
+
+// lookup returns the live entry of a key, dropping it if it expired
+func (b *MemoryBackend) lookup(key string) *memoryEntry {
+	element, ok := b.entries[key]
+	if !ok {
+		return nil
+	}
+	entry := element.Value.(*memoryEntry)
+	if time.Now().After(entry.expiresAt) {
+		b.remove(element)
+		return nil
+	}
+	return entry
+}
+
+// store inserts or replaces an entry and evicts the oldest ones above the limit
+func (b *MemoryBackend) store(key string, record Record, ttl time.Duration) {
+	if element, ok := b.entries[key]; ok {
+		b.remove(element)
+	}
+
+	b.entries[key] = b.order.PushBack(&memoryEntry{key: key, record: record, expiresAt: time.Now().Add(ttl)})
+
+	for b.order.Len() > b.maxEntries {
+		b.remove(b.order.Front())
+	}
+}
+
+func (b *MemoryBackend) remove(element *list.Element) {
+	b.order.Remove(element)
+	delete(b.entries, element.Value.(*memoryEntry).key)
+}
+
+// cleanup periodically removes expired entries so idle keys do not hold memory
+func (b *MemoryBackend) cleanup() {
+	for range time.Tick(time.Minute) {
+		now := time.Now()
+
+		b.mu.Lock()
+		for _, element := range b.entries {
+			if now.After(element.Value.(*memoryEntry).expiresAt) {
+				b.remove(element)
+			}
+		}
+		b.mu.Unlock()
+	}
+}
@@ -0,0 +1,99 @@
+//	Copyright 2025 Naveen R
+//
+//		Licensed under the Apache License, Version 2.0 (the "License");
+//		you may not use this file except in compliance with the License.
+//		You may obtain a copy of the License at
+//
+//		http://www.apache.org/licenses/LICENSE-2.0
+//
+//		Unless required by applicable law or agreed to in writing, software
+//		distributed under the License is distributed on an "AS IS" BASIS,
+//		WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//		See the License for the specific language governing permissions and
+//		limitations under the License.
+
+package problem
+
+import (
+	"errors"
+	"log"
+	"project_chimera/gene_bank_service/pkg/common"
+	"project_chimera/gene_bank_service/pkg/utils"
+	"project_chimera/gene_bank_service/pkg/utils/helpers"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/google/uuid"
+)
+
+// ContentType is the media type of RFC 7807 error bodies
+const ContentType = "application/problem+json"
+
+// Problem type URIs, relative to the service
+const (
+	TypeDefault    = "about:blank"
+	TypeValidation = "/problems/validation-error"
+	TypeDownstream = "/problems/downstream-error"
+)
+
+// TraceIDHeader carries the trace ID between the gateway, this service and the client
+const TraceIDHeader = fiber.HeaderXRequestID
+
+// RequestID returns the middleware assigning every request a trace ID, reusing the one sent by the gateway
+func RequestID() fiber.Handler {
+	return requestid.New(requestid.Config{
+		Header:    TraceIDHeader,
+		Generator: func() string { return uuid.New().String() },
+	})
+}
+
+// TraceID returns the trace ID of the request
+func TraceID(c *fiber.Ctx) string {
+	if id, ok := c.Locals(requestid.ConfigDefault.ContextKey).(string); ok && id != "" {
+		return id
+	}
+	if id := c.Get(TraceIDHeader); id != "" {
+		return id
+	}
+	return c.GetRespHeader(TraceIDHeader)
+}
+
+// ErrorHandler renders every error returned by a handler as application/problem+json
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	problem := common.Problem{
+		Type:     TypeDefault,
+		Status:   fiber.StatusInternalServerError,
+		Instance: c.Path(),
+		TraceID:  TraceID(c),
+	}
+
+	var validationErr *utils.ValidationError
+	var rpcErr *helpers.RPCError
+	var fiberErr *fiber.Error
+	switch {
+	case errors.As(err, &validationErr):
+		problem.Type = TypeValidation
+		problem.Status = fiber.StatusUnprocessableEntity
+		problem.Detail = "One or more fields are invalid"
+		problem.Errors = validationErr.Fields
+	case errors.As(err, &rpcErr):
+		problem.Type = TypeDownstream
+		problem.Status = rpcErr.Status
+		problem.Detail = rpcErr.Message
+		problem.RPCCode = rpcErr.Code
+	case errors.As(err, &fiberErr):
+		problem.Status = fiberErr.Code
+		problem.Detail = fiberErr.Message
+	default:
+		// Unexpected errors may carry internal details, so they are only logged
+		problem.Detail = "An unexpected error occurred"
+	}
+	problem.Title = utils.StatusMessage(problem.Status)
+
+	if problem.Status >= fiber.StatusInternalServerError {
+		log.Printf("[%s] %s %s failed with %d: %v", problem.TraceID, c.Method(), c.OriginalURL(), problem.Status, err)
+	}
+
+	c.Set(TraceIDHeader, problem.TraceID)
+	return c.Status(problem.Status).JSON(problem, ContentType)
+}
@@ -18,9 +18,12 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
+	"project_chimera/gene_bank_service/config"
 	"project_chimera/gene_bank_service/pkg/common"
 	"project_chimera/gene_bank_service/pkg/utils"
+	"strconv"
 	"sync"
 	"time"
 
@@ -28,70 +31,389 @@ import (
 	"github.com/rabbitmq/amqp091-go"
 )
 
+const (
+	// Initial and maximum delay between reconnect attempts
+	reconnectBaseDelay = 500 * time.Millisecond
+	reconnectMaxDelay  = 30 * time.Second
+
+	// defaultConfirmTimeout is used when no publisher confirm timeout is configured
+	defaultConfirmTimeout = 5 * time.Second
+)
+
+var (
+	// ErrConnectionLost is returned to in-flight RPC calls when the broker connection drops
+	ErrConnectionLost = errors.New("RabbitMQ connection lost while waiting for RPC response")
+	// ErrRPCTimeout is returned when the deadline of an RPC call passes before the reply arrives
+	ErrRPCTimeout = errors.New("timeout waiting for RPC response")
+	// ErrRPCCanceled is returned when the caller gives up on an RPC call before the reply arrives
+	ErrRPCCanceled = errors.New("RPC call canceled")
+	// ErrPublishFailed is returned when an Ack-based command was not confirmed after all attempts
+	ErrPublishFailed = errors.New("failed to publish message")
+)
+
+// rpcReply carries either the response body or the error for a pending RPC call
+type rpcReply struct {
+	body []byte
+	err  error
+}
+
 // RabbitMQClient handles both RPC and Ack-based messaging
 type RabbitMQClient struct {
+	url         string
+	mu          sync.RWMutex
 	conn        *amqp091.Connection
 	channel     *amqp091.Channel
+	publisher   publisher // The channel as used for publishing, a fake in tests
 	replyQueue  amqp091.Queue
+	connClose   chan *amqp091.Error
+	chanClose   chan *amqp091.Error
+	confirms    *confirmTracker
+	publishMu   sync.Mutex
 	responseMap sync.Map
+	consuming   bool
+	eventQueues map[string]func(amqp091.Delivery) error
+	broadcasts  map[string]broadcast
+	done        chan struct{}
+	closeOnce   sync.Once
+}
+
+// publisher is the part of a channel in confirm mode that publishing needs
+type publisher interface {
+	GetNextPublishSeqNo() uint64
+	PublishWithContext(ctx context.Context, exchange string, key string, mandatory bool, immediate bool, msg amqp091.Publishing) error
+}
+
+// broadcast relays a shared event queue to a fanout exchange every instance consumes
+type broadcast struct {
+	queueName string
+	handler   func(amqp091.Delivery) error
 }
 
 // NewRabbitMQClient initializes the RabbitMQ connection
 func NewRabbitMQClient(rabbitURL string) (*RabbitMQClient, error) {
-	conn, err := amqp091.Dial(rabbitURL)
-	if err != nil {
+	client := &RabbitMQClient{
+		url:         rabbitURL,
+		done:        make(chan struct{}),
+		eventQueues: make(map[string]func(amqp091.Delivery) error),
+		broadcasts:  make(map[string]broadcast),
+	}
+
+	if err := client.connect(); err != nil {
 		return nil, err
 	}
 
+	log.Println("Connected to RabbitMQ")
+
+	go client.handleReconnect()
+
+	return client, nil
+}
+
+// connect opens the connection (unless it is still alive), the channel and the exclusive reply queue
+func (c *RabbitMQClient) connect() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	conn, connClose := c.conn, c.connClose
+	if conn == nil || conn.IsClosed() {
+		var err error
+		conn, err = amqp091.Dial(c.url)
+		if err != nil {
+			return err
+		}
+		// Listen once per connection, a recovered channel keeps using the listener of its connection
+		connClose = conn.NotifyClose(make(chan *amqp091.Error, 1))
+	}
+
 	ch, err := conn.Channel()
 	if err != nil {
 		conn.Close()
-		return nil, err
+		return err
 	}
 
 	replyQueue, err := ch.QueueDeclare(
 		"", false, false, true, false, nil,
 	)
 	if err != nil {
+		ch.Close()
 		conn.Close()
+		return err
+	}
+
+	// Publisher confirms let SendAckCommand know the broker took the message
+	if err := ch.Confirm(false); err != nil {
 		ch.Close()
-		return nil, err
+		conn.Close()
+		return err
 	}
 
-	log.Println("Connected to RabbitMQ")
+	c.conn = conn
+	c.channel = ch
+	c.publisher = ch
+	c.replyQueue = replyQueue
+	c.connClose = connClose
+	c.chanClose = ch.NotifyClose(make(chan *amqp091.Error, 1))
+	c.confirms = newConfirmTracker(
+		ch.NotifyPublish(make(chan amqp091.Confirmation, 64)),
+		ch.NotifyReturn(make(chan amqp091.Return)),
+	)
 
-	return &RabbitMQClient{
-		conn:       conn,
-		channel:    ch,
-		replyQueue: replyQueue,
-	}, nil
+	return nil
+}
+
+// handleReconnect watches the connection and channel and recovers them when the broker goes away
+func (c *RabbitMQClient) handleReconnect() {
+	for {
+		c.mu.RLock()
+		connClose, chanClose := c.connClose, c.chanClose
+		c.mu.RUnlock()
+
+		var closeErr *amqp091.Error
+		select {
+		case <-c.done:
+			return
+		case closeErr = <-connClose:
+		case closeErr = <-chanClose:
+		}
+
+		select {
+		case <-c.done:
+			return
+		default:
+		}
+
+		log.Printf("RabbitMQ connection or channel closed: %v, reconnecting", closeErr)
+
+		// Nothing will answer on the old reply queue, fail the waiting callers now
+		c.failPending(ErrConnectionLost)
+
+		if !c.reconnect() {
+			return
+		}
+
+		c.mu.RLock()
+		consuming := c.consuming
+		eventQueues := make(map[string]func(amqp091.Delivery) error, len(c.eventQueues))
+		for queueName, handler := range c.eventQueues {
+			eventQueues[queueName] = handler
+		}
+		broadcasts := make(map[string]broadcast, len(c.broadcasts))
+		for exchange, b := range c.broadcasts {
+			broadcasts[exchange] = b
+		}
+		c.mu.RUnlock()
+
+		if consuming {
+			if err := c.consume(); err != nil {
+				log.Printf("Failed to restart consumer after reconnect: %v", err)
+				c.closeChannel()
+				continue
+			}
+		}
+
+		restarted := true
+		for queueName, handler := range eventQueues {
+			if err := c.consumeEvents(queueName, handler); err != nil {
+				log.Printf("Failed to restart event consumer for %s after reconnect: %v", queueName, err)
+				restarted = false
+				break
+			}
+		}
+		for exchange, b := range broadcasts {
+			if !restarted {
+				break
+			}
+			if err := c.consumeBroadcast(b.queueName, exchange, b.handler); err != nil {
+				log.Printf("Failed to restart broadcast consumer for %s after reconnect: %v", exchange, err)
+				restarted = false
+			}
+		}
+		if !restarted {
+			c.closeChannel()
+			continue
+		}
+
+		log.Println("Reconnected to RabbitMQ")
+	}
+}
+
+// reconnect retries connect with exponential backoff, it returns false when the client is closed
+func (c *RabbitMQClient) reconnect() bool {
+	delay := reconnectBaseDelay
+
+	for attempt := 1; ; attempt++ {
+		err := c.connect()
+		if err == nil {
+			return true
+		}
+
+		log.Printf("Failed to reconnect to RabbitMQ (attempt %d), retrying in %s: %v", attempt, delay, err)
+
+		select {
+		case <-c.done:
+			return false
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+		if delay > reconnectMaxDelay {
+			delay = reconnectMaxDelay
+		}
+	}
+}
+
+// closeChannel closes the current channel so the watcher starts another recovery round
+func (c *RabbitMQClient) closeChannel() {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.channel != nil {
+		c.channel.Close()
+	}
+}
+
+// failPending completes every in-flight RPC call with the given error
+func (c *RabbitMQClient) failPending(err error) {
+	c.responseMap.Range(func(key, _ interface{}) bool {
+		if ch, ok := c.responseMap.LoadAndDelete(key); ok {
+			ch.(chan rpcReply) <- rpcReply{err: err}
+		}
+		return true
+	})
 }
 
 // StartConsumer listens for responses in the background
 func (c *RabbitMQClient) StartConsumer() {
+	c.mu.Lock()
+	c.consuming = true
+	c.mu.Unlock()
+
+	if err := c.consume(); err != nil {
+		log.Fatalf("Failed to start consumer: %v", err)
+	}
+}
+
+// consume starts delivering replies from the current reply queue to the waiting callers
+func (c *RabbitMQClient) consume() error {
+	c.mu.RLock()
+	ch, replyQueue := c.channel, c.replyQueue
+	c.mu.RUnlock()
+
+	msgs, err := ch.Consume(
+		replyQueue.Name, "", false, false, false, false, nil, // Manual ACK
+	)
+	if err != nil {
+		return err
+	}
+
 	go func() {
-		msgs, err := c.channel.Consume(
-			c.replyQueue.Name, "", false, false, false, false, nil, // Manual ACK
-		)
-		if err != nil {
-			log.Fatalf("Failed to start consumer: %v", err)
-			return
+		// The loop ends when the channel closes, recovery is done by handleReconnect
+		for msg := range msgs {
+			if ch, ok := c.responseMap.LoadAndDelete(msg.CorrelationId); ok {
+				ch.(chan rpcReply) <- rpcReply{body: msg.Body} // Send response to the corresponding request
+			}
+			msg.Ack(false) // ✅ Manually acknowledge message
 		}
+	}()
+
+	return nil
+}
+
+// StartEventConsumer consumes a durable event queue, the consumer is restarted after a reconnect.
+// The delivery is acked when the handler returns nil and requeued otherwise.
+func (c *RabbitMQClient) StartEventConsumer(queueName string, handler func(amqp091.Delivery) error) error {
+	c.mu.Lock()
+	c.eventQueues[queueName] = handler
+	c.mu.Unlock()
+
+	return c.consumeEvents(queueName, handler)
+}
+
+// consumeEvents declares the event queue and hands its deliveries to the handler
+func (c *RabbitMQClient) consumeEvents(queueName string, handler func(amqp091.Delivery) error) error {
+	ch, _ := c.currentChannel()
+
+	_, err := ch.QueueDeclare(queueName, true, false, false, false, nil)
+	if err != nil {
+		return err
+	}
+
+	msgs, err := ch.Consume(queueName, "", false, false, false, false, nil)
+	if err != nil {
+		return err
+	}
 
+	go func() {
 		for msg := range msgs {
-			if ch, ok := c.responseMap.Load(msg.CorrelationId); ok {
-				responseChan := ch.(chan []byte)
-				responseChan <- msg.Body                // Send response to the corresponding request
-				close(responseChan)                     // Close channel after sending response
-				c.responseMap.Delete(msg.CorrelationId) // Remove from map
-				msg.Ack(false)                          // ✅ Manually acknowledge message
+			if err := handler(msg); err != nil {
+				log.Printf("Failed to handle event from %s: %v", queueName, err)
+				msg.Nack(false, true)
+				continue
 			}
+			msg.Ack(false)
 		}
 	}()
+
+	return nil
+}
+
+// StartBroadcastConsumer hands every event of a shared queue to the handler of every instance.
+// Whichever instance takes an event from the queue relays it to a fanout exchange, and each
+// instance consumes the exchange through its own exclusive queue. The consumers are restarted
+// after a reconnect.
+func (c *RabbitMQClient) StartBroadcastConsumer(queueName string, exchange string, handler func(amqp091.Delivery) error) error {
+	c.mu.Lock()
+	c.broadcasts[exchange] = broadcast{queueName: queueName, handler: handler}
+	c.mu.Unlock()
+
+	return c.consumeBroadcast(queueName, exchange, handler)
 }
 
-// SendRPCCommand sends an RPC request and waits for the response
-func (c *RabbitMQClient) SendRPCCommand(queueName string, cmd string, data interface{}) (common.MessageResponse, error) {
+// consumeBroadcast binds the exclusive queue of this instance to the exchange and relays the shared queue to it
+func (c *RabbitMQClient) consumeBroadcast(queueName string, exchange string, handler func(amqp091.Delivery) error) error {
+	ch, _ := c.currentChannel()
+
+	if err := ch.ExchangeDeclare(exchange, amqp091.ExchangeFanout, true, false, false, false, nil); err != nil {
+		return err
+	}
+	own, err := ch.QueueDeclare("", false, true, true, false, nil)
+	if err != nil {
+		return err
+	}
+	if err := ch.QueueBind(own.Name, "", exchange, false, nil); err != nil {
+		return err
+	}
+
+	// Bind before relaying, so this instance also sees the events it relays itself
+	if err := c.consumeEvents(own.Name, handler); err != nil {
+		return err
+	}
+	return c.consumeEvents(queueName, func(msg amqp091.Delivery) error {
+		return c.relay(exchange, msg)
+	})
+}
+
+// relay republishes a delivery to the exchange and waits for the broker to confirm it
+func (c *RabbitMQClient) relay(exchange string, msg amqp091.Delivery) error {
+	return c.publishConfirmed(exchange, "", confirmTimeout(), amqp091.Publishing{
+		ContentType:   msg.ContentType,
+		Headers:       msg.Headers,
+		MessageId:     uuid.New().String(),
+		CorrelationId: msg.CorrelationId,
+		Body:          msg.Body,
+	})
+}
+
+// currentChannel returns the channel in use, it changes after a reconnect
+func (c *RabbitMQClient) currentChannel() (*amqp091.Channel, string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.channel, c.replyQueue.Name
+}
+
+// SendRPCCommand sends an RPC request and waits for the response until ctx is done
+func (c *RabbitMQClient) SendRPCCommand(ctx context.Context, queueName string, cmd string, data interface{}) (common.MessageResponse, error) {
 	message := common.MessageRequest{
 		Pattern: common.Pattern{
 			Cmd: cmd,
@@ -105,20 +427,27 @@ func (c *RabbitMQClient) SendRPCCommand(queueName string, cmd string, data inter
 	}
 
 	corrID := uuid.New().String()
-	responseChan := make(chan []byte, 1) // Buffered to prevent blocking
+	responseChan := make(chan rpcReply, 1) // Buffered to prevent blocking
 
 	// Store response channel in map
 	c.responseMap.Store(corrID, responseChan)
 
 	// Publish message
-	err = c.channel.PublishWithContext(context.Background(),
-		"", queueName, false, false,
-		amqp091.Publishing{
-			ContentType:   "application/json",
-			Body:          body,
-			ReplyTo:       c.replyQueue.Name,
-			CorrelationId: corrID,
-		})
+	_, replyTo := c.currentChannel()
+	publishing := amqp091.Publishing{
+		ContentType:   "application/json",
+		Body:          body,
+		ReplyTo:       replyTo,
+		CorrelationId: corrID,
+	}
+	// Let the broker drop the request once nobody is waiting for the reply
+	if deadline, ok := ctx.Deadline(); ok {
+		if ttl := time.Until(deadline).Milliseconds(); ttl > 0 {
+			publishing.Expiration = strconv.FormatInt(ttl, 10)
+		}
+	}
+
+	_, err = c.publish(ctx, "", queueName, false, publishing, nil)
 
 	if err != nil {
 		log.Printf("Failed to publish RPC command: %v", err)
@@ -130,11 +459,15 @@ func (c *RabbitMQClient) SendRPCCommand(queueName string, cmd string, data inter
 
 	// Wait for response or timeout
 	select {
-	case responseBody := <-responseChan:
+	case reply := <-responseChan:
+		if reply.err != nil {
+			log.Printf("RPC command failed: %v", reply.err)
+			return common.MessageResponse{}, reply.err
+		}
 		log.Println("Received RPC response successfully")
 
 		var response map[string]interface{}
-		if err := json.Unmarshal(responseBody, &response); err != nil {
+		if err := json.Unmarshal(reply.body, &response); err != nil {
 			log.Printf("Failed to parse RPC response: %v", err)
 			return common.MessageResponse{}, err
 		}
@@ -165,19 +498,42 @@ func (c *RabbitMQClient) SendRPCCommand(queueName string, cmd string, data inter
 			return common.MessageResponse{}, errors.New("data not found in RPC response")
 		}
 
+		// total is only sent back by paginated commands
+		var total int
+		if parsedTotal, ok := response["total"].(float64); ok {
+			total = int(parsedTotal)
+		}
+
 		return common.MessageResponse{
 			Status: status,
 			Code:   code,
 			Data:   data,
+			Total:  total,
 		}, nil
-	case <-time.After(10 * time.Second): // Timeout
-		c.responseMap.Delete(corrID) // Clean up if timeout
-		return common.MessageResponse{}, errors.New("timeout waiting for RPC response")
+	case <-ctx.Done(): // Deadline passed or caller went away
+		c.responseMap.Delete(corrID) // Clean up right away so a late reply is dropped
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return common.MessageResponse{}, ErrRPCTimeout
+		}
+		return common.MessageResponse{}, ErrRPCCanceled
 	}
 }
 
-// SendAckCommand sends a message without waiting for a response (Ack-based)
-func (c *RabbitMQClient) SendAckCommand(queueName string, cmd string, data interface{}, isEvent bool) error {
+// SendAckCommand sends a message without waiting for a response (Ack-based).
+// It returns once the broker confirmed the message, retrying nacked, unroutable
+// or unconfirmed messages up to PUBLISH_MAX_ATTEMPTS times.
+func (c *RabbitMQClient) SendAckCommand(queueName string, cmd string, data interface{}, isEvent bool, headers amqp091.Table) error {
+	return c.sendConfirmed("", queueName, cmd, data, isEvent, headers)
+}
+
+// SendBroadcastEvent publishes an event to the fanout exchange of StartBroadcastConsumer,
+// with the same confirms and retries as SendAckCommand
+func (c *RabbitMQClient) SendBroadcastEvent(exchange string, pattern string, data interface{}) error {
+	return c.sendConfirmed(exchange, "", pattern, data, true, nil)
+}
+
+// sendConfirmed publishes a command or event and retries it until the broker confirms it
+func (c *RabbitMQClient) sendConfirmed(exchange string, routingKey string, cmd string, data interface{}, isEvent bool, headers amqp091.Table) error {
 	var message = map[string]interface{}{
 		"pattern": map[string]string{
 			"cmd": cmd,
@@ -193,26 +549,109 @@ func (c *RabbitMQClient) SendAckCommand(queueName string, cmd string, data inter
 	if err != nil {
 		return err
 	}
-	log.Println("Sent Ack-based command:" + cmd)
 
-	err = c.channel.PublishWithContext(context.Background(),
-		"", queueName, false, false,
-		amqp091.Publishing{
-			ContentType: "application/json",
-			Headers:     amqp091.Table{},
-			Body:        body,
+	maxAttempts := config.Env.PublishMaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	timeout := confirmTimeout()
+
+	if headers == nil {
+		headers = amqp091.Table{}
+	}
+	correlationID, _ := headers[common.SubmissionIDHeader].(string)
+
+	delay := reconnectBaseDelay
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		err = c.publishConfirmed(exchange, routingKey, timeout, amqp091.Publishing{
+			ContentType:   "application/json",
+			Headers:       headers,
+			MessageId:     uuid.New().String(),
+			CorrelationId: correlationID,
+			Body:          body,
 		})
+		if err == nil {
+			log.Println("Sent Ack-based command:" + cmd)
+			return nil
+		}
+
+		log.Printf("Failed to publish Ack command %s (attempt %d/%d): %v", cmd, attempt, maxAttempts, err)
+		if attempt < maxAttempts {
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+
+	return fmt.Errorf("%w: %w", ErrPublishFailed, err)
+}
+
+// confirmTimeout returns how long a publish waits for the broker to confirm it
+func confirmTimeout() time.Duration {
+	if config.Env.PublishConfirmTimeout > 0 {
+		return config.Env.PublishConfirmTimeout
+	}
+	return defaultConfirmTimeout
+}
 
+// publishConfirmed publishes a mandatory message and waits for the broker to confirm it
+func (c *RabbitMQClient) publishConfirmed(exchange string, routingKey string, timeout time.Duration, msg amqp091.Publishing) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var tracker *confirmTracker
+	var done <-chan error
+	tag, err := c.publish(ctx, exchange, routingKey, true, msg, func(confirms *confirmTracker, tag uint64) {
+		tracker = confirms
+		done = confirms.add(tag, msg.MessageId)
+	})
 	if err != nil {
-		log.Printf("Failed to publish Ack command: %v", err)
+		tracker.remove(tag)
 		return err
 	}
-	return nil
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		tracker.remove(tag)
+		return ErrConfirmTimeout
+	}
+}
+
+// publish sends a message on the current channel and returns its delivery tag.
+// Publishes are serialised so the tag handed to track is the one the broker confirms.
+func (c *RabbitMQClient) publish(ctx context.Context, exchange string, routingKey string, mandatory bool, msg amqp091.Publishing, track func(confirms *confirmTracker, tag uint64)) (uint64, error) {
+	c.mu.RLock()
+	channel, confirms := c.publisher, c.confirms
+	c.mu.RUnlock()
+
+	c.publishMu.Lock()
+	defer c.publishMu.Unlock()
+
+	tag := channel.GetNextPublishSeqNo()
+	if track != nil {
+		track(confirms, tag)
+	}
+
+	return tag, channel.PublishWithContext(ctx, exchange, routingKey, mandatory, false, msg)
 }
 
 // CheckQueueStatus checks if a queue exists
 func (c *RabbitMQClient) CheckQueueStatus(queueName string) error {
-	_, err := c.channel.QueueDeclarePassive(queueName, false, false, false, false, nil)
+	c.mu.RLock()
+	conn := c.conn
+	c.mu.RUnlock()
+
+	// A failed passive declare closes the channel, so use a throwaway one
+	// instead of the shared channel that in-flight RPC calls depend on
+	ch, err := conn.Channel()
+	if err != nil {
+		log.Printf("Queue %s is not reachable: %v", queueName, err)
+		return err
+	}
+	defer ch.Close()
+
+	_, err = ch.QueueDeclarePassive(queueName, false, false, false, false, nil)
 	if err != nil {
 		log.Printf("Queue %s is not reachable: %v", queueName, err)
 		return err
@@ -222,7 +661,10 @@ func (c *RabbitMQClient) CheckQueueStatus(queueName string) error {
 
 // CheckRabbitMQStatus checks if the connection is active
 func (c *RabbitMQClient) CheckRabbitMQStatus() error {
-	if c.conn.IsClosed() {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.conn.IsClosed() || c.channel.IsClosed() {
 		log.Println("RabbitMQ connection is closed")
 		return errors.New("RabbitMQ connection is closed")
 	}
@@ -231,6 +673,13 @@ func (c *RabbitMQClient) CheckRabbitMQStatus() error {
 
 // Close RabbitMQ connection
 func (c *RabbitMQClient) Close() {
-	c.channel.Close()
-	c.conn.Close()
+	c.closeOnce.Do(func() {
+		close(c.done)
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		c.channel.Close()
+		c.conn.Close()
+	})
 }
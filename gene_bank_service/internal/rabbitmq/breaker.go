@@ -0,0 +1,195 @@
+//	Copyright 2025 Naveen R
+//
+//		Licensed under the Apache License, Version 2.0 (the "License");
+//		you may not use this file except in compliance with the License.
+//		You may obtain a copy of the License at
+//
+//		http://www.apache.org/licenses/LICENSE-2.0
+//
+//		Unless required by applicable law or agreed to in writing, software
+//		distributed under the License is distributed on an "AS IS" BASIS,
+//		WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//		See the License for the specific language governing permissions and
+//		limitations under the License.
+
+package rabbitmq
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// BreakerState is the state of a circuit breaker
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"    // Calls go through
+	BreakerOpen     BreakerState = "open"      // Calls fail fast
+	BreakerHalfOpen BreakerState = "half-open" // A few probe calls go through
+)
+
+const (
+	defaultBreakerFailures    = 5
+	defaultBreakerOpenTimeout = 30 * time.Second
+	defaultBreakerProbes      = 2
+)
+
+// BreakerConfig tunes a circuit breaker
+type BreakerConfig struct {
+	FailureThreshold int           // Consecutive failures that open the circuit
+	OpenTimeout      time.Duration // How long the circuit stays open before probing
+	HalfOpenProbes   int           // Probes allowed while half-open, all of them have to succeed to close
+}
+
+// BreakerStatus is the state of a circuit breaker as shown by /actuator/health
+type BreakerStatus struct {
+	State     BreakerState `json:"state"`
+	Failures  int          `json:"failures"`            // Consecutive failures
+	OpenedAt  *time.Time   `json:"opened_at,omitempty"` // When the circuit last opened, while not closed
+	LastError string       `json:"last_error,omitempty"`
+}
+
+// CircuitOpenError is returned instead of calling a queue whose circuit is open.
+// It unwraps to a 503 *fiber.Error so it is rendered like the other fiber errors.
+type CircuitOpenError struct {
+	Queue      string
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return "Circuit open for " + e.Queue + ", the service is temporarily unavailable"
+}
+
+func (e *CircuitOpenError) Unwrap() error {
+	return &fiber.Error{Code: fiber.StatusServiceUnavailable, Message: e.Error()}
+}
+
+// IsCircuitOpen reports whether err is a call rejected by an open circuit
+func IsCircuitOpen(err error) bool {
+	var openErr *CircuitOpenError
+	return errors.As(err, &openErr)
+}
+
+// Breaker stops calling a queue after repeated failures and probes it again once OpenTimeout passed
+type Breaker struct {
+	name   string
+	config BreakerConfig
+	now    func() time.Time // Clock, replaced in tests
+
+	mu        sync.Mutex
+	state     BreakerState
+	failures  int
+	openedAt  time.Time
+	probes    int // Probes in flight while half-open
+	successes int // Successful probes while half-open
+	lastError string
+}
+
+// NewBreaker creates a closed circuit breaker, zero config values fall back to the defaults
+func NewBreaker(name string, config BreakerConfig) *Breaker {
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = defaultBreakerFailures
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = defaultBreakerOpenTimeout
+	}
+	if config.HalfOpenProbes <= 0 {
+		config.HalfOpenProbes = defaultBreakerProbes
+	}
+
+	return &Breaker{name: name, config: config, now: time.Now, state: BreakerClosed}
+}
+
+// Allow reports whether a call may go through, returning a *CircuitOpenError if not.
+// Every allowed call has to be followed by Success, Failure or Release.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen {
+		wait := b.config.OpenTimeout - b.now().Sub(b.openedAt)
+		if wait > 0 {
+			return &CircuitOpenError{Queue: b.name, RetryAfter: wait}
+		}
+		b.setState(BreakerHalfOpen)
+		b.probes = 0
+		b.successes = 0
+	}
+
+	if b.state == BreakerHalfOpen {
+		if b.probes >= b.config.HalfOpenProbes {
+			return &CircuitOpenError{Queue: b.name, RetryAfter: time.Second}
+		}
+		b.probes++
+	}
+
+	return nil
+}
+
+// Success records a call that went through
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	if b.state == BreakerHalfOpen {
+		b.successes++
+		if b.successes >= b.config.HalfOpenProbes {
+			b.lastError = ""
+			b.setState(BreakerClosed)
+		}
+	}
+}
+
+// Failure records a call that timed out or failed
+func (b *Breaker) Failure(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.lastError = err.Error()
+
+	// A failed probe opens the circuit again right away
+	if b.state == BreakerHalfOpen || (b.state == BreakerClosed && b.failures >= b.config.FailureThreshold) {
+		b.openedAt = b.now()
+		b.setState(BreakerOpen)
+	}
+}
+
+// Release gives back a probe slot for a call that ended without a verdict, e.g. a canceled request
+func (b *Breaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerHalfOpen && b.probes > 0 {
+		b.probes--
+	}
+}
+
+// Status returns the current state of the breaker
+func (b *Breaker) Status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := BreakerStatus{State: b.state, Failures: b.failures, LastError: b.lastError}
+	// An open circuit past its timeout only moves to half-open on the next call
+	if b.state == BreakerOpen && b.now().Sub(b.openedAt) >= b.config.OpenTimeout {
+		status.State = BreakerHalfOpen
+	}
+	if b.state != BreakerClosed {
+		openedAt := b.openedAt
+		status.OpenedAt = &openedAt
+	}
+	return status
+}
+
+func (b *Breaker) setState(state BreakerState) {
+	if b.state != state {
+		log.Printf("Circuit for %s is now %s", b.name, state)
+	}
+	b.state = state
+}
@@ -0,0 +1,155 @@
+//	Copyright 2025 Naveen R
+//
+//		Licensed under the Apache License, Version 2.0 (the "License");
+//		you may not use this file except in compliance with the License.
+//		You may obtain a copy of the License at
+//
+//		http://www.apache.org/licenses/LICENSE-2.0
+//
+//		Unless required by applicable law or agreed to in writing, software
+//		distributed under the License is distributed on an "AS IS" BASIS,
+//		WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//		See the License for the specific language governing permissions and
+//		limitations under the License.
+
+package rabbitmq
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeClock is moved forward by the tests instead of waiting
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestBreaker(probes int) (*Breaker, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	breaker := NewBreaker("flora_queue", BreakerConfig{FailureThreshold: 3, OpenTimeout: 30 * time.Second, HalfOpenProbes: probes})
+	breaker.now = clock.Now
+	return breaker, clock
+}
+
+func assertState(t *testing.T, breaker *Breaker, want BreakerState) {
+	t.Helper()
+	if got := breaker.Status().State; got != want {
+		t.Fatalf("state = %s, want %s", got, want)
+	}
+}
+
+func assertOpen(t *testing.T, err error, wantRetryAfter time.Duration) {
+	t.Helper()
+	var openErr *CircuitOpenError
+	if !errors.As(err, &openErr) {
+		t.Fatalf("got %v, want a *CircuitOpenError", err)
+	}
+	if openErr.RetryAfter != wantRetryAfter {
+		t.Fatalf("RetryAfter = %s, want %s", openErr.RetryAfter, wantRetryAfter)
+	}
+}
+
+// openBreaker fails calls until the circuit opens
+func openBreaker(t *testing.T, breaker *Breaker) {
+	t.Helper()
+	for i := 0; i < 3; i++ {
+		if err := breaker.Allow(); err != nil {
+			t.Fatalf("call %d rejected while closed: %v", i, err)
+		}
+		breaker.Failure(ErrRPCTimeout)
+	}
+	assertState(t, breaker, BreakerOpen)
+}
+
+func TestBreakerRecoversThroughHalfOpen(t *testing.T) {
+	breaker, clock := newTestBreaker(1)
+
+	// Failures below the threshold, or interrupted by a success, keep the circuit closed
+	breaker.Failure(ErrRPCTimeout)
+	breaker.Failure(ErrRPCTimeout)
+	breaker.Success()
+	breaker.Failure(ErrRPCTimeout)
+	assertState(t, breaker, BreakerClosed)
+	breaker.Success()
+
+	openBreaker(t, breaker)
+	if status := breaker.Status(); status.LastError != ErrRPCTimeout.Error() || status.OpenedAt == nil {
+		t.Fatalf("status %+v does not describe the open circuit", status)
+	}
+
+	clock.Advance(10 * time.Second)
+	assertOpen(t, breaker.Allow(), 20*time.Second)
+
+	clock.Advance(20 * time.Second)
+	assertState(t, breaker, BreakerHalfOpen)
+
+	// Only the single probe goes through while half-open
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("probe rejected: %v", err)
+	}
+	assertOpen(t, breaker.Allow(), time.Second)
+	assertState(t, breaker, BreakerHalfOpen)
+
+	breaker.Success()
+	assertState(t, breaker, BreakerClosed)
+	if status := breaker.Status(); status.LastError != "" || status.OpenedAt != nil {
+		t.Fatalf("closed circuit still reports %+v", status)
+	}
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("call rejected once closed: %v", err)
+	}
+}
+
+func TestBreakerFailedProbeReopens(t *testing.T) {
+	breaker, clock := newTestBreaker(1)
+	openBreaker(t, breaker)
+
+	clock.Advance(30 * time.Second)
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("probe rejected: %v", err)
+	}
+	breaker.Failure(ErrRPCTimeout)
+	assertState(t, breaker, BreakerOpen)
+
+	// The open timeout starts over from the failed probe
+	assertOpen(t, breaker.Allow(), 30*time.Second)
+}
+
+func TestBreakerReleasedProbeFreesItsSlot(t *testing.T) {
+	breaker, clock := newTestBreaker(1)
+	openBreaker(t, breaker)
+	clock.Advance(30 * time.Second)
+
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("probe rejected: %v", err)
+	}
+	breaker.Release()
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("probe after a release rejected: %v", err)
+	}
+	breaker.Success()
+	assertState(t, breaker, BreakerClosed)
+}
+
+func TestBreakerNeedsEveryProbeToSucceed(t *testing.T) {
+	breaker, clock := newTestBreaker(2)
+	openBreaker(t, breaker)
+	clock.Advance(30 * time.Second)
+
+	for i := 0; i < 2; i++ {
+		if err := breaker.Allow(); err != nil {
+			t.Fatalf("probe %d rejected: %v", i, err)
+		}
+	}
+	assertOpen(t, breaker.Allow(), time.Second)
+
+	breaker.Success()
+	assertState(t, breaker, BreakerHalfOpen)
+	breaker.Success()
+	assertState(t, breaker, BreakerClosed)
+}
@@ -0,0 +1,137 @@
+//	Copyright 2025 Naveen R
+//
+//		Licensed under the Apache License, Version 2.0 (the "License");
+//		you may not use this file except in compliance with the License.
+//		You may obtain a copy of the License at
+//
+//		http://www.apache.org/licenses/LICENSE-2.0
+//
+//		Unless required by applicable law or agreed to in writing, software
+//		distributed under the License is distributed on an "AS IS" BASIS,
+//		WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//		See the License for the specific language governing permissions and
+//		limitations under the License.
+
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"project_chimera/gene_bank_service/pkg/common"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CoalescingStats counts how many RPC reads were merged into a call already in flight
+type CoalescingStats struct {
+	Requests      uint64  `json:"requests"`       // Calls to SendRequest
+	RPCs          uint64  `json:"rpcs"`           // RPC commands actually sent
+	Collapsed     uint64  `json:"collapsed"`      // Requests that shared the response of another one
+	CollapseRatio float64 `json:"collapse_ratio"` // Collapsed / Requests
+}
+
+// inflightCall is an RPC shared by every identical request arriving while it runs
+type inflightCall struct {
+	done     chan struct{}
+	response common.MessageResponse
+	err      error
+
+	waiters int                // Callers still waiting, guarded by coalescer.mu
+	cancel  context.CancelFunc // Cancels the RPC once no caller waits for it
+}
+
+// coalescer merges identical concurrent RPC calls, singleflight style
+type coalescer struct {
+	mu    sync.Mutex
+	calls map[string]*inflightCall
+
+	requests  atomic.Uint64
+	rpcs      atomic.Uint64
+	collapsed atomic.Uint64
+}
+
+func newCoalescer() *coalescer {
+	return &coalescer{calls: make(map[string]*inflightCall)}
+}
+
+// do runs fn once for all concurrent callers using the same key. The shared call runs
+// for timeout and ignores the deadline and cancellation of the caller that started it,
+// so a client asking for a short deadline or going away does not fail the others.
+// Each caller stops waiting when its own ctx is done, and the call is canceled once
+// the last one stopped waiting.
+func (c *coalescer) do(ctx context.Context, key string, timeout time.Duration, fn func(context.Context) (common.MessageResponse, error)) (common.MessageResponse, error) {
+	c.requests.Add(1)
+
+	c.mu.Lock()
+	call, shared := c.calls[key]
+	if !shared {
+		callCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+		call = &inflightCall{done: make(chan struct{}), cancel: cancel}
+		c.calls[key] = call
+
+		go func() {
+			defer cancel()
+			response, err := fn(callCtx)
+
+			c.mu.Lock()
+			call.response, call.err = response, err
+			if c.calls[key] == call {
+				delete(c.calls, key)
+			}
+			c.mu.Unlock()
+			close(call.done)
+		}()
+	}
+	call.waiters++
+	c.mu.Unlock()
+
+	if shared {
+		c.collapsed.Add(1)
+	}
+
+	select {
+	case <-call.done:
+		return call.response, call.err
+	case <-ctx.Done():
+		c.leave(key, call)
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return common.MessageResponse{}, ErrRPCTimeout
+		}
+		return common.MessageResponse{}, ErrRPCCanceled
+	}
+}
+
+// leave removes a caller that stopped waiting, canceling the call when it was the last one.
+// The canceled call is forgotten right away, so a new request starts a fresh RPC instead of joining it.
+func (c *coalescer) leave(key string, call *inflightCall) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	call.waiters--
+	if call.waiters > 0 {
+		return
+	}
+	if c.calls[key] == call {
+		delete(c.calls, key)
+	}
+	call.cancel()
+}
+
+// stats returns the counters collected so far
+func (c *coalescer) stats() CoalescingStats {
+	stats := CoalescingStats{
+		Requests:  c.requests.Load(),
+		RPCs:      c.rpcs.Load(),
+		Collapsed: c.collapsed.Load(),
+	}
+	if stats.Requests > 0 {
+		stats.CollapseRatio = float64(stats.Collapsed) / float64(stats.Requests)
+	}
+	return stats
+}
+
+// coalesceKey identifies identical requests, params that cannot be encoded are never merged
+func coalesceKey(queueName string, cmd string, body []byte) string {
+	return queueName + "\x00" + cmd + "\x00" + string(body)
+}
@@ -0,0 +1,123 @@
+//	Copyright 2025 Naveen R
+//
+//		Licensed under the Apache License, Version 2.0 (the "License");
+//		you may not use this file except in compliance with the License.
+//		You may obtain a copy of the License at
+//
+//		http://www.apache.org/licenses/LICENSE-2.0
+//
+//		Unless required by applicable law or agreed to in writing, software
+//		distributed under the License is distributed on an "AS IS" BASIS,
+//		WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//		See the License for the specific language governing permissions and
+//		limitations under the License.
+
+package rabbitmq
+
+import (
+	"context"
+	"project_chimera/gene_bank_service/pkg/common"
+	"testing"
+	"time"
+)
+
+// blockingRPC stands in for an RPC that only returns when its context is done
+func blockingRPC(started chan<- struct{}, stopped chan<- error) func(context.Context) (common.MessageResponse, error) {
+	return func(ctx context.Context) (common.MessageResponse, error) {
+		started <- struct{}{}
+		<-ctx.Done()
+		stopped <- ctx.Err()
+		return common.MessageResponse{}, ctx.Err()
+	}
+}
+
+func TestCallIsCanceledWhenLastWaiterLeaves(t *testing.T) {
+	c := newCoalescer()
+	started := make(chan struct{}, 2)
+	stopped := make(chan error, 2)
+	fn := blockingRPC(started, stopped)
+
+	first, cancelFirst := context.WithCancel(context.Background())
+	second, cancelSecond := context.WithCancel(context.Background())
+	results := make(chan error, 2)
+
+	go func() {
+		_, err := c.do(first, "flora:1", time.Minute, fn)
+		results <- err
+	}()
+	<-started
+	go func() {
+		_, err := c.do(second, "flora:1", time.Minute, fn)
+		results <- err
+	}()
+	waitForWaiters(t, c, "flora:1", 2)
+
+	cancelFirst()
+	if err := <-results; err != ErrRPCCanceled {
+		t.Fatalf("first caller: got %v, want %v", err, ErrRPCCanceled)
+	}
+	select {
+	case err := <-stopped:
+		t.Fatalf("shared call stopped with %v while a caller was still waiting", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cancelSecond()
+	if err := <-results; err != ErrRPCCanceled {
+		t.Fatalf("second caller: got %v, want %v", err, ErrRPCCanceled)
+	}
+	select {
+	case err := <-stopped:
+		if err != context.Canceled {
+			t.Fatalf("shared call stopped with %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("shared call kept running after every caller left")
+	}
+}
+
+func TestCanceledCallIsNotJoined(t *testing.T) {
+	c := newCoalescer()
+	started := make(chan struct{}, 2)
+	stopped := make(chan error, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		c.do(ctx, "flora:1", time.Minute, blockingRPC(started, stopped))
+		close(done)
+	}()
+	<-started
+	cancel()
+	<-done
+
+	response, err := c.do(context.Background(), "flora:1", time.Minute, func(context.Context) (common.MessageResponse, error) {
+		return common.MessageResponse{Status: "ok"}, nil
+	})
+	if err != nil || response.Status != "ok" {
+		t.Fatalf("got (%v, %v), want a fresh call", response, err)
+	}
+	if stats := c.stats(); stats.Collapsed != 0 {
+		t.Fatalf("collapsed = %d, want 0", stats.Collapsed)
+	}
+}
+
+// waitForWaiters waits until n callers share the call running for key
+func waitForWaiters(t *testing.T, c *coalescer, key string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		c.mu.Lock()
+		call := c.calls[key]
+		waiters := 0
+		if call != nil {
+			waiters = call.waiters
+		}
+		c.mu.Unlock()
+		if waiters == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("%d callers never joined %s", n, key)
+}
@@ -0,0 +1,125 @@
+//	Copyright 2025 Naveen R
+//
+//		Licensed under the Apache License, Version 2.0 (the "License");
+//		you may not use this file except in compliance with the License.
+//		You may obtain a copy of the License at
+//
+//		http://www.apache.org/licenses/LICENSE-2.0
+//
+//		Unless required by applicable law or agreed to in writing, software
+//		distributed under the License is distributed on an "AS IS" BASIS,
+//		WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//		See the License for the specific language governing permissions and
+//		limitations under the License.
+
+package rabbitmq
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+var (
+	// ErrPublishNacked is returned when the broker refuses to take responsibility for a message
+	ErrPublishNacked = errors.New("message was nacked by the broker")
+	// ErrUnroutable is returned when a mandatory message could not be routed to any queue
+	ErrUnroutable = errors.New("message could not be routed to a queue")
+	// ErrConfirmTimeout is returned when the broker does not confirm a message in time
+	ErrConfirmTimeout = errors.New("timeout waiting for publisher confirm")
+)
+
+// pendingPublish is a confirmed publish waiting for the broker's ack or nack
+type pendingPublish struct {
+	messageID string
+	done      chan error
+}
+
+// confirmTracker matches publisher confirms and returned messages of one channel to their publishers
+type confirmTracker struct {
+	mu      sync.Mutex
+	pending map[uint64]*pendingPublish
+}
+
+// newConfirmTracker starts tracking the confirms and returns of a channel in confirm mode.
+// The returns channel must be unbuffered: the broker sends basic.return before basic.ack and
+// the library hands both over from the same goroutine, so an unbuffered send guarantees that
+// a return has been seen here before the confirm of the same message.
+func newConfirmTracker(confirms <-chan amqp091.Confirmation, returns <-chan amqp091.Return) *confirmTracker {
+	tracker := &confirmTracker{pending: make(map[uint64]*pendingPublish)}
+	go tracker.run(confirms, returns)
+	return tracker
+}
+
+// add registers a publish by its delivery tag, the returned channel receives the outcome
+func (t *confirmTracker) add(deliveryTag uint64, messageID string) <-chan error {
+	done := make(chan error, 1)
+
+	t.mu.Lock()
+	t.pending[deliveryTag] = &pendingPublish{messageID: messageID, done: done}
+	t.mu.Unlock()
+
+	return done
+}
+
+// remove forgets a publish that failed or is no longer waited for
+func (t *confirmTracker) remove(deliveryTag uint64) {
+	t.mu.Lock()
+	delete(t.pending, deliveryTag)
+	t.mu.Unlock()
+}
+
+// run resolves pending publishes until the channel closes
+func (t *confirmTracker) run(confirms <-chan amqp091.Confirmation, returns <-chan amqp091.Return) {
+	returned := make(map[string]string)
+
+	for {
+		select {
+		case ret, ok := <-returns:
+			if !ok {
+				returns = nil
+				continue
+			}
+			returned[ret.MessageId] = ret.ReplyText
+		case confirm, ok := <-confirms:
+			if !ok {
+				t.failAll(ErrConnectionLost)
+				return
+			}
+
+			t.mu.Lock()
+			publish := t.pending[confirm.DeliveryTag]
+			delete(t.pending, confirm.DeliveryTag)
+			t.mu.Unlock()
+
+			if publish == nil {
+				continue
+			}
+
+			reason, wasReturned := returned[publish.messageID]
+			delete(returned, publish.messageID)
+
+			switch {
+			case !confirm.Ack:
+				publish.done <- ErrPublishNacked
+			case wasReturned:
+				publish.done <- fmt.Errorf("%w: %s", ErrUnroutable, reason)
+			default:
+				publish.done <- nil
+			}
+		}
+	}
+}
+
+// failAll resolves every pending publish with the given error
+func (t *confirmTracker) failAll(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for tag, publish := range t.pending {
+		publish.done <- err
+		delete(t.pending, tag)
+	}
+}
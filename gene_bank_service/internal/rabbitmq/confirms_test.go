@@ -0,0 +1,225 @@
+//	Copyright 2025 Naveen R
+//
+//		Licensed under the Apache License, Version 2.0 (the "License");
+//		you may not use this file except in compliance with the License.
+//		You may obtain a copy of the License at
+//
+//		http://www.apache.org/licenses/LICENSE-2.0
+//
+//		Unless required by applicable law or agreed to in writing, software
+//		distributed under the License is distributed on an "AS IS" BASIS,
+//		WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//		See the License for the specific language governing permissions and
+//		limitations under the License.
+
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"project_chimera/gene_bank_service/config"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// outcome is how the fake broker answers a publish
+type outcome int
+
+const (
+	acked outcome = iota
+	nacked
+	returned // Unroutable, returned and then acked like the broker does
+	lost     // Never confirmed
+)
+
+// fakeChannel stands in for a channel in confirm mode, answering publishes with the scripted outcomes
+type fakeChannel struct {
+	confirms chan amqp091.Confirmation
+	returns  chan amqp091.Return
+
+	mu        sync.Mutex
+	seqNo     uint64
+	outcomes  []outcome // One per publish, the last one repeats
+	published []amqp091.Publishing
+	mandatory []bool
+}
+
+func newFakeChannel(outcomes ...outcome) *fakeChannel {
+	return &fakeChannel{
+		confirms: make(chan amqp091.Confirmation, 64),
+		returns:  make(chan amqp091.Return),
+		seqNo:    1,
+		outcomes: outcomes,
+	}
+}
+
+func (f *fakeChannel) GetNextPublishSeqNo() uint64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.seqNo
+}
+
+func (f *fakeChannel) PublishWithContext(_ context.Context, _ string, _ string, mandatory bool, _ bool, msg amqp091.Publishing) error {
+	f.mu.Lock()
+	tag := f.seqNo
+	f.seqNo++
+	result := f.outcomes[min(len(f.published), len(f.outcomes)-1)]
+	f.published = append(f.published, msg)
+	f.mandatory = append(f.mandatory, mandatory)
+	f.mu.Unlock()
+
+	// Like the library, returns and confirms are handed over from one goroutine, returns first
+	go func() {
+		switch result {
+		case acked:
+			f.confirms <- amqp091.Confirmation{DeliveryTag: tag, Ack: true}
+		case nacked:
+			f.confirms <- amqp091.Confirmation{DeliveryTag: tag, Ack: false}
+		case returned:
+			f.returns <- amqp091.Return{MessageId: msg.MessageId, ReplyText: "NO_ROUTE"}
+			f.confirms <- amqp091.Confirmation{DeliveryTag: tag, Ack: true}
+		}
+	}()
+	return nil
+}
+
+func (f *fakeChannel) publishes() ([]amqp091.Publishing, []bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]amqp091.Publishing(nil), f.published...), append([]bool(nil), f.mandatory...)
+}
+
+func newFakeClient(channel *fakeChannel) *RabbitMQClient {
+	return &RabbitMQClient{
+		publisher: channel,
+		confirms:  newConfirmTracker(channel.confirms, channel.returns),
+	}
+}
+
+// withPublishConfig sets the publish attempts and confirm timeout for the test
+func withPublishConfig(t *testing.T, attempts int, timeout time.Duration) {
+	t.Helper()
+	previous := config.Env
+	config.Env.PublishMaxAttempts = attempts
+	config.Env.PublishConfirmTimeout = timeout
+	t.Cleanup(func() { config.Env = previous })
+}
+
+func TestConfirmTracker(t *testing.T) {
+	tests := []struct {
+		name    string
+		outcome outcome
+		wantErr error
+	}{
+		{name: "ack", outcome: acked},
+		{name: "nack", outcome: nacked, wantErr: ErrPublishNacked},
+		{name: "returned then acked", outcome: returned, wantErr: ErrUnroutable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			channel := newFakeChannel(tt.outcome)
+			tracker := newConfirmTracker(channel.confirms, channel.returns)
+
+			done := tracker.add(1, "message-1")
+			channel.PublishWithContext(context.Background(), "", "flora", true, false, amqp091.Publishing{MessageId: "message-1"})
+
+			select {
+			case err := <-done:
+				if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+					t.Fatalf("got %v, want %v", err, tt.wantErr)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("publish was never resolved")
+			}
+		})
+	}
+}
+
+func TestConfirmTrackerMatchesReturnsByMessageID(t *testing.T) {
+	confirms := make(chan amqp091.Confirmation)
+	returns := make(chan amqp091.Return)
+	tracker := newConfirmTracker(confirms, returns)
+
+	first := tracker.add(1, "message-1")
+	second := tracker.add(2, "message-2")
+
+	returns <- amqp091.Return{MessageId: "message-2", ReplyText: "NO_ROUTE"}
+	confirms <- amqp091.Confirmation{DeliveryTag: 1, Ack: true}
+	confirms <- amqp091.Confirmation{DeliveryTag: 2, Ack: true}
+	// Confirms of publishes nobody waits for any more are ignored
+	confirms <- amqp091.Confirmation{DeliveryTag: 3, Ack: true}
+
+	if err := <-first; err != nil {
+		t.Fatalf("first publish: got %v, want nil", err)
+	}
+	if err := <-second; !errors.Is(err, ErrUnroutable) {
+		t.Fatalf("second publish: got %v, want %v", err, ErrUnroutable)
+	}
+}
+
+func TestConfirmTrackerFailsPendingWhenChannelCloses(t *testing.T) {
+	confirms := make(chan amqp091.Confirmation)
+	tracker := newConfirmTracker(confirms, make(chan amqp091.Return))
+
+	done := tracker.add(1, "message-1")
+	removed := tracker.add(2, "message-2")
+	tracker.remove(2)
+	close(confirms)
+
+	if err := <-done; !errors.Is(err, ErrConnectionLost) {
+		t.Fatalf("got %v, want %v", err, ErrConnectionLost)
+	}
+	select {
+	case err := <-removed:
+		t.Fatalf("removed publish was resolved with %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestSendAckCommandRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		outcomes     []outcome
+		wantErr      error
+		wantAttempts int
+	}{
+		{name: "acked", outcomes: []outcome{acked}, wantAttempts: 1},
+		{name: "nacked then acked", outcomes: []outcome{nacked, acked}, wantAttempts: 2},
+		{name: "always nacked", outcomes: []outcome{nacked}, wantErr: ErrPublishNacked, wantAttempts: 2},
+		{name: "always returned", outcomes: []outcome{returned}, wantErr: ErrUnroutable, wantAttempts: 2},
+		{name: "never confirmed", outcomes: []outcome{lost}, wantErr: ErrConfirmTimeout, wantAttempts: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withPublishConfig(t, 2, 50*time.Millisecond)
+			channel := newFakeChannel(tt.outcomes...)
+
+			err := newFakeClient(channel).SendAckCommand("flora_upstream_queue", "add_flora", map[string]string{"common_name": "Rose"}, false, nil)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("got %v, want nil", err)
+				}
+			} else if !errors.Is(err, ErrPublishFailed) || !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v wrapping %v", err, ErrPublishFailed, tt.wantErr)
+			}
+
+			published, mandatory := channel.publishes()
+			if len(published) != tt.wantAttempts {
+				t.Fatalf("published %d times, want %d", len(published), tt.wantAttempts)
+			}
+			for i, msg := range published {
+				if !mandatory[i] {
+					t.Fatalf("attempt %d was not mandatory", i+1)
+				}
+				if i > 0 && msg.MessageId == published[i-1].MessageId {
+					t.Fatalf("attempt %d reused the message ID of the previous one", i+1)
+				}
+			}
+		})
+	}
+}
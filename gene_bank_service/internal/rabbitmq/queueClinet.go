@@ -15,16 +15,30 @@
 package rabbitmq
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"math"
+	"project_chimera/gene_bank_service/config"
 	"project_chimera/gene_bank_service/pkg/common"
+	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/rabbitmq/amqp091-go"
 )
 
+// defaultRPCTimeout is used when no RPC timeout is configured
+const defaultRPCTimeout = 10 * time.Second
+
 // Handler struct holds dependencies
 type Handler struct {
 	rpcClient *RabbitMQClient
 	queueName string
+	breaker   *Breaker
+	coalescer *coalescer
 }
 
 // NewHandler creates a new Handler instance
@@ -32,33 +46,171 @@ func NewQueueHandler(rpcClient *RabbitMQClient, queueName string) *Handler {
 	return &Handler{
 		rpcClient: rpcClient,
 		queueName: queueName,
+		breaker: NewBreaker(queueName, BreakerConfig{
+			FailureThreshold: config.Env.RPCBreakerFailures,
+			OpenTimeout:      config.Env.RPCBreakerOpenTimeout,
+			HalfOpenProbes:   config.Env.RPCBreakerHalfOpenProbes,
+		}),
+		coalescer: newCoalescer(),
 	}
 }
 
-// SendRequest handles HTTP requests and sends a RPC command to RabbitMQ
-func (h *Handler) SendRequest(c *fiber.Ctx, cmd string, param string) (common.MessageResponse, error) {
+// QueueName returns the name of the queue the handler sends to
+func (h *Handler) QueueName() string {
+	return h.queueName
+}
+
+// BreakerStatus returns the state of the circuit breaker guarding the RPC calls
+func (h *Handler) BreakerStatus() BreakerStatus {
+	return h.breaker.Status()
+}
+
+// CoalescingStats returns how many requests shared the response of an identical one in flight
+func (h *Handler) CoalescingStats() CoalescingStats {
+	return h.coalescer.stats()
+}
+
+// SendRequest handles HTTP requests and sends a RPC command to RabbitMQ.
+// Identical requests in flight share one RPC, and calls fail fast with 503
+// while the circuit of the queue is open.
+func (h *Handler) SendRequest(c *fiber.Ctx, cmd string, param interface{}) (common.MessageResponse, error) {
 	var data = map[string]interface{}{"param": param}
 
-	response, err := h.rpcClient.SendRPCCommand(h.queueName, cmd, data)
+	ctx, cancel, shortened := requestContext(c, cmd)
+	defer cancel()
+
+	var response common.MessageResponse
+	var err error
+	if key, keyErr := json.Marshal(param); keyErr == nil {
+		// The shared call runs for the configured timeout whatever the deadline of this request
+		response, err = h.coalescer.do(ctx, coalesceKey(h.queueName, cmd, key), commandTimeout(cmd), func(ctx context.Context) (common.MessageResponse, error) {
+			return h.sendRPC(ctx, cmd, data, false)
+		})
+	} else {
+		h.coalescer.requests.Add(1)
+		response, err = h.sendRPC(ctx, cmd, data, shortened)
+	}
+
 	if err != nil {
+		var openErr *CircuitOpenError
+		if errors.As(err, &openErr) {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(openErr.RetryAfter.Seconds()))))
+			return common.MessageResponse{}, err
+		}
+
+		switch {
+		case errors.Is(err, ErrRPCTimeout):
+			return common.MessageResponse{}, &fiber.Error{Code: fiber.StatusGatewayTimeout, Message: "Command " + cmd + " timed out"}
+		case errors.Is(err, ErrRPCCanceled), errors.Is(err, ErrConnectionLost):
+			return common.MessageResponse{}, &fiber.Error{Code: fiber.StatusServiceUnavailable, Message: "Command failed with error: " + err.Error()}
+		}
 		return common.MessageResponse{}, &fiber.Error{Code: fiber.StatusInternalServerError, Message: "Command failed with error: " + err.Error()}
 	}
 
 	return response, nil
 }
 
+// sendRPC sends one RPC command through the circuit breaker of the queue
+func (h *Handler) sendRPC(ctx context.Context, cmd string, data interface{}, shortened bool) (common.MessageResponse, error) {
+	h.coalescer.rpcs.Add(1)
+
+	if err := h.breaker.Allow(); err != nil {
+		return common.MessageResponse{}, err
+	}
+
+	response, err := h.rpcClient.SendRPCCommand(ctx, h.queueName, cmd, data)
+	if err != nil {
+		// Canceled calls and timeouts the client asked to be shorter say nothing about the queue
+		if errors.Is(err, ErrRPCCanceled) || (shortened && errors.Is(err, ErrRPCTimeout)) {
+			h.breaker.Release()
+		} else {
+			h.breaker.Failure(err)
+		}
+		return common.MessageResponse{}, err
+	}
+
+	if response.Code >= fiber.StatusInternalServerError {
+		h.breaker.Failure(fmt.Errorf("command %s returned %d", cmd, response.Code))
+	} else {
+		h.breaker.Success()
+	}
+
+	return response, nil
+}
+
+// commandTimeout returns the configured timeout of the command, falling back to the global RPC timeout
+func commandTimeout(cmd string) time.Duration {
+	timeout := config.Env.RPCTimeout
+	if commandTimeout, ok := config.Env.RPCCommandTimeouts[cmd]; ok {
+		timeout = commandTimeout
+	}
+	if timeout <= 0 {
+		timeout = defaultRPCTimeout
+	}
+	return timeout
+}
+
+// requestContext derives the RPC context from the incoming request, it is canceled when the client disconnects.
+// The deadline comes from the X-Request-Timeout header (a duration like "5s" or milliseconds),
+// falling back to the per command and global RPC timeouts, and is capped by RPC_MAX_TIMEOUT.
+// shortened reports whether the client asked for less than the configured timeout.
+func requestContext(c *fiber.Ctx, cmd string) (ctx context.Context, cancel context.CancelFunc, shortened bool) {
+	configured := commandTimeout(cmd)
+	timeout := configured
+
+	if header := c.Get("X-Request-Timeout"); header != "" {
+		if requested, err := time.ParseDuration(header); err == nil && requested > 0 {
+			timeout = requested
+		} else if ms, err := strconv.Atoi(header); err == nil && ms > 0 {
+			timeout = time.Duration(ms) * time.Millisecond
+		}
+	}
+	if config.Env.RPCMaxTimeout > 0 && timeout > config.Env.RPCMaxTimeout {
+		timeout = config.Env.RPCMaxTimeout
+	}
+
+	// disconnect.Middleware cancels the user context when the client disconnects or the server shuts down
+	ctx, cancel = context.WithTimeout(c.UserContext(), timeout)
+	return ctx, cancel, timeout < configured
+}
+
 // SendAckRequest handles HTTP requests and sends an Ack-based command to RabbitMQ
 func (h *Handler) SendAckRequest(data map[string]interface{}, cmd string, isEvent bool) error {
+	return h.SendTrackedAckRequest(data, cmd, isEvent, "")
+}
+
+// SendTrackedAckRequest sends an Ack-based command carrying the submission ID as correlation header
+func (h *Handler) SendTrackedAckRequest(data map[string]interface{}, cmd string, isEvent bool, submissionID string) error {
+	headers := amqp091.Table{}
+	if submissionID != "" {
+		headers[common.SubmissionIDHeader] = submissionID
+	}
 
 	log.Printf("Received request for Ack-based command: %s", cmd)
-	err := h.rpcClient.SendAckCommand(h.queueName, cmd, data, isEvent)
+	err := h.rpcClient.SendAckCommand(h.queueName, cmd, data, isEvent, headers)
 	if err != nil {
+		if errors.Is(err, ErrPublishFailed) {
+			return &fiber.Error{Code: fiber.StatusServiceUnavailable, Message: "Failed to send command, the message broker did not accept it"}
+		}
 		return &fiber.Error{Code: fiber.StatusInternalServerError, Message: "Failed to send command"}
 	}
 
 	return nil
 }
 
+// SendEvent publishes an event body on the handler's queue with the pattern cmd
+func (h *Handler) SendEvent(event interface{}, cmd string) error {
+	err := h.rpcClient.SendAckCommand(h.queueName, cmd, event, true, nil)
+	if err != nil {
+		if errors.Is(err, ErrPublishFailed) {
+			return &fiber.Error{Code: fiber.StatusServiceUnavailable, Message: "Failed to send event, the message broker did not accept it"}
+		}
+		return &fiber.Error{Code: fiber.StatusInternalServerError, Message: "Failed to send event"}
+	}
+
+	return nil
+}
+
 // CheckQueueStatusHandler checks if the queue is reachable
 func (h *Handler) CheckQueueStatusHandler(c *fiber.Ctx) error {
 	err := h.rpcClient.CheckQueueStatus(h.queueName)
@@ -0,0 +1,137 @@
+//	Copyright 2025 Naveen R
+//
+//		Licensed under the Apache License, Version 2.0 (the "License");
+//		you may not use this file except in compliance with the License.
+//		You may obtain a copy of the License at
+//
+//		http://www.apache.org/licenses/LICENSE-2.0
+//
+//		Unless required by applicable law or agreed to in writing, software
+//		distributed under the License is distributed on an "AS IS" BASIS,
+//		WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//		See the License for the specific language governing permissions and
+//		limitations under the License.
+
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Budget is a token bucket refilled with PerMinute tokens a minute and holding at most Burst tokens
+type Budget struct {
+	PerMinute int
+	Burst     int
+}
+
+// Enabled reports whether the budget limits anything, a zero rate disables it
+func (b Budget) Enabled() bool {
+	return b.PerMinute > 0
+}
+
+// burst returns the bucket size, defaulting to one minute worth of tokens
+func (b Budget) burst() float64 {
+	if b.Burst > 0 {
+		return float64(b.Burst)
+	}
+	return float64(b.PerMinute)
+}
+
+// ratePerSecond returns how many tokens are added every second
+func (b Budget) ratePerSecond() float64 {
+	return float64(b.PerMinute) / 60
+}
+
+// Decision is the outcome of taking a token
+type Decision struct {
+	Allowed    bool
+	Limit      int           // Size of the bucket
+	Remaining  int           // Tokens left after this request
+	Reset      time.Duration // Time until the bucket is full again
+	RetryAfter time.Duration // Time until the next token, only set when the request is denied
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// Limiter keeps one token bucket per key
+type Limiter struct {
+	budget Budget
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// NewLimiter creates a limiter for the budget and starts forgetting idle buckets
+func NewLimiter(budget Budget) *Limiter {
+	limiter := &Limiter{
+		budget:  budget,
+		buckets: make(map[string]*bucket),
+	}
+
+	go limiter.cleanup()
+
+	return limiter
+}
+
+// Take removes a token from the bucket of the key if one is available
+func (l *Limiter) Take(key string) Decision {
+	now := time.Now()
+	burst := l.budget.burst()
+	rate := l.budget.ratePerSecond()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, updated: now}
+		l.buckets[key] = b
+	} else {
+		b.tokens = math.Min(burst, b.tokens+now.Sub(b.updated).Seconds()*rate)
+		b.updated = now
+	}
+
+	decision := Decision{Limit: int(burst)}
+	if b.tokens >= 1 {
+		b.tokens--
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = secondsToDuration((1 - b.tokens) / rate)
+	}
+	decision.Remaining = int(b.tokens)
+	decision.Reset = secondsToDuration((burst - b.tokens) / rate)
+
+	return decision
+}
+
+// cleanup removes buckets that refilled completely, they behave like new ones
+func (l *Limiter) cleanup() {
+	burst := l.budget.burst()
+	idle := secondsToDuration(burst / l.budget.ratePerSecond())
+
+	interval := idle
+	if interval < time.Minute {
+		interval = time.Minute
+	}
+
+	for range time.Tick(interval) {
+		cutoff := time.Now().Add(-idle)
+
+		l.mu.Lock()
+		for key, b := range l.buckets {
+			if b.updated.Before(cutoff) {
+				delete(l.buckets, key)
+			}
+		}
+		l.mu.Unlock()
+	}
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,110 @@
+//	Copyright 2025 Naveen R
+//
+//		Licensed under the Apache License, Version 2.0 (the "License");
+//		you may not use this file except in compliance with the License.
+//		You may obtain a copy of the License at
+//
+//		http://www.apache.org/licenses/LICENSE-2.0
+//
+//		Unless required by applicable law or agreed to in writing, software
+//		distributed under the License is distributed on an "AS IS" BASIS,
+//		WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//		See the License for the specific language governing permissions and
+//		limitations under the License.
+
+package ratelimit
+
+import (
+	"math"
+	"project_chimera/gene_bank_service/internal/auth"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Rate limit headers of the IETF RateLimit header fields draft
+const (
+	HeaderLimit     = "RateLimit-Limit"
+	HeaderRemaining = "RateLimit-Remaining"
+	HeaderReset     = "RateLimit-Reset"
+	HeaderPolicy    = "RateLimit-Policy"
+)
+
+// Config holds the budgets of read (GET, HEAD, OPTIONS) and write requests
+type Config struct {
+	Read  Budget
+	Write Budget
+}
+
+// Middleware limits each caller to the read and write budgets. Callers are identified by the
+// user ID of their verified token and fall back to the client IP, so it has to run after
+// auth.Middleware. Without it every caller is limited by IP. Behind a trusted proxy the
+// client IP is the one the proxy added to the fiber.Config ProxyHeader.
+func Middleware(config Config) fiber.Handler {
+	var read, write *Limiter
+	if config.Read.Enabled() {
+		read = NewLimiter(config.Read)
+	}
+	if config.Write.Enabled() {
+		write = NewLimiter(config.Write)
+	}
+
+	return func(c *fiber.Ctx) error {
+		limiter := write
+		if c.Method() == fiber.MethodGet || c.Method() == fiber.MethodHead || c.Method() == fiber.MethodOptions {
+			limiter = read
+		}
+		if limiter == nil {
+			return c.Next()
+		}
+
+		key := "ip:" + clientIP(c)
+		if userId := auth.VerifiedUserID(c); userId != "" {
+			key = "user:" + userId
+		}
+
+		decision := limiter.Take(key)
+		c.Set(HeaderLimit, strconv.Itoa(decision.Limit))
+		c.Set(HeaderRemaining, strconv.Itoa(decision.Remaining))
+		c.Set(HeaderReset, strconv.Itoa(ceilSeconds(decision.Reset)))
+		c.Set(HeaderPolicy, policy(limiter.budget))
+
+		if !decision.Allowed {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(ceilSeconds(decision.RetryAfter)))
+			return &fiber.Error{Code: fiber.StatusTooManyRequests, Message: "Rate limit exceeded, retry after " + strconv.Itoa(ceilSeconds(decision.RetryAfter)) + " seconds"}
+		}
+
+		return c.Next()
+	}
+}
+
+// clientIP returns the address the request came from. When it was sent by a trusted proxy this is
+// the last address of the proxy header, the one the proxy appended itself; earlier ones are sent
+// by the client and cannot be trusted.
+func clientIP(c *fiber.Ctx) string {
+	header := c.App().Config().ProxyHeader
+	if header == "" || !c.IsProxyTrusted() {
+		return c.IP()
+	}
+
+	forwarded := c.Get(header)
+	if i := strings.LastIndexByte(forwarded, ','); i >= 0 {
+		forwarded = forwarded[i+1:]
+	}
+	if ip := strings.TrimSpace(forwarded); ip != "" {
+		return ip
+	}
+	return c.Context().RemoteIP().String()
+}
+
+// policy describes the budget as "<burst>;w=<seconds to refill it>"
+func policy(budget Budget) string {
+	window := time.Duration(budget.burst() / budget.ratePerSecond() * float64(time.Second))
+	return strconv.Itoa(int(budget.burst())) + ";w=" + strconv.Itoa(ceilSeconds(window))
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,82 @@
+//	Copyright 2025 Naveen R
+//
+//		Licensed under the Apache License, Version 2.0 (the "License");
+//		you may not use this file except in compliance with the License.
+//		You may obtain a copy of the License at
+//
+//		http://www.apache.org/licenses/LICENSE-2.0
+//
+//		Unless required by applicable law or agreed to in writing, software
+//		distributed under the License is distributed on an "AS IS" BASIS,
+//		WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//		See the License for the specific language governing permissions and
+//		limitations under the License.
+
+package ratelimit
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// newApp limits writes to one request per client. Requests sent with app.Test come from 0.0.0.0.
+func newApp(trustedProxies []string) *fiber.App {
+	app := fiber.New(fiber.Config{
+		ProxyHeader:             fiber.HeaderXForwardedFor,
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          trustedProxies,
+	})
+	app.Use(Middleware(Config{Write: Budget{PerMinute: 1, Burst: 1}}))
+	app.Post("/", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	return app
+}
+
+func TestClientsBehindProxy(t *testing.T) {
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		forwardedFor   []string
+		want           []int
+	}{
+		{
+			name:           "trusted proxy gives each client its own bucket",
+			trustedProxies: []string{"0.0.0.0"},
+			forwardedFor:   []string{"203.0.113.1", "203.0.113.2", "203.0.113.1"},
+			want:           []int{fiber.StatusNoContent, fiber.StatusNoContent, fiber.StatusTooManyRequests},
+		},
+		{
+			name:           "addresses sent by the client before the proxy are ignored",
+			trustedProxies: []string{"0.0.0.0"},
+			forwardedFor:   []string{"198.51.100.7, 203.0.113.1", "198.51.100.8, 203.0.113.1"},
+			want:           []int{fiber.StatusNoContent, fiber.StatusTooManyRequests},
+		},
+		{
+			name:           "header of an untrusted peer is ignored",
+			trustedProxies: nil,
+			forwardedFor:   []string{"203.0.113.1", "203.0.113.2"},
+			want:           []int{fiber.StatusNoContent, fiber.StatusTooManyRequests},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newApp(tt.trustedProxies)
+			for i, forwardedFor := range tt.forwardedFor {
+				req := httptest.NewRequest(fiber.MethodPost, "/", nil)
+				req.Header.Set(fiber.HeaderXForwardedFor, forwardedFor)
+
+				resp, err := app.Test(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if resp.StatusCode != tt.want[i] {
+					t.Fatalf("request %d from %q: got %d, want %d", i, forwardedFor, resp.StatusCode, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+//	Copyright 2025 Naveen R
+//
+//		Licensed under the Apache License, Version 2.0 (the "License");
+//		you may not use this file except in compliance with the License.
+//		You may obtain a copy of the License at
+//
+//		http://www.apache.org/licenses/LICENSE-2.0
+//
+//		Unless required by applicable law or agreed to in writing, software
+//		distributed under the License is distributed on an "AS IS" BASIS,
+//		WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//		See the License for the specific language governing permissions and
+//		limitations under the License.
+
+package submission
+
+import (
+	"encoding/json"
+	"log"
+	"project_chimera/gene_bank_service/pkg/common"
+	"time"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// statusPattern is the pattern of submission status events
+const statusPattern = "flora.submission"
+
+// statusEvent is the flora.submission event sent by the upstream and error handler services,
+// and by the instances announcing the submissions they created
+type statusEvent struct {
+	Pattern string     `json:"pattern"`
+	Data    statusData `json:"data"`
+}
+
+// statusData is the body of a status event, operation and user_id are only set by announcements
+type statusData struct {
+	SubmissionID string `json:"submission_id"`
+	Status       Status `json:"status"`
+	Operation    string `json:"operation,omitempty"`
+	UserID       string `json:"user_id,omitempty"`
+	FloraID      string `json:"flora_id,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// HandleEvent applies a flora.submission status event to the store.
+// The submission ID is taken from the correlation header, falling back to the payload.
+func (s *Store) HandleEvent(msg amqp091.Delivery) error {
+	var event statusEvent
+	if err := json.Unmarshal(msg.Body, &event); err != nil {
+		log.Printf("Failed to parse submission event: %v", err)
+		return nil // Malformed events can never be applied, drop them
+	}
+
+	submissionID, _ := msg.Headers[common.SubmissionIDHeader].(string)
+	if submissionID == "" {
+		submissionID = event.Data.SubmissionID
+	}
+	if submissionID == "" {
+		log.Printf("Submission event %s has no submission ID", event.Pattern)
+		return nil
+	}
+
+	switch event.Data.Status {
+	case Pending:
+		// Announced by the instance that created the submission, it already knows it
+		now := time.Now().UTC()
+		s.add(Submission{
+			ID:        submissionID,
+			Operation: event.Data.Operation,
+			UserID:    event.Data.UserID,
+			FloraID:   event.Data.FloraID,
+			Status:    Pending,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+		return nil
+	case Succeeded, Failed, AutoFixed:
+	default:
+		log.Printf("Submission event for %s has unknown status %q", submissionID, event.Data.Status)
+		return nil
+	}
+
+	if !s.Update(submissionID, event.Data.Status, event.Data.Error, event.Data.FloraID) {
+		log.Printf("Submission %s is unknown or expired, ignoring %s event", submissionID, event.Data.Status)
+	}
+
+	return nil
+}
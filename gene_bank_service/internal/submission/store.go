@@ -0,0 +1,192 @@
+//	Copyright 2025 Naveen R
+//
+//		Licensed under the Apache License, Version 2.0 (the "License");
+//		you may not use this file except in compliance with the License.
+//		You may obtain a copy of the License at
+//
+//		http://www.apache.org/licenses/LICENSE-2.0
+//
+//		Unless required by applicable law or agreed to in writing, software
+//		distributed under the License is distributed on an "AS IS" BASIS,
+//		WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//		See the License for the specific language governing permissions and
+//		limitations under the License.
+
+package submission
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Status is the processing state of a flora submission
+type Status string
+
+const (
+	Pending   Status = "pending"
+	Succeeded Status = "succeeded"
+	Failed    Status = "failed"
+	AutoFixed Status = "auto-fixed"
+)
+
+// Submission tracks a single add_flora/update_flora command sent upstream
+type Submission struct {
+	ID        string    `json:"id"`                 // Submission ID, sent as correlation header
+	Operation string    `json:"operation"`          // Upstream command (add_flora/update_flora)
+	UserID    string    `json:"user_id"`            // User who submitted the flora
+	FloraID   string    `json:"flora_id,omitempty"` // Flora ID once known
+	Status    Status    `json:"status"`             // Current status of the submission
+	Detail    string    `json:"detail,omitempty"`   // Error or fix description reported by the events
+	CreatedAt time.Time `json:"created_at"`         // When the submission was accepted
+	UpdatedAt time.Time `json:"updated_at"`         // When the status last changed
+}
+
+// Announcer publishes an event to every instance, implemented by *rabbitmq.RabbitMQClient
+type Announcer interface {
+	SendBroadcastEvent(exchange string, pattern string, data interface{}) error
+}
+
+// Store keeps submissions in memory and forgets them once they are older than the TTL.
+// Submissions created or failed here are announced to the other instances, which keep
+// their own copy, so any instance can answer for them.
+type Store struct {
+	mu          sync.RWMutex
+	submissions map[string]*Submission
+	ttl         time.Duration
+	announcer   Announcer
+	exchange    string
+}
+
+// defaultTTL is used when no submission TTL is configured
+const defaultTTL = 24 * time.Hour
+
+// NewStore creates a submission store and starts its cleanup loop.
+// Submissions are announced on the exchange through announcer, which may be nil.
+func NewStore(ttl time.Duration, announcer Announcer, exchange string) *Store {
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+
+	store := &Store{
+		submissions: make(map[string]*Submission),
+		ttl:         ttl,
+		announcer:   announcer,
+		exchange:    exchange,
+	}
+
+	go store.cleanup()
+
+	return store
+}
+
+// Create registers a new pending submission and announces it to the other instances.
+// It has to be called before the command is sent, so that its status events find it.
+func (s *Store) Create(id string, operation string, userID string, floraID string) Submission {
+	now := time.Now().UTC()
+	submission := Submission{
+		ID:        id,
+		Operation: operation,
+		UserID:    userID,
+		FloraID:   floraID,
+		Status:    Pending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	s.add(submission)
+	s.announce(submission)
+
+	return submission
+}
+
+// Fail marks a submission whose command could not be sent as failed on every instance
+func (s *Store) Fail(id string, detail string) {
+	if !s.Update(id, Failed, detail, "") {
+		return
+	}
+	if submission, ok := s.Get(id); ok {
+		s.announce(submission)
+	}
+}
+
+// add registers a submission unless it is already known
+func (s *Store) add(submission Submission) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.submissions[submission.ID]; !ok {
+		s.submissions[submission.ID] = &submission
+	}
+}
+
+// announce publishes the submission to the other instances, which then answer for it as well
+func (s *Store) announce(submission Submission) {
+	if s.announcer == nil {
+		return
+	}
+
+	err := s.announcer.SendBroadcastEvent(s.exchange, statusPattern, statusData{
+		SubmissionID: submission.ID,
+		Status:       submission.Status,
+		Operation:    submission.Operation,
+		UserID:       submission.UserID,
+		FloraID:      submission.FloraID,
+		Error:        submission.Detail,
+	})
+	if err != nil {
+		log.Printf("Failed to announce submission %s, only this instance knows it: %v", submission.ID, err)
+	}
+}
+
+// Get returns a copy of the submission with the given ID
+func (s *Store) Get(id string) (Submission, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	submission, ok := s.submissions[id]
+	if !ok {
+		return Submission{}, false
+	}
+	return *submission, true
+}
+
+// Update records a new status for a submission, unknown IDs are ignored
+func (s *Store) Update(id string, status Status, detail string, floraID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	submission, ok := s.submissions[id]
+	if !ok {
+		return false
+	}
+
+	submission.Status = status
+	submission.Detail = detail
+	if floraID != "" {
+		submission.FloraID = floraID
+	}
+	submission.UpdatedAt = time.Now().UTC()
+
+	return true
+}
+
+// cleanup periodically removes submissions older than the TTL
+func (s *Store) cleanup() {
+	interval := s.ttl / 4
+	if interval < time.Minute {
+		interval = time.Minute
+	}
+
+	for range time.Tick(interval) {
+		cutoff := time.Now().UTC().Add(-s.ttl)
+
+		s.mu.Lock()
+		for id, submission := range s.submissions {
+			if submission.UpdatedAt.Before(cutoff) {
+				delete(s.submissions, id)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
@@ -0,0 +1,120 @@
+//	Copyright 2025 Naveen R
+//
+//		Licensed under the Apache License, Version 2.0 (the "License");
+//		you may not use this file except in compliance with the License.
+//		You may obtain a copy of the License at
+//
+//		http://www.apache.org/licenses/LICENSE-2.0
+//
+//		Unless required by applicable law or agreed to in writing, software
+//		distributed under the License is distributed on an "AS IS" BASIS,
+//		WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//		See the License for the specific language governing permissions and
+//		limitations under the License.
+
+package submission
+
+import (
+	"encoding/json"
+	"errors"
+	"project_chimera/gene_bank_service/pkg/common"
+	"testing"
+	"time"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// fanout delivers announcements to the stores of every instance, like the fanout exchange
+type fanout struct {
+	stores []*Store
+	err    error
+}
+
+func (f *fanout) SendBroadcastEvent(exchange string, pattern string, data interface{}) error {
+	if f.err != nil {
+		return f.err
+	}
+	body, err := json.Marshal(map[string]interface{}{"pattern": pattern, "data": data})
+	if err != nil {
+		return err
+	}
+	for _, store := range f.stores {
+		if err := store.HandleEvent(amqp091.Delivery{Body: body}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func statusDelivery(t *testing.T, id string, status Status) amqp091.Delivery {
+	t.Helper()
+
+	body, err := json.Marshal(statusEvent{Pattern: statusPattern, Data: statusData{Status: status, FloraID: "flora-1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return amqp091.Delivery{Body: body, Headers: amqp091.Table{common.SubmissionIDHeader: id}}
+}
+
+func TestSubmissionsAreSharedBetweenInstances(t *testing.T) {
+	bus := &fanout{}
+	first := NewStore(time.Hour, bus, "events")
+	second := NewStore(time.Hour, bus, "events")
+	bus.stores = []*Store{first, second}
+
+	created := first.Create("sub-1", "add_flora", "user-1", "")
+
+	got, ok := second.Get(created.ID)
+	if !ok {
+		t.Fatal("the other instance does not know the submission")
+	}
+	if got.Status != Pending || got.UserID != "user-1" || got.Operation != "add_flora" {
+		t.Errorf("announced submission = %+v", got)
+	}
+
+	// The status event reaches every instance through the fanout exchange
+	for _, store := range bus.stores {
+		if err := store.HandleEvent(statusDelivery(t, created.ID, Succeeded)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, store := range bus.stores {
+		if got, _ := store.Get(created.ID); got.Status != Succeeded || got.FloraID != "flora-1" {
+			t.Errorf("instance %d: submission = %+v, want succeeded", i, got)
+		}
+	}
+
+	// A late announcement does not reset a status that already arrived
+	if err := second.HandleEvent(statusDelivery(t, created.ID, Pending)); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := second.Get(created.ID); got.Status != Succeeded {
+		t.Errorf("status after a late announcement = %s, want succeeded", got.Status)
+	}
+}
+
+func TestFailIsAnnounced(t *testing.T) {
+	bus := &fanout{}
+	first := NewStore(time.Hour, bus, "events")
+	second := NewStore(time.Hour, bus, "events")
+	bus.stores = []*Store{first, second}
+
+	created := first.Create("sub-1", "update_flora", "user-1", "flora-1")
+	first.Fail(created.ID, "broker unavailable")
+
+	for i, store := range bus.stores {
+		got, _ := store.Get(created.ID)
+		if got.Status != Failed || got.Detail != "broker unavailable" {
+			t.Errorf("instance %d: submission = %+v, want failed", i, got)
+		}
+	}
+}
+
+func TestCreateWithoutAnnouncement(t *testing.T) {
+	store := NewStore(time.Hour, &fanout{err: errors.New("broker unavailable")}, "events")
+
+	created := store.Create("sub-1", "add_flora", "user-1", "")
+	if got, ok := store.Get(created.ID); !ok || got.Status != Pending {
+		t.Errorf("submission = %+v, %v, want it pending on the instance that created it", got, ok)
+	}
+}
@@ -18,8 +18,33 @@ type SuccessResponse struct {
 	Status string `json:"status"`
 }
 
-type ErrorResponse struct {
-	Error string `json:"error"`
+// SubmissionIDHeader carries the submission ID on upstream commands and the events that come back
+const SubmissionIDHeader = "x-submission-id"
+
+type SubmissionResponse struct {
+	Status       string `json:"status"`
+	SubmissionID string `json:"submission_id"`
+	StatusURL    string `json:"status_url"`
+}
+
+// Problem is an RFC 7807 application/problem+json error body
+type Problem struct {
+	Type     string       `json:"type"`               // URI reference identifying the problem type
+	Title    string       `json:"title"`              // Short summary of the problem type
+	Status   int          `json:"status"`             // HTTP status code
+	Detail   string       `json:"detail,omitempty"`   // Explanation specific to this occurrence
+	Instance string       `json:"instance,omitempty"` // Request path the problem occurred on
+	TraceID  string       `json:"trace_id"`           // Request ID, also sent as the X-Request-ID header
+	RPCCode  int          `json:"rpc_code,omitempty"` // Code returned by the downstream RPC call, if any
+	Errors   []FieldError `json:"errors,omitempty"`   // Invalid request fields, for validation problems
+}
+
+// FieldError describes why a single request field failed validation
+type FieldError struct {
+	Field   string `json:"field"`           // JSON name of the field
+	Rule    string `json:"rule"`            // Validation rule that failed
+	Param   string `json:"param,omitempty"` // Parameter of the rule, e.g. the maximum length
+	Message string `json:"message"`         // Human readable description
 }
 
 // Define the top-level structure for the message
@@ -32,6 +57,7 @@ type MessageResponse struct {
 	Status string        `json:"status"`
 	Code   int           `json:"code"`
 	Data   []interface{} `json:"data"`
+	Total  int           `json:"total,omitempty"`
 }
 
 // Define the "Pattern" structure
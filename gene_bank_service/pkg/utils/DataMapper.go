@@ -19,7 +19,7 @@ import (
 	"project_chimera/gene_bank_service/internal/dto"
 )
 
-func CreateFloraDataMap(payload dto.FloraUpdateRequest, userId string, imageBytes []byte) map[string]interface{} {
+func CreateFloraDataMap(payload dto.FloraUpdateRequest, userId string, imageBytes []byte, thumbnail []byte) map[string]interface{} {
 	data := make(map[string]interface{})
 
 	if payload.ID != "" {
@@ -35,6 +35,9 @@ func CreateFloraDataMap(payload dto.FloraUpdateRequest, userId string, imageByte
 	if len(imageBytes) > 0 {
 		data["Image"] = imageBytes
 	}
+	if len(thumbnail) > 0 {
+		data["Thumbnail"] = thumbnail
+	}
 	if payload.Description != "" {
 		data["Description"] = payload.Description
 	}
@@ -14,36 +14,187 @@
 package utils
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
-	"net/http"
+	"log"
 	"os"
+	"path"
+	"path/filepath"
+	"project_chimera/gene_bank_service/config"
+	"strings"
+	"sync"
+	"time"
 )
 
-// fetchImageFromURL fetches the image as bytes from the provided URL
-func FetchImageFromURL(imageURL string) ([]byte, error) {
-	resp, err := http.Get(imageURL)
+var (
+	// ErrImagePathDisabled is returned when image_path imports are not enabled by the operator
+	ErrImagePathDisabled = errors.New("image_path imports are disabled")
+	// ErrForbiddenImagePath is returned when an image_path points outside the import root
+	ErrForbiddenImagePath = errors.New("image_path is not allowed")
+)
+
+var (
+	imageFetcher     *ImageFetcher
+	imageFetcherOnce sync.Once
+
+	imageProcessor     *ImageProcessor
+	imageProcessorOnce sync.Once
+)
+
+// defaultImageFetcher builds the image fetcher from the service configuration
+func defaultImageFetcher() *ImageFetcher {
+	imageFetcherOnce.Do(func() {
+		allowedCIDRs, err := ParseCIDRs(config.Env.ImageFetchAllowedCIDRs)
+		if err != nil {
+			log.Printf("Error parsing IMAGE_FETCH_ALLOWED_CIDRS, ignoring it: %v", err)
+			allowedCIDRs = nil
+		}
+		deniedCIDRs, err := ParseCIDRs(config.Env.ImageFetchDeniedCIDRs)
+		if err != nil {
+			log.Printf("Error parsing IMAGE_FETCH_DENIED_CIDRS, ignoring it: %v", err)
+			deniedCIDRs = nil
+		}
+
+		maxBytes := config.Env.MaxImageSize
+		if maxBytes <= 0 {
+			maxBytes = 5 << 20
+		}
+
+		imageFetcher = NewImageFetcher(ImageFetcherConfig{
+			ConnectTimeout: durationOr(config.Env.ImageFetchConnectTimeout, 5*time.Second),
+			ReadTimeout:    durationOr(config.Env.ImageFetchReadTimeout, 15*time.Second),
+			MaxBytes:       maxBytes,
+			MaxRedirects:   config.Env.ImageFetchMaxRedirects,
+			AllowedHosts:   config.Env.ImageFetchAllowedHosts,
+			DeniedHosts:    config.Env.ImageFetchDeniedHosts,
+			AllowedCIDRs:   allowedCIDRs,
+			DeniedCIDRs:    deniedCIDRs,
+		})
+	})
+	return imageFetcher
+}
+
+// defaultImageProcessor builds the image processor from the service configuration
+func defaultImageProcessor() *ImageProcessor {
+	imageProcessorOnce.Do(func() {
+		maxBytes := config.Env.MaxImageSize
+		if maxBytes <= 0 {
+			maxBytes = 5 << 20
+		}
+
+		imageProcessor = NewImageProcessor(ImageProcessorConfig{
+			MaxBytes:      maxBytes,
+			MaxPixels:     config.Env.ImageMaxPixels,
+			MaxDimension:  config.Env.ImageMaxDimension,
+			ThumbnailSize: config.Env.ImageThumbnailSize,
+			JPEGQuality:   config.Env.ImageJPEGQuality,
+		})
+	})
+	return imageProcessor
+}
+
+// ProcessImage normalises an uploaded image and creates its thumbnail
+func ProcessImage(image []byte) (ProcessedImage, error) {
+	return defaultImageProcessor().Process(image)
+}
+
+// ResizeImage scales a stored image down to size pixels on its longest side
+func ResizeImage(image []byte, size int) ([]byte, string, error) {
+	return defaultImageProcessor().Resize(image, size)
+}
+
+// ThumbnailSize returns the configured longest side of thumbnails
+func ThumbnailSize() int {
+	return defaultImageProcessor().config.ThumbnailSize
+}
+
+// fetchImageFromURL fetches the image as bytes from the provided URL, stopping when ctx is done
+func FetchImageFromURL(ctx context.Context, imageURL string) ([]byte, error) {
+	return defaultImageFetcher().Fetch(ctx, imageURL)
+}
+
+func durationOr(value time.Duration, def time.Duration) time.Duration {
+	if value > 0 {
+		return value
+	}
+	return def
+}
+
+// fetchImageFromPath reads the image from a local file path and returns it as a byte slice.
+// Paths are only served when IMAGE_IMPORT_ENABLED is set and must stay inside IMAGE_IMPORT_ROOT.
+func FetchImageFromPath(imagePath string) ([]byte, error) {
+	if !config.Env.ImageImportEnabled || config.Env.ImageImportRoot == "" {
+		return nil, ErrImagePathDisabled
+	}
+
+	maxBytes := config.Env.MaxImageSize
+	if maxBytes <= 0 {
+		maxBytes = 5 << 20
+	}
+
+	return ReadImageFromRoot(config.Env.ImageImportRoot, imagePath, maxBytes)
+}
+
+// ReadImageFromRoot reads an image below root, rejecting absolute paths, ".." segments and symlinks
+func ReadImageFromRoot(root string, imagePath string, maxBytes int64) ([]byte, error) {
+	name := filepath.ToSlash(imagePath)
+	if name == "" || path.IsAbs(name) || filepath.IsAbs(imagePath) || filepath.VolumeName(imagePath) != "" {
+		return nil, fmt.Errorf("%w: path must be relative to the import root", ErrForbiddenImagePath)
+	}
+	for _, segment := range strings.Split(name, "/") {
+		if segment == ".." {
+			return nil, fmt.Errorf("%w: path must not contain ..", ErrForbiddenImagePath)
+		}
+	}
+	name = path.Clean(name)
+
+	// os.Root keeps every lookup inside the import root
+	importRoot, err := os.OpenRoot(root)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch image from URL: %v", err)
+		return nil, fmt.Errorf("failed to open image import root: %v", err)
+	}
+	defer importRoot.Close()
+
+	// Reject symlinks anywhere on the path, not only those escaping the root
+	current := ""
+	for _, segment := range strings.Split(name, "/") {
+		current = path.Join(current, segment)
+		info, err := importRoot.Lstat(current)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read image from path: %v", err)
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return nil, fmt.Errorf("%w: symlinks are not allowed", ErrForbiddenImagePath)
+		}
 	}
-	defer resp.Body.Close()
 
-	// Read the image content into a byte slice
-	imageBytes, err := io.ReadAll(resp.Body)
+	file, err := importRoot.Open(name)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read image data: %v", err)
+		return nil, fmt.Errorf("failed to read image from path: %v", err)
 	}
+	defer file.Close()
 
-	return imageBytes, nil
-}
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image from path: %v", err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%w: not a regular file", ErrForbiddenImagePath)
+	}
+	if info.Size() > maxBytes {
+		return nil, ErrImageTooLarge
+	}
 
-// fetchImageFromPath reads the image from a local file path and returns it as a byte slice
-func FetchImageFromPath(imagePath string) ([]byte, error) {
-	var imageBytes []byte
-	imageBytes, err := os.ReadFile(imagePath)
+	imageBytes, err := io.ReadAll(io.LimitReader(file, maxBytes+1))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read image from path: %v", err)
 	}
 
+	if _, err := ValidateImage(imageBytes, maxBytes); err != nil {
+		return nil, err
+	}
+
 	return imageBytes, nil
 }
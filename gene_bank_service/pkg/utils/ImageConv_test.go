@@ -0,0 +1,138 @@
+// Copyright 2025 Naveen R
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package utils
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"project_chimera/gene_bank_service/config"
+	"testing"
+)
+
+// newImportRoot creates an import root holding a valid image, plus an image and a secret outside of it
+func newImportRoot(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	root := filepath.Join(dir, "root")
+
+	files := map[string][]byte{
+		"root/plant.png":       pngHeader,
+		"root/nested/leaf.png": pngHeader,
+		"root/notes.txt":       []byte("not an image"),
+		"root/large.png":       append(append([]byte{}, pngHeader...), make([]byte, 64)...),
+		"outside.png":          pngHeader,
+		"secrets/password.png": pngHeader,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, content, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	symlinks := map[string]string{
+		"root/escape.png":   filepath.Join(dir, "outside.png"),
+		"root/relative.png": "../outside.png",
+		"root/secrets":      filepath.Join(dir, "secrets"),
+		"root/inside.png":   "plant.png",
+	}
+	for name, target := range symlinks {
+		if err := os.Symlink(target, filepath.Join(dir, filepath.FromSlash(name))); err != nil {
+			t.Skipf("symlinks are not supported: %v", err)
+		}
+	}
+
+	return root
+}
+
+func TestReadImageFromRoot(t *testing.T) {
+	root := newImportRoot(t)
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr error
+	}{
+		{name: "image in the root", path: "plant.png"},
+		{name: "image in a sub directory", path: "nested/leaf.png"},
+		{name: "redundant segments", path: "./nested//leaf.png"},
+		{name: "parent segment", path: "../outside.png", wantErr: ErrForbiddenImagePath},
+		{name: "parent segment resolving inside the root", path: "nested/../plant.png", wantErr: ErrForbiddenImagePath},
+		{name: "absolute path", path: filepath.Join(root, "plant.png"), wantErr: ErrForbiddenImagePath},
+		{name: "absolute path outside the root", path: "/etc/passwd", wantErr: ErrForbiddenImagePath},
+		{name: "empty path", path: "", wantErr: ErrForbiddenImagePath},
+		{name: "absolute symlink escaping the root", path: "escape.png", wantErr: ErrForbiddenImagePath},
+		{name: "relative symlink escaping the root", path: "relative.png", wantErr: ErrForbiddenImagePath},
+		{name: "symlinked directory escaping the root", path: "secrets/password.png", wantErr: ErrForbiddenImagePath},
+		{name: "symlink inside the root", path: "inside.png", wantErr: ErrForbiddenImagePath},
+		{name: "directory", path: "nested", wantErr: ErrForbiddenImagePath},
+		{name: "not an image", path: "notes.txt", wantErr: ErrUnsupportedImageType},
+		{name: "over the size limit", path: "large.png", wantErr: ErrImageTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			image, err := ReadImageFromRoot(root, tt.path, 32)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(image) != string(pngHeader) {
+				t.Fatalf("got %d bytes, want the image", len(image))
+			}
+		})
+	}
+
+	if _, err := ReadImageFromRoot(root, "missing.png", 32); err == nil || errors.Is(err, ErrForbiddenImagePath) {
+		t.Fatalf("missing file: got %v, want a read error", err)
+	}
+}
+
+func TestFetchImageFromPathIsDisabledByDefault(t *testing.T) {
+	root := newImportRoot(t)
+	previous := config.Env
+	t.Cleanup(func() { config.Env = previous })
+
+	tests := []struct {
+		name    string
+		enabled bool
+		root    string
+		wantErr error
+	}{
+		{name: "default configuration", wantErr: ErrImagePathDisabled},
+		{name: "root without enabling imports", root: root, wantErr: ErrImagePathDisabled},
+		{name: "enabled without a root", enabled: true, wantErr: ErrImagePathDisabled},
+		{name: "enabled with a root", enabled: true, root: root},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Env = config.Config{ImageImportEnabled: tt.enabled, ImageImportRoot: tt.root}
+
+			_, err := FetchImageFromPath("plant.png")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
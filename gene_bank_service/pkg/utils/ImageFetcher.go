@@ -0,0 +1,266 @@
+// Copyright 2025 Naveen R
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenImageURL is returned when an image URL points to a host or address that may not be fetched
+var ErrForbiddenImageURL = errors.New("image URL is not allowed")
+
+// ImageFetcherConfig holds the limits and the host/IP policy of an ImageFetcher
+type ImageFetcherConfig struct {
+	ConnectTimeout time.Duration // Timeout for dialing and the TLS handshake
+	ReadTimeout    time.Duration // Timeout for receiving the whole response
+	MaxBytes       int64         // Maximum image size in bytes
+	MaxRedirects   int           // Maximum number of redirects to follow
+	AllowedHosts   []string      // If set, only these hosts (and ".suffix" domains) may be fetched
+	DeniedHosts    []string      // Hosts (and ".suffix" domains) that may never be fetched
+	AllowedCIDRs   []*net.IPNet  // Ranges allowed even if they are private
+	DeniedCIDRs    []*net.IPNet  // Ranges that may never be fetched
+}
+
+// ImageFetcher downloads images from user supplied URLs without reaching internal services
+type ImageFetcher struct {
+	config ImageFetcherConfig
+	client *http.Client
+}
+
+// blockedCIDRs are special purpose ranges not covered by the net.IP helpers
+var blockedCIDRs = mustParseCIDRs(
+	"0.0.0.0/8",     // "this" network
+	"100.64.0.0/10", // carrier grade NAT
+	"192.0.0.0/24",  // IETF protocol assignments
+	"198.18.0.0/15", // benchmarking
+	"240.0.0.0/4",   // reserved
+	"64:ff9b::/96",  // NAT64, can map to internal IPv4 addresses
+)
+
+// NewImageFetcher creates an ImageFetcher that checks every resolved address before connecting
+func NewImageFetcher(config ImageFetcherConfig) *ImageFetcher {
+	fetcher := &ImageFetcher{config: config}
+
+	dialer := &net.Dialer{
+		Timeout: config.ConnectTimeout,
+		// Control runs after DNS resolution, so rebinding to an internal address is caught too
+		Control: fetcher.control,
+	}
+
+	fetcher.client = &http.Client{
+		Timeout: config.ConnectTimeout + config.ReadTimeout,
+		Transport: &http.Transport{
+			Proxy:                 nil, // A proxy would hide the real destination from the dialer check
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   config.ConnectTimeout,
+			ResponseHeaderTimeout: config.ReadTimeout,
+			MaxIdleConns:          10,
+			IdleConnTimeout:       30 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > config.MaxRedirects {
+				return fmt.Errorf("stopped after %d redirects", config.MaxRedirects)
+			}
+			return fetcher.checkURL(req.URL)
+		},
+	}
+
+	return fetcher
+}
+
+// control rejects connections to addresses that may not be fetched
+func (f *ImageFetcher) control(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if !f.ipAllowed(net.ParseIP(host)) {
+		return fmt.Errorf("%w: %s resolves to a blocked address", ErrForbiddenImageURL, host)
+	}
+	return nil
+}
+
+// Fetch downloads the image at rawURL, enforcing the size limit and checking that it is an image
+func (f *ImageFetcher) Fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrForbiddenImageURL, err)
+	}
+	if err := f.checkURL(parsed); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, parsed.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "image/jpeg, image/png, image/webp")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		if errors.Is(err, ErrForbiddenImageURL) {
+			return nil, fmt.Errorf("%w: %s resolves to a blocked address", ErrForbiddenImageURL, parsed.Hostname())
+		}
+		return nil, fmt.Errorf("failed to fetch image from URL: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch image from URL: unexpected status %s", resp.Status)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if !strings.HasPrefix(mediaType, "image/") {
+		return nil, ErrUnsupportedImageType
+	}
+	if f.config.MaxBytes > 0 && resp.ContentLength > f.config.MaxBytes {
+		return nil, ErrImageTooLarge
+	}
+
+	body := io.Reader(resp.Body)
+	if f.config.MaxBytes > 0 {
+		body = io.LimitReader(resp.Body, f.config.MaxBytes+1)
+	}
+
+	imageBytes, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image data: %v", err)
+	}
+
+	// The Content-Type header is only a claim, check the actual bytes
+	if _, err := ValidateImage(imageBytes, f.config.MaxBytes); err != nil {
+		return nil, err
+	}
+
+	return imageBytes, nil
+}
+
+// checkURL validates the scheme and the host of a URL against the host lists
+func (f *ImageFetcher) checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: scheme %q is not supported", ErrForbiddenImageURL, u.Scheme)
+	}
+	if u.User != nil {
+		return fmt.Errorf("%w: credentials in URL", ErrForbiddenImageURL)
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "" {
+		return fmt.Errorf("%w: missing host", ErrForbiddenImageURL)
+	}
+
+	if matchesHost(host, f.config.DeniedHosts) {
+		return fmt.Errorf("%w: host %s is denied", ErrForbiddenImageURL, host)
+	}
+	if len(f.config.AllowedHosts) > 0 && !matchesHost(host, f.config.AllowedHosts) {
+		return fmt.Errorf("%w: host %s is not allowed", ErrForbiddenImageURL, host)
+	}
+
+	// Literal IPs are checked here as well as on dial to fail early
+	if ip := net.ParseIP(host); ip != nil && !f.ipAllowed(ip) {
+		return fmt.Errorf("%w: address %s is blocked", ErrForbiddenImageURL, host)
+	}
+
+	return nil
+}
+
+// ipAllowed applies the denied ranges, then the allowed ranges, then blocks internal addresses
+func (f *ImageFetcher) ipAllowed(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+
+	if containsIP(f.config.DeniedCIDRs, ip) {
+		return false
+	}
+	if containsIP(f.config.AllowedCIDRs, ip) {
+		return true
+	}
+
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+
+	return !containsIP(blockedCIDRs, ip)
+}
+
+// ParseCIDRs parses a list of CIDR ranges, single addresses are treated as /32 or /128
+func ParseCIDRs(values []string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if !strings.Contains(value, "/") {
+			if ip := net.ParseIP(value); ip != nil && ip.To4() != nil {
+				value += "/32"
+			} else {
+				value += "/128"
+			}
+		}
+
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+func mustParseCIDRs(values ...string) []*net.IPNet {
+	networks, err := ParseCIDRs(values)
+	if err != nil {
+		panic(err)
+	}
+	return networks
+}
+
+func containsIP(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesHost reports whether host equals an entry or is a subdomain of a ".suffix" entry
+func matchesHost(host string, hosts []string) bool {
+	for _, entry := range hosts {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if host == entry || (strings.HasPrefix(entry, ".") && strings.HasSuffix(host, entry)) {
+			return true
+		}
+	}
+	return false
+}
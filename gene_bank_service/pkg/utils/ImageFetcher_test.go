@@ -0,0 +1,191 @@
+// Copyright 2025 Naveen R
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var pngHeader = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1A, '\n', 0, 0, 0, 0x0D}
+
+func TestImageFetcherControl(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  ImageFetcherConfig
+		address string
+		allowed bool
+	}{
+		{name: "public IPv4", address: "93.184.216.34:443", allowed: true},
+		{name: "public IPv6", address: "[2606:4700::1]:443", allowed: true},
+		{name: "IPv4-mapped public address", address: "[::ffff:93.184.216.34]:443", allowed: true},
+		{name: "loopback", address: "127.0.0.1:80"},
+		{name: "loopback range", address: "127.8.8.8:80"},
+		{name: "IPv6 loopback", address: "[::1]:80"},
+		{name: "private 10/8", address: "10.1.2.3:80"},
+		{name: "private 172.16/12", address: "172.20.0.1:80"},
+		{name: "private 192.168/16", address: "192.168.1.1:80"},
+		{name: "IPv6 unique local", address: "[fd00::1]:80"},
+		{name: "link-local metadata endpoint", address: "169.254.169.254:80"},
+		{name: "IPv6 link-local", address: "[fe80::1]:80"},
+		{name: "IPv4-mapped loopback", address: "[::ffff:127.0.0.1]:80"},
+		{name: "IPv4-mapped private", address: "[::ffff:10.0.0.1]:80"},
+		{name: "IPv4-mapped link-local", address: "[::ffff:169.254.169.254]:80"},
+		{name: "NAT64 of a private address", address: "[64:ff9b::a00:1]:80"},
+		{name: "unspecified", address: "0.0.0.0:80"},
+		{name: "carrier grade NAT", address: "100.64.0.1:80"},
+		{name: "multicast", address: "224.0.0.1:80"},
+		{name: "allowed private range", config: ImageFetcherConfig{AllowedCIDRs: mustParseCIDRs("10.0.0.0/8")}, address: "10.1.2.3:80", allowed: true},
+		{name: "allowed range as IPv4-mapped address", config: ImageFetcherConfig{AllowedCIDRs: mustParseCIDRs("10.0.0.0/8")}, address: "[::ffff:10.1.2.3]:80", allowed: true},
+		{name: "denied public range", config: ImageFetcherConfig{DeniedCIDRs: mustParseCIDRs("93.184.216.0/24")}, address: "93.184.216.34:443"},
+		{name: "denied wins over allowed", config: ImageFetcherConfig{AllowedCIDRs: mustParseCIDRs("10.0.0.0/8"), DeniedCIDRs: mustParseCIDRs("10.1.2.3")}, address: "10.1.2.3:80"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewImageFetcher(tt.config).control("tcp", tt.address, nil)
+			if tt.allowed && err != nil {
+				t.Fatalf("got %v, want the address to be allowed", err)
+			}
+			if !tt.allowed && !errors.Is(err, ErrForbiddenImageURL) {
+				t.Fatalf("got %v, want %v", err, ErrForbiddenImageURL)
+			}
+		})
+	}
+}
+
+// newImageServer serves the routes used by TestImageFetcherFetch
+func newImageServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/image.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ImagePNG)
+		w.Write(pngHeader)
+	})
+	mux.HandleFunc("/redirect/", func(w http.ResponseWriter, r *http.Request) {
+		remaining, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/redirect/"))
+		if remaining == 0 {
+			http.Redirect(w, r, "/image.png", http.StatusFound)
+			return
+		}
+		http.Redirect(w, r, fmt.Sprintf("/redirect/%d", remaining-1), http.StatusFound)
+	})
+	mux.HandleFunc("/metadata", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/latest/meta-data/", http.StatusFound)
+	})
+	mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ImagePNG)
+		w.Header().Set("Content-Length", "1024")
+		w.Write(append(pngHeader, make([]byte, 1024-len(pngHeader))...))
+	})
+	mux.HandleFunc("/large-chunked", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ImagePNG)
+		w.Write(pngHeader)
+		w.(http.Flusher).Flush()
+		w.Write(make([]byte, 1024))
+	})
+	mux.HandleFunc("/fake.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ImagePNG)
+		w.Write([]byte("<html><body>not an image</body></html>"))
+	})
+	mux.HandleFunc("/page.html", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write(pngHeader)
+	})
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestImageFetcherFetch(t *testing.T) {
+	server := newImageServer(t)
+
+	// The test server listens on loopback, which has to be allowed explicitly
+	fetcher := NewImageFetcher(ImageFetcherConfig{
+		ConnectTimeout: time.Second,
+		ReadTimeout:    time.Second,
+		MaxBytes:       512,
+		MaxRedirects:   2,
+		AllowedCIDRs:   mustParseCIDRs("127.0.0.1"),
+	})
+
+	tests := []struct {
+		name    string
+		url     string
+		wantErr error
+		wantMsg string
+	}{
+		{name: "image", url: server.URL + "/image.png"},
+		{name: "redirects up to the limit", url: server.URL + "/redirect/1"},
+		{name: "redirects over the limit", url: server.URL + "/redirect/2", wantMsg: "stopped after 2 redirects"},
+		{name: "redirect to a blocked address", url: server.URL + "/metadata", wantErr: ErrForbiddenImageURL},
+		{name: "declared size over the limit", url: server.URL + "/large", wantErr: ErrImageTooLarge},
+		{name: "streamed size over the limit", url: server.URL + "/large-chunked", wantErr: ErrImageTooLarge},
+		{name: "image content type with other bytes", url: server.URL + "/fake.png", wantErr: ErrUnsupportedImageType},
+		{name: "other content type", url: server.URL + "/page.html", wantErr: ErrUnsupportedImageType},
+		{name: "error status", url: server.URL + "/missing", wantMsg: "unexpected status 404"},
+		{name: "unsupported scheme", url: "file:///etc/passwd", wantErr: ErrForbiddenImageURL},
+		{name: "credentials in URL", url: strings.Replace(server.URL, "://", "://user:pass@", 1) + "/image.png", wantErr: ErrForbiddenImageURL},
+		{name: "blocked literal address", url: "http://169.254.169.254/", wantErr: ErrForbiddenImageURL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			image, err := fetcher.Fetch(context.Background(), tt.url)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %v, want %v", err, tt.wantErr)
+				}
+			case tt.wantMsg != "":
+				if err == nil || !strings.Contains(err.Error(), tt.wantMsg) {
+					t.Fatalf("got %v, want an error containing %q", err, tt.wantMsg)
+				}
+			default:
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(image) != string(pngHeader) {
+					t.Fatalf("got %d bytes, want the served image", len(image))
+				}
+			}
+		})
+	}
+}
+
+func TestImageFetcherRejectsLoopbackByDefault(t *testing.T) {
+	server := newImageServer(t)
+
+	fetcher := NewImageFetcher(ImageFetcherConfig{ConnectTimeout: time.Second, ReadTimeout: time.Second})
+	if _, err := fetcher.Fetch(context.Background(), server.URL+"/image.png"); !errors.Is(err, ErrForbiddenImageURL) {
+		t.Fatalf("got %v, want %v", err, ErrForbiddenImageURL)
+	}
+
+	// A host name resolving to loopback is only caught by the dialer
+	localhost := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+	if _, err := fetcher.Fetch(context.Background(), localhost+"/image.png"); !errors.Is(err, ErrForbiddenImageURL) {
+		t.Fatalf("got %v, want %v", err, ErrForbiddenImageURL)
+	}
+}
@@ -0,0 +1,286 @@
+// Copyright 2025 Naveen R
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // registers the WebP decoder with image.Decode
+)
+
+// ErrInvalidImage is returned when an image has valid magic bytes but cannot be decoded
+var ErrInvalidImage = errors.New("image could not be decoded")
+
+// ImageProcessorConfig holds the output limits of an ImageProcessor
+type ImageProcessorConfig struct {
+	MaxBytes      int64 // Maximum size of the uploaded image in bytes
+	MaxPixels     int   // Maximum number of pixels of the decoded image
+	MaxDimension  int   // Longest side of the stored main image
+	ThumbnailSize int   // Longest side of the thumbnail
+	JPEGQuality   int   // Quality used when encoding JPEG output
+}
+
+// ProcessedImage is a normalised image together with its thumbnail
+type ProcessedImage struct {
+	Image       []byte // Main image, bounded to MaxDimension
+	Thumbnail   []byte // Thumbnail, bounded to ThumbnailSize
+	ContentType string // Content type of both the main image and the thumbnail
+}
+
+// ImageProcessor decodes, orients and re-encodes uploaded images.
+// Re-encoding drops every metadata block, including EXIF GPS coordinates.
+type ImageProcessor struct {
+	config ImageProcessorConfig
+}
+
+// NewImageProcessor creates an ImageProcessor with the given limits
+func NewImageProcessor(config ImageProcessorConfig) *ImageProcessor {
+	if config.MaxPixels <= 0 {
+		config.MaxPixels = 40_000_000
+	}
+	if config.MaxDimension <= 0 {
+		config.MaxDimension = 2048
+	}
+	if config.ThumbnailSize <= 0 {
+		config.ThumbnailSize = 256
+	}
+	if config.JPEGQuality <= 0 || config.JPEGQuality > 100 {
+		config.JPEGQuality = jpeg.DefaultQuality
+	}
+	return &ImageProcessor{config: config}
+}
+
+// Process validates the image and returns the bounded main image and its thumbnail
+func (p *ImageProcessor) Process(data []byte) (ProcessedImage, error) {
+	img, contentType, err := p.decode(data)
+	if err != nil {
+		return ProcessedImage{}, err
+	}
+
+	// Transparent images stay PNG, everything else is stored as JPEG
+	if contentType == ImageWebP || (contentType == ImagePNG && isOpaque(img)) {
+		contentType = ImageJPEG
+	}
+
+	main, err := p.encode(fit(img, p.config.MaxDimension), contentType)
+	if err != nil {
+		return ProcessedImage{}, err
+	}
+	thumbnail, err := p.encode(fit(img, p.config.ThumbnailSize), contentType)
+	if err != nil {
+		return ProcessedImage{}, err
+	}
+
+	return ProcessedImage{Image: main, Thumbnail: thumbnail, ContentType: contentType}, nil
+}
+
+// Resize returns a copy of an already processed image bounded to size pixels on its longest side
+func (p *ImageProcessor) Resize(data []byte, size int) ([]byte, string, error) {
+	img, contentType, err := p.decode(data)
+	if err != nil {
+		return nil, "", err
+	}
+	if contentType == ImageWebP {
+		contentType = ImageJPEG
+	}
+
+	resized, err := p.encode(fit(img, size), contentType)
+	if err != nil {
+		return nil, "", err
+	}
+	return resized, contentType, nil
+}
+
+// decode checks the size, type and dimensions of the image before decoding and orienting it
+func (p *ImageProcessor) decode(data []byte) (image.Image, string, error) {
+	contentType, err := ValidateImage(data, p.config.MaxBytes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Check the dimensions first so a small file cannot expand into a huge bitmap
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > p.config.MaxPixels {
+		return nil, "", fmt.Errorf("%w: %dx%d pixels", ErrImageTooLarge, cfg.Width, cfg.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	if contentType == ImageJPEG {
+		img = orient(img, jpegOrientation(data))
+	}
+	return img, contentType, nil
+}
+
+// encode writes the image in the given format without any metadata
+func (p *ImageProcessor) encode(img image.Image, contentType string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	if contentType == ImagePNG {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: p.config.JPEGQuality})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode image: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// fit scales the image down so its longest side is at most size pixels
+func fit(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if size <= 0 || (width <= size && height <= size) {
+		return img
+	}
+
+	if width >= height {
+		height = max(1, height*size/width)
+		width = size
+	} else {
+		width = max(1, width*size/height)
+		height = size
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+// isOpaque reports whether the image has no transparent pixels
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
+
+// orient applies an EXIF orientation (1-8) so the pixels are stored upright
+func orient(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	src := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 {
+		dstWidth, dstHeight = height, width
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored horizontally
+				dx, dy = width-1-x, y
+			case 3: // rotated 180
+				dx, dy = width-1-x, height-1-y
+			case 4: // mirrored vertically
+				dx, dy = x, height-1-y
+			case 5: // transposed
+				dx, dy = y, x
+			case 6: // rotated 90 clockwise
+				dx, dy = height-1-y, x
+			case 7: // transversed
+				dx, dy = height-1-y, width-1-x
+			case 8: // rotated 90 counter clockwise
+				dx, dy = y, width-1-x
+			}
+			si := src.PixOffset(x, y)
+			di := dst.PixOffset(dx, dy)
+			copy(dst.Pix[di:di+4], src.Pix[si:si+4])
+		}
+	}
+	return dst
+}
+
+// jpegOrientation returns the EXIF orientation tag of a JPEG, or 1 if there is none
+func jpegOrientation(data []byte) int {
+	// Walk the marker segments up to the start of the scan
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		if marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2 : i+4]))
+		if length < 2 || i+2+length > len(data) {
+			return 1
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		i += 2 + length
+	}
+	return 1
+}
+
+// exifOrientation reads the orientation tag from the first IFD of a TIFF structure
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	offset := int(order.Uint32(tiff[4:8]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[offset : offset+2]))
+	for n := 0; n < count; n++ {
+		entry := offset + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:entry+2]) == 0x0112 {
+			value := int(order.Uint16(tiff[entry+8 : entry+10]))
+			if value < 1 || value > 8 {
+				return 1
+			}
+			return value
+		}
+	}
+	return 1
+}
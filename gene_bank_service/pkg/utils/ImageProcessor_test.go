@@ -0,0 +1,218 @@
+// Copyright 2025 Naveen R
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// The orientation fixtures show red, green, blue and white quadrants clockwise from the top left of
+// a 32x16 picture once oriented, see testdata/gen_orientation.go
+var uprightQuadrants = map[string]color.NRGBA{
+	"top left":     {255, 0, 0, 255},
+	"top right":    {0, 255, 0, 255},
+	"bottom right": {0, 0, 255, 255},
+	"bottom left":  {255, 255, 255, 255},
+}
+
+// quadrantCenter returns the center pixel of a quadrant of an image of the given size
+func quadrantCenter(name string, width, height int) (int, int) {
+	x, y := width/4, height/4
+	if name == "top right" || name == "bottom right" {
+		x = width * 3 / 4
+	}
+	if name == "bottom left" || name == "bottom right" {
+		y = height * 3 / 4
+	}
+	return x, y
+}
+
+// closeTo compares colors with room for JPEG compression
+func closeTo(got color.Color, want color.NRGBA) bool {
+	r, g, b, _ := got.RGBA()
+	diff := func(a uint32, b uint8) int {
+		d := int(a>>8) - int(b)
+		if d < 0 {
+			return -d
+		}
+		return d
+	}
+	return diff(r, want.R) < 48 && diff(g, want.G) < 48 && diff(b, want.B) < 48
+}
+
+func TestProcessAppliesEXIFOrientation(t *testing.T) {
+	processor := NewImageProcessor(ImageProcessorConfig{JPEGQuality: 95})
+
+	for orientation := 1; orientation <= 8; orientation++ {
+		t.Run(fmt.Sprintf("orientation %d", orientation), func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", fmt.Sprintf("orientation_%d.jpg", orientation)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := jpegOrientation(data); got != orientation {
+				t.Fatalf("jpegOrientation = %d, want %d", got, orientation)
+			}
+
+			processed, err := processor.Process(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if processed.ContentType != ImageJPEG {
+				t.Fatalf("content type = %s, want %s", processed.ContentType, ImageJPEG)
+			}
+			// Re-encoding drops the EXIF block, so viewers do not rotate the image a second time
+			if got := jpegOrientation(processed.Image); got != 1 {
+				t.Fatalf("processed image still has orientation %d", got)
+			}
+
+			img, err := jpeg.Decode(bytes.NewReader(processed.Image))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if size := img.Bounds().Size(); size != image.Pt(32, 16) {
+				t.Fatalf("size = %v, want 32x16", size)
+			}
+			for name, want := range uprightQuadrants {
+				x, y := quadrantCenter(name, 32, 16)
+				if got := img.At(x, y); !closeTo(got, want) {
+					t.Errorf("%s quadrant = %v, want %v", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestJPEGOrientationWithoutEXIF(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "no segments", data: []byte{0xFF, 0xD8, 0xFF, 0xD9}},
+		{name: "truncated segment", data: []byte{0xFF, 0xD8, 0xFF, 0xE1, 0x00, 0x40, 'E', 'x'}},
+		{name: "APP1 without EXIF", data: []byte{0xFF, 0xD8, 0xFF, 0xE1, 0x00, 0x06, 'h', 't', 't', 'p', 0xFF, 0xD9}},
+		{name: "not a JPEG structure", data: []byte{0xFF, 0xD8, 0x00, 0x00, 0x00}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jpegOrientation(tt.data); got != 1 {
+				t.Fatalf("got %d, want 1", got)
+			}
+		})
+	}
+}
+
+// encodeTestImage encodes an opaque image of the given size
+func encodeTestImage(t *testing.T, width, height int, contentType string) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = 200
+	}
+
+	var buf bytes.Buffer
+	var err error
+	if contentType == ImagePNG {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, nil)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func decodedSize(t *testing.T, data []byte) image.Point {
+	t.Helper()
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return image.Pt(cfg.Width, cfg.Height)
+}
+
+func TestProcessSizes(t *testing.T) {
+	processor := NewImageProcessor(ImageProcessorConfig{MaxDimension: 100, ThumbnailSize: 20, MaxPixels: 200 * 200})
+
+	tests := []struct {
+		name          string
+		width, height int
+		wantImage     image.Point
+		wantThumbnail image.Point
+	}{
+		{name: "landscape", width: 200, height: 100, wantImage: image.Pt(100, 50), wantThumbnail: image.Pt(20, 10)},
+		{name: "portrait", width: 50, height: 200, wantImage: image.Pt(25, 100), wantThumbnail: image.Pt(5, 20)},
+		{name: "smaller than the limits is not enlarged", width: 16, height: 8, wantImage: image.Pt(16, 8), wantThumbnail: image.Pt(16, 8)},
+		{name: "thin images keep a pixel", width: 200, height: 1, wantImage: image.Pt(100, 1), wantThumbnail: image.Pt(20, 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processed, err := processor.Process(encodeTestImage(t, tt.width, tt.height, ImageJPEG))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := decodedSize(t, processed.Image); got != tt.wantImage {
+				t.Errorf("image size = %v, want %v", got, tt.wantImage)
+			}
+			if got := decodedSize(t, processed.Thumbnail); got != tt.wantThumbnail {
+				t.Errorf("thumbnail size = %v, want %v", got, tt.wantThumbnail)
+			}
+		})
+	}
+
+	if _, err := processor.Process(encodeTestImage(t, 201, 200, ImageJPEG)); !errors.Is(err, ErrImageTooLarge) {
+		t.Fatalf("image over MaxPixels: got %v, want %v", err, ErrImageTooLarge)
+	}
+}
+
+func TestResize(t *testing.T) {
+	processor := NewImageProcessor(ImageProcessorConfig{})
+
+	tests := []struct {
+		name        string
+		contentType string
+		size        int
+		want        image.Point
+	}{
+		{name: "JPEG", contentType: ImageJPEG, size: 64, want: image.Pt(64, 32)},
+		{name: "PNG stays PNG", contentType: ImagePNG, size: 32, want: image.Pt(32, 16)},
+		{name: "larger than the image", contentType: ImageJPEG, size: 1000, want: image.Pt(128, 64)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resized, contentType, err := processor.Resize(encodeTestImage(t, 128, 64, tt.contentType), tt.size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if contentType != tt.contentType {
+				t.Fatalf("content type = %s, want %s", contentType, tt.contentType)
+			}
+			if got := decodedSize(t, resized); got != tt.want {
+				t.Fatalf("size = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
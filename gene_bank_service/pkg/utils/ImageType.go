@@ -0,0 +1,57 @@
+// Copyright 2025 Naveen R
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package utils
+
+import (
+	"bytes"
+	"errors"
+)
+
+var (
+	// ErrImageTooLarge is returned when an image exceeds the configured size limit
+	ErrImageTooLarge = errors.New("image exceeds the maximum allowed size")
+	// ErrUnsupportedImageType is returned when an image is not JPEG, PNG or WebP
+	ErrUnsupportedImageType = errors.New("image must be JPEG, PNG or WebP")
+)
+
+// Supported image content types
+const (
+	ImageJPEG = "image/jpeg"
+	ImagePNG  = "image/png"
+	ImageWebP = "image/webp"
+)
+
+// SniffImageSize is the number of leading bytes DetectImageType needs
+const SniffImageSize = 12
+
+// DetectImageType returns the content type of an image based on its magic bytes
+func DetectImageType(header []byte) (string, error) {
+	switch {
+	case bytes.HasPrefix(header, []byte{0xFF, 0xD8, 0xFF}):
+		return ImageJPEG, nil
+	case bytes.HasPrefix(header, []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1A, '\n'}):
+		return ImagePNG, nil
+	case len(header) >= 12 && bytes.Equal(header[0:4], []byte("RIFF")) && bytes.Equal(header[8:12], []byte("WEBP")):
+		return ImageWebP, nil
+	}
+	return "", ErrUnsupportedImageType
+}
+
+// ValidateImage checks the size and the magic bytes of an image
+func ValidateImage(image []byte, maxSize int64) (string, error) {
+	if maxSize > 0 && int64(len(image)) > maxSize {
+		return "", ErrImageTooLarge
+	}
+	return DetectImageType(image)
+}
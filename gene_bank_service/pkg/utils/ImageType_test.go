@@ -0,0 +1,50 @@
+// Copyright 2025 Naveen R
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package utils
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDetectImageType(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []byte
+		want   string
+	}{
+		{name: "JPEG", header: []byte{0xFF, 0xD8, 0xFF, 0xE0}, want: ImageJPEG},
+		{name: "PNG", header: pngHeader, want: ImagePNG},
+		{name: "WebP", header: []byte("RIFF\x00\x00\x00\x00WEBPVP8 "), want: ImageWebP},
+		{name: "GIF", header: []byte("GIF89a")},
+		{name: "RIFF without WEBP", header: []byte("RIFF\x00\x00\x00\x00WAVE")},
+		{name: "truncated PNG", header: pngHeader[:4]},
+		{name: "empty", header: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DetectImageType(tt.header)
+			if tt.want == "" {
+				if !errors.Is(err, ErrUnsupportedImageType) {
+					t.Fatalf("got (%q, %v), want %v", got, err, ErrUnsupportedImageType)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("got (%q, %v), want %q", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -1,7 +1,14 @@
 package utils
 
+import "net/http"
+
 const (
 	SUCCESS               = 200
 	NOT_FOUND             = 404
 	INTERNAL_SERVER_ERROR = 500
 )
+
+// StatusMessage returns the reason phrase sent as status in the error events
+func StatusMessage(code int) string {
+	return http.StatusText(code)
+}
@@ -0,0 +1,131 @@
+// Copyright 2025 Naveen R
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"project_chimera/gene_bank_service/pkg/common"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// scientificNamePattern matches binomial names with an optional hybrid sign and infraspecific rank,
+// e.g. "Rosa canina", "Mentha × piperita" or "Brassica oleracea var. capitata"
+var scientificNamePattern = regexp.MustCompile(`^[A-Z][a-z]+ (?:× ?)?[a-z]+(?:-[a-z]+)*(?: (?:subsp\.|ssp\.|var\.|f\.) [a-z]+(?:-[a-z]+)*)?$`)
+
+var (
+	validate     *validator.Validate
+	validateOnce sync.Once
+)
+
+// ValidationError is returned when a request DTO fails its validate tags
+type ValidationError struct {
+	Fields []common.FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Message)
+	}
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+// defaultValidator builds the validator with the JSON field names and the custom flora rules
+func defaultValidator() *validator.Validate {
+	validateOnce.Do(func() {
+		validate = validator.New(validator.WithRequiredStructEnabled())
+
+		// Report fields by their JSON name so the errors match the request body
+		validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+
+		validate.RegisterValidation("notblank", func(fl validator.FieldLevel) bool {
+			return strings.TrimSpace(fl.Field().String()) != ""
+		})
+		validate.RegisterValidation("scientific_name", func(fl validator.FieldLevel) bool {
+			return scientificNamePattern.MatchString(fl.Field().String())
+		})
+		validate.RegisterValidation("json_size", func(fl validator.FieldLevel) bool {
+			limit, err := strconv.Atoi(fl.Param())
+			if err != nil {
+				return false
+			}
+			encoded, err := json.Marshal(fl.Field().Interface())
+			return err == nil && len(encoded) <= limit
+		})
+	})
+	return validate
+}
+
+// ValidateStruct checks a DTO against its validate tags and returns a *ValidationError listing every failing field
+func ValidateStruct(payload interface{}) error {
+	err := defaultValidator().Struct(payload)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err
+	}
+
+	fields := make([]common.FieldError, 0, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		fields = append(fields, common.FieldError{
+			Field:   fieldErr.Field(),
+			Rule:    fieldErr.Tag(),
+			Param:   fieldErr.Param(),
+			Message: fieldMessage(fieldErr),
+		})
+	}
+	return &ValidationError{Fields: fields}
+}
+
+// fieldMessage describes a failed rule in plain words
+func fieldMessage(fieldErr validator.FieldError) string {
+	field := fieldErr.Field()
+	switch fieldErr.Tag() {
+	case "required", "notblank":
+		return fmt.Sprintf("%s is required", field)
+	case "max":
+		if fieldErr.Kind() == reflect.Map {
+			return fmt.Sprintf("%s must not have more than %s entries", field, fieldErr.Param())
+		}
+		return fmt.Sprintf("%s must not exceed %s characters", field, fieldErr.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", field, strings.ReplaceAll(fieldErr.Param(), " ", ", "))
+	case "scientific_name":
+		return fmt.Sprintf("%s must be a binomial name such as \"Rosa canina\"", field)
+	case "json_size":
+		return fmt.Sprintf("%s must not exceed %s bytes when encoded", field, fieldErr.Param())
+	case "url":
+		return fmt.Sprintf("%s must be a valid URL", field)
+	case "uuid":
+		return fmt.Sprintf("%s must be a UUID", field)
+	}
+	return fmt.Sprintf("%s is invalid (%s)", field, fieldErr.Tag())
+}
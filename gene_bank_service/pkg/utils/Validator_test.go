@@ -0,0 +1,115 @@
+// Copyright 2025 Naveen R
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestScientificNamePattern(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"Rosa canina", true},
+		{"Mentha × piperita", true},
+		{"Mentha ×piperita", true},
+		{"Brassica oleracea var. capitata", true},
+		{"Acer saccharum subsp. nigrum", true},
+		{"Carex ssp. flava", false},
+		{"Rosa canina f. alba", true},
+		{"Capsella bursa-pastoris", true},
+		{"Silene latifolia ssp. alba", true},
+		{"rosa canina", false},
+		{"Rosa Canina", false},
+		{"Rosa", false},
+		{"Rosa  canina", false},
+		{"Rosa canina ", false},
+		{"Rosa canina L.", false},
+		{"Rosa canina var.", false},
+		{"Rosa canina cv. alba", false},
+		{"Rosa canina1", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scientificNamePattern.MatchString(tt.name); got != tt.valid {
+				t.Fatalf("match = %t, want %t", got, tt.valid)
+			}
+		})
+	}
+}
+
+// sample exercises every custom rule and message, fields are reported by their JSON names
+type sample struct {
+	Name     string                 `json:"name,omitempty" validate:"required,notblank,max=5"`
+	Species  string                 `json:"species" validate:"omitempty,scientific_name"`
+	Kind     string                 `json:"kind" validate:"omitempty,oneof=public private"`
+	Link     string                 `json:"link" validate:"omitempty,url"`
+	ID       string                 `json:"id" validate:"omitempty,uuid"`
+	Details  map[string]interface{} `json:"details" validate:"omitempty,max=2,json_size=20"`
+	Internal string                 `json:"-" validate:"omitempty,max=1"`
+}
+
+func TestValidateStruct(t *testing.T) {
+	tests := []struct {
+		name        string
+		payload     sample
+		wantField   string
+		wantRule    string
+		wantMessage string
+	}{
+		{name: "valid", payload: sample{Name: "Rosa", Species: "Rosa canina", Kind: "public", Link: "https://example.com/rosa.png", ID: "8f14e45f-ceea-467f-a0e6-1c0e6b7a9a10", Details: map[string]interface{}{"a": 1}}},
+		{name: "missing", payload: sample{}, wantField: "name", wantRule: "required", wantMessage: "name is required"},
+		{name: "blank", payload: sample{Name: "   "}, wantField: "name", wantRule: "notblank", wantMessage: "name is required"},
+		{name: "too long", payload: sample{Name: "Rosaceae"}, wantField: "name", wantRule: "max", wantMessage: "name must not exceed 5 characters"},
+		{name: "not binomial", payload: sample{Name: "Rosa", Species: "rosa"}, wantField: "species", wantRule: "scientific_name", wantMessage: `species must be a binomial name such as "Rosa canina"`},
+		{name: "not one of", payload: sample{Name: "Rosa", Kind: "shared"}, wantField: "kind", wantRule: "oneof", wantMessage: "kind must be one of: public, private"},
+		{name: "invalid URL", payload: sample{Name: "Rosa", Link: "rosa.png"}, wantField: "link", wantRule: "url", wantMessage: "link must be a valid URL"},
+		{name: "invalid UUID", payload: sample{Name: "Rosa", ID: "42"}, wantField: "id", wantRule: "uuid", wantMessage: "id must be a UUID"},
+		{name: "too many entries", payload: sample{Name: "Rosa", Details: map[string]interface{}{"a": 1, "b": 2, "c": 3}}, wantField: "details", wantRule: "max", wantMessage: "details must not have more than 2 entries"},
+		{name: "encoded too large", payload: sample{Name: "Rosa", Details: map[string]interface{}{"a": strings.Repeat("x", 20)}}, wantField: "details", wantRule: "json_size", wantMessage: "details must not exceed 20 bytes when encoded"},
+		{name: "field without JSON name", payload: sample{Name: "Rosa", Internal: "ab"}, wantField: "Internal", wantRule: "max"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateStruct(tt.payload)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Fatalf("got %v, want no error", err)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("got %v, want a *ValidationError", err)
+			}
+			if len(validationErr.Fields) != 1 {
+				t.Fatalf("got %d failing fields %+v, want 1", len(validationErr.Fields), validationErr.Fields)
+			}
+			field := validationErr.Fields[0]
+			if field.Field != tt.wantField || field.Rule != tt.wantRule {
+				t.Fatalf("got %s/%s, want %s/%s", field.Field, field.Rule, tt.wantField, tt.wantRule)
+			}
+			if tt.wantMessage != "" && field.Message != tt.wantMessage {
+				t.Fatalf("message = %q, want %q", field.Message, tt.wantMessage)
+			}
+		})
+	}
+}
@@ -33,6 +33,22 @@ func ProcessFloraData(rawDataList []interface{}) ([]dto.FloraData, error) {
 	return floraList, nil
 }
 
+// RPCError is a failed response from a downstream RPC call.
+// It unwraps to a *fiber.Error so callers checking for fiber errors keep working.
+type RPCError struct {
+	Status  int    // HTTP status sent to the client
+	Code    int    // Code returned by the downstream service
+	Message string // Message returned by the downstream service
+}
+
+func (e *RPCError) Error() string {
+	return e.Message
+}
+
+func (e *RPCError) Unwrap() error {
+	return &fiber.Error{Code: e.Status, Message: e.Message}
+}
+
 // Helper function to handle RPC error responses
 func HandleRPCError(res common.MessageResponse) error {
 	var msg string
@@ -41,5 +57,11 @@ func HandleRPCError(res common.MessageResponse) error {
 			msg = itemStr
 		}
 	}
-	return &fiber.Error{Code: int(res.Code), Message: msg}
+
+	// Downstream codes outside the HTTP error range are reported as a bad gateway
+	status := res.Code
+	if status < fiber.StatusBadRequest || status > 599 {
+		status = fiber.StatusBadGateway
+	}
+	return &RPCError{Status: status, Code: res.Code, Message: msg}
 }
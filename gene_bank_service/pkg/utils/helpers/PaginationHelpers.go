@@ -0,0 +1,148 @@
+package helpers
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"project_chimera/gene_bank_service/internal/dto"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const cursorPrefix = "page:"
+
+// EncodeCursor builds the opaque cursor pointing at the given page
+func EncodeCursor(page int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(page)))
+}
+
+// DecodeCursor returns the page number stored in a cursor created by EncodeCursor
+func DecodeCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(raw), cursorPrefix) {
+		return 0, errors.New("invalid cursor")
+	}
+
+	page, err := strconv.Atoi(strings.TrimPrefix(string(raw), cursorPrefix))
+	if err != nil || page < 1 {
+		return 0, errors.New("invalid cursor")
+	}
+	return page, nil
+}
+
+// ApplyFloraQuery filters, sorts and pages the flora list in memory.
+// It is used when the downstream service answers get_all_floras without paging the result itself.
+func ApplyFloraQuery(floraList []dto.FloraData, query dto.FloraQuery) ([]dto.FloraData, int) {
+	filtered := make([]dto.FloraData, 0, len(floraList))
+	q := strings.ToLower(query.Q)
+
+	for _, flora := range floraList {
+		if query.Type != "" && flora.Type != query.Type {
+			continue
+		}
+		if query.Origin != "" && !strings.EqualFold(flora.Origin, query.Origin) {
+			continue
+		}
+		if query.UserID != "" && flora.UserID != query.UserID {
+			continue
+		}
+		if q != "" &&
+			!strings.Contains(strings.ToLower(flora.CommonName), q) &&
+			!strings.Contains(strings.ToLower(flora.ScientificName), q) &&
+			!strings.Contains(strings.ToLower(flora.Description), q) {
+			continue
+		}
+		filtered = append(filtered, flora)
+	}
+
+	if query.Sort != "" {
+		sort.SliceStable(filtered, func(i, j int) bool {
+			a, b := floraSortKey(filtered[i], query.Sort), floraSortKey(filtered[j], query.Sort)
+			if query.Order == "desc" {
+				return a > b
+			}
+			return a < b
+		})
+	}
+
+	total := len(filtered)
+	start := (query.Page - 1) * query.Size
+	if start >= total {
+		return []dto.FloraData{}, total
+	}
+	end := start + query.Size
+	if end > total {
+		end = total
+	}
+
+	return filtered[start:end], total
+}
+
+// BuildPageLinks creates the navigation links for a paginated flora response
+func BuildPageLinks(baseURL string, query dto.FloraQuery, total int) *dto.PageLinks {
+	lastPage := LastPage(total, query.Size)
+
+	links := &dto.PageLinks{
+		Self:  pageURL(baseURL, query, query.Page),
+		First: pageURL(baseURL, query, 1),
+		Last:  pageURL(baseURL, query, lastPage),
+	}
+	if query.Page < lastPage {
+		links.Next = pageURL(baseURL, query, query.Page+1)
+	}
+	if query.Page > 1 {
+		links.Prev = pageURL(baseURL, query, query.Page-1)
+	}
+
+	return links
+}
+
+// LastPage returns the number of the last page, which is 1 for an empty result
+func LastPage(total int, size int) int {
+	if total <= 0 || size <= 0 {
+		return 1
+	}
+	return (total + size - 1) / size
+}
+
+func pageURL(baseURL string, query dto.FloraQuery, page int) string {
+	values := url.Values{}
+	values.Set("page", strconv.Itoa(page))
+	values.Set("size", strconv.Itoa(query.Size))
+	if query.Sort != "" {
+		if query.Order == "desc" {
+			values.Set("sort", "-"+query.Sort)
+		} else {
+			values.Set("sort", query.Sort)
+		}
+	}
+	if query.Type != "" {
+		values.Set("type", query.Type)
+	}
+	if query.Origin != "" {
+		values.Set("origin", query.Origin)
+	}
+	if query.UserID != "" {
+		values.Set("user_id", query.UserID)
+	}
+	if query.Q != "" {
+		values.Set("q", query.Q)
+	}
+
+	return baseURL + "?" + values.Encode()
+}
+
+func floraSortKey(flora dto.FloraData, field string) string {
+	switch field {
+	case "common_name":
+		return strings.ToLower(flora.CommonName)
+	case "scientific_name":
+		return strings.ToLower(flora.ScientificName)
+	case "origin":
+		return strings.ToLower(flora.Origin)
+	case "type":
+		return flora.Type
+	}
+	return ""
+}
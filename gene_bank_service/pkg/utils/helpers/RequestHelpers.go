@@ -0,0 +1,44 @@
+package helpers
+
+import (
+	"errors"
+	"io"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ErrBodyTooLarge is returned by ReadBody for bodies over the limit
+var ErrBodyTooLarge = errors.New("request body is too large")
+
+// ReadBody reads the request body, failing with ErrBodyTooLarge once more than limit bytes arrive.
+// With StreamRequestBody the Content-Length is only checked up front, chunked bodies have none,
+// so the stream is read through a limit. The body is then set on the request for BodyParser and
+// later readers, reading c.Body() directly would buffer the unbounded stream.
+func ReadBody(c *fiber.Ctx, limit int64) ([]byte, error) {
+	if int64(c.Request().Header.ContentLength()) > limit {
+		c.Context().SetConnectionClose()
+		return nil, ErrBodyTooLarge
+	}
+
+	stream := c.Context().RequestBodyStream()
+	if stream == nil {
+		body := c.Body()
+		if int64(len(body)) > limit {
+			return nil, ErrBodyTooLarge
+		}
+		return body, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(stream, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		// The rest of the body is never read, so the connection can not be reused
+		c.Context().SetConnectionClose()
+		return nil, ErrBodyTooLarge
+	}
+
+	c.Request().SetBodyRaw(body)
+	return body, nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2025 Naveen R
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+//go:build ignore
+
+// Generates orientation_<1-8>.jpg: a 32x16 picture with red, green, blue and white quadrants
+// (clockwise from the top left) stored the way a camera with the given EXIF orientation stores it.
+//
+//	go run gen_orientation.go
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"os"
+)
+
+const width, height = 32, 16
+
+var quadrants = [2][2]color.NRGBA{
+	{{255, 0, 0, 255}, {0, 255, 0, 255}},
+	{{255, 255, 255, 255}, {0, 0, 255, 255}},
+}
+
+// upright returns the color of the upright picture at x, y
+func upright(x, y int) color.NRGBA {
+	return quadrants[y*2/height][x*2/width]
+}
+
+// stored returns the image a camera writes for the orientation, viewing it needs the transformation
+// named by the EXIF specification: 2 flip horizontally, 3 rotate 180, 4 flip vertically, 5 transpose,
+// 6 rotate 90 clockwise, 7 transverse, 8 rotate 90 counter clockwise
+func stored(orientation int) *image.NRGBA {
+	w, h := width, height
+	if orientation >= 5 {
+		w, h = height, width
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			// Position in the upright picture of the stored pixel x, y
+			var ux, uy int
+			switch orientation {
+			case 1:
+				ux, uy = x, y
+			case 2:
+				ux, uy = width-1-x, y
+			case 3:
+				ux, uy = width-1-x, height-1-y
+			case 4:
+				ux, uy = x, height-1-y
+			case 5:
+				ux, uy = y, x
+			case 6:
+				ux, uy = width-1-y, x
+			case 7:
+				ux, uy = width-1-y, height-1-x
+			case 8:
+				ux, uy = y, height-1-x
+			}
+			img.SetNRGBA(x, y, upright(ux, uy))
+		}
+	}
+	return img
+}
+
+// exifSegment is an APP1 segment holding a little endian TIFF header with only the orientation tag
+func exifSegment(orientation int) []byte {
+	var tiff bytes.Buffer
+	tiff.WriteString("II")
+	binary.Write(&tiff, binary.LittleEndian, uint16(42))
+	binary.Write(&tiff, binary.LittleEndian, uint32(8))
+	binary.Write(&tiff, binary.LittleEndian, uint16(1))
+	binary.Write(&tiff, binary.LittleEndian, [4]uint16{0x0112, 3, 1, 0})
+	binary.Write(&tiff, binary.LittleEndian, uint16(orientation))
+	binary.Write(&tiff, binary.LittleEndian, uint16(0))
+	binary.Write(&tiff, binary.LittleEndian, uint32(0))
+
+	payload := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+func main() {
+	for orientation := 1; orientation <= 8; orientation++ {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, stored(orientation), &jpeg.Options{Quality: 95}); err != nil {
+			panic(err)
+		}
+		encoded := buf.Bytes()
+
+		// Insert the EXIF segment right after the SOI marker
+		file := append(append(append([]byte{}, encoded[:2]...), exifSegment(orientation)...), encoded[2:]...)
+		if err := os.WriteFile(fmt.Sprintf("orientation_%d.jpg", orientation), file, 0o644); err != nil {
+			panic(err)
+		}
+	}
+}